@@ -7,7 +7,9 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/go-redis/redis/v8"
+	"github.com/lib/pq"
 	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/service"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"log"
@@ -33,10 +35,27 @@ func initDS() (*dataSources, error) {
 		return nil, fmt.Errorf("error opening db: %w", err)
 	}
 
+	// The counters have to be filled in once after they got added
+	backfillCounters := db.Migrator().HasTable(&model.Post{}) && !db.Migrator().HasColumn(&model.Post{}, "LikeCount")
+	// Same for the entities, which also replace the hashtags split on spaces
+	backfillEntities := db.Migrator().HasTable(&model.Post{}) && !db.Migrator().HasColumn(&model.Post{}, "Entities")
+	// And the reply counter, which came after the replies
+	backfillReplies := db.Migrator().HasTable(&model.Post{}) && !db.Migrator().HasColumn(&model.Post{}, "ReplyCount")
+
 	if err := db.AutoMigrate(
 		&model.User{},
+		&model.Card{},
 		&model.Post{},
+		&model.PostVersion{},
+		&model.Poll{},
+		&model.PollOption{},
+		&model.PollVote{},
+		&model.Draft{},
+		&model.BookmarkFolder{},
+		&model.Bookmark{},
 		&model.File{},
+		&model.FileVariant{},
+		&model.Media{},
 		&model.Retweet{},
 	); err != nil {
 		return nil, fmt.Errorf("error migrating models: %w", err)
@@ -46,6 +65,61 @@ func initDS() (*dataSources, error) {
 		return nil, fmt.Errorf("error creating join table: %w", err)
 	}
 
+	// Generated columns can't be declared through the model, so the search vector is added here
+	if err := db.Exec(`
+		ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (to_tsvector('english', coalesce(text, ''))) STORED
+	`).Error; err != nil {
+		return nil, fmt.Errorf("error adding search vector: %w", err)
+	}
+
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_posts_search_vector ON posts USING GIN (search_vector)").Error; err != nil {
+		return nil, fmt.Errorf("error indexing search vector: %w", err)
+	}
+
+	// Profile search matches names by trigram similarity
+	for _, statement := range []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+		"CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING GIN (username gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_users_display_name_trgm ON users USING GIN (display_name gin_trgm_ops)",
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			return nil, fmt.Errorf("error setting up profile search: %w", err)
+		}
+	}
+
+	if backfillCounters {
+		if err := db.Exec(`
+			UPDATE posts p SET
+				like_count = (SELECT count(*) FROM post_likes l WHERE l.post_id = p.id),
+				retweet_count = (SELECT count(*) FROM retweets r WHERE r.post_id = p.id)
+		`).Error; err != nil {
+			return nil, fmt.Errorf("error backfilling post counters: %w", err)
+		}
+
+		if err := db.Exec(`
+			UPDATE users u SET
+				follower_count = (SELECT count(*) FROM followers f WHERE f.user_id = u.id),
+				followee_count = (SELECT count(*) FROM followee f WHERE f.user_id = u.id)
+		`).Error; err != nil {
+			return nil, fmt.Errorf("error backfilling user counters: %w", err)
+		}
+	}
+
+	if backfillReplies {
+		if err := db.Exec(`
+			UPDATE posts p SET reply_count = (SELECT count(*) FROM posts r WHERE r.reply_to_id = p.id)
+		`).Error; err != nil {
+			return nil, fmt.Errorf("error backfilling reply counters: %w", err)
+		}
+	}
+
+	if backfillEntities {
+		if err := fillEntities(db); err != nil {
+			return nil, fmt.Errorf("error backfilling post entities: %w", err)
+		}
+	}
+
 	// Initialize redis connection
 	redisURL := os.Getenv("REDIS_URL")
 	opt, err := redis.ParseURL(redisURL)
@@ -91,6 +165,24 @@ func initDS() (*dataSources, error) {
 	}, nil
 }
 
+// fillEntities extracts the entities of all posts in batches
+func fillEntities(db *gorm.DB) error {
+	var posts []model.Post
+	return db.Select("id", "text").Where("text IS NOT NULL").
+		FindInBatches(&posts, 500, func(tx *gorm.DB, batch int) error {
+			for _, post := range posts {
+				entities := service.ExtractEntities(*post.Text)
+				if err := tx.Model(&post).UpdateColumns(map[string]interface{}{
+					"entities":  entities,
+					"hash_tags": pq.StringArray(entities.Hashtags()),
+				}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
 // close to be used in graceful server shutdown
 func (d *dataSources) close() error {
 	if err := d.RedisClient.Close(); err != nil {
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"github.com/sentrionic/mirage/model"
+	"os"
+	"time"
+)
+
+// readEditWindow reads POST_EDIT_WINDOW, the time authors have to edit their posts
+func readEditWindow() (time.Duration, error) {
+	value := os.Getenv("POST_EDIT_WINDOW")
+
+	if value == "" {
+		return model.DefaultEditWindow, nil
+	}
+
+	window, err := time.ParseDuration(value)
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("could not parse POST_EDIT_WINDOW as a positive duration: %v", value)
+	}
+
+	return window, nil
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type bookmarkFolderReq struct {
+	Name string `json:"name" form:"name"`
+}
+
+func (r bookmarkFolderReq) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Name, validation.Required, validation.By(notBlank), validation.RuneLength(1, model.BookmarkFolderMaxName)),
+	)
+}
+
+func (r *bookmarkFolderReq) Sanitize() {
+	r.Name = strings.TrimSpace(r.Name)
+}
+
+// GetBookmarkFolders returns the bookmark folders of the current user
+func (h *Handler) GetBookmarkFolders(c *gin.Context) {
+	userId := c.MustGet("userId").(string)
+
+	folders, err := h.BookmarkService.GetFolders(userId)
+
+	if err != nil {
+		log.Printf("Unable to find bookmark folders of user: %v\n%v", userId, err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	response := make([]model.BookmarkFolderResponse, 0)
+
+	for _, f := range folders {
+		response = append(response, f.NewBookmarkFolderResponse())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"folders": response,
+	})
+}
+
+// CreateBookmarkFolder adds a bookmark folder for the current user
+func (h *Handler) CreateBookmarkFolder(c *gin.Context) {
+	userId := c.MustGet("userId").(string)
+
+	var req bookmarkFolderReq
+
+	if ok := bindData(c, &req); !ok {
+		return
+	}
+
+	req.Sanitize()
+
+	folder, err := h.BookmarkService.CreateFolder(&model.BookmarkFolder{
+		UserID: userId,
+		Name:   req.Name,
+	})
+
+	if err != nil {
+		log.Printf("Failed to create bookmark folder: %v\n", err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, folder.NewBookmarkFolderResponse())
+}
+
+// DeleteBookmarkFolder removes a bookmark folder of the current user.
+// Its bookmarks stay without a folder.
+func (h *Handler) DeleteBookmarkFolder(c *gin.Context) {
+	userId := c.MustGet("userId").(string)
+	folderId := c.Param("id")
+
+	folder, err := h.BookmarkService.FindFolder(userId, folderId)
+
+	if err != nil {
+		log.Printf("Unable to find bookmark folder: %v\n%v", folderId, err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if err := h.BookmarkService.DeleteFolder(folder); err != nil {
+		log.Printf("Unable to delete bookmark folder: %v\n%v", folderId, err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, folder.NewBookmarkFolderResponse())
+}
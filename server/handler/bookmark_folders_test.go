@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_BookmarkFolders(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	uid := fixture.RandID()
+
+	setupRouter := func(mockBookmarkService *mocks.BookmarkService) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:               router,
+			BookmarkService: mockBookmarkService,
+		})
+
+		return router
+	}
+
+	newRequest := func(body gin.H) *http.Request {
+		reqBody, err := json.Marshal(body)
+		assert.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/v1/bookmarks/folders", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		return request
+	}
+
+	t.Run("List folders", func(t *testing.T) {
+		folders := []model.BookmarkFolder{
+			{ID: fixture.RandID(), UserID: uid, Name: "Articles"},
+			{ID: fixture.RandID(), UserID: uid, Name: "Recipes"},
+		}
+
+		mockBookmarkService := new(mocks.BookmarkService)
+		mockBookmarkService.On("GetFolders", uid).Return(folders, nil)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/v1/bookmarks/folders", nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockBookmarkService).ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(gin.H{
+			"folders": []model.BookmarkFolderResponse{folders[0].NewBookmarkFolderResponse(), folders[1].NewBookmarkFolderResponse()},
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+	})
+
+	t.Run("Create folder", func(t *testing.T) {
+		initial := &model.BookmarkFolder{UserID: uid, Name: "Recipes"}
+		created := &model.BookmarkFolder{ID: fixture.RandID(), UserID: uid, Name: "Recipes"}
+
+		mockBookmarkService := new(mocks.BookmarkService)
+		mockBookmarkService.On("CreateFolder", initial).Return(created, nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockBookmarkService).ServeHTTP(rr, newRequest(gin.H{"name": " Recipes "}))
+
+		respBody, err := json.Marshal(created.NewBookmarkFolderResponse())
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+	})
+
+	t.Run("Name taken", func(t *testing.T) {
+		mockErr := apperrors.NewConflict("name")
+
+		mockBookmarkService := new(mocks.BookmarkService)
+		mockBookmarkService.On("CreateFolder", mock.AnythingOfType("*model.BookmarkFolder")).Return(nil, mockErr)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockBookmarkService).ServeHTTP(rr, newRequest(gin.H{"name": "Recipes"}))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Invalid names", func(t *testing.T) {
+		for _, name := range []string{"", "   ", fixture.RandStringRunes(model.BookmarkFolderMaxName + 1)} {
+			mockBookmarkService := new(mocks.BookmarkService)
+
+			rr := httptest.NewRecorder()
+			setupRouter(mockBookmarkService).ServeHTTP(rr, newRequest(gin.H{"name": name}))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mockBookmarkService.AssertNotCalled(t, "CreateFolder", mock.Anything)
+		}
+	})
+
+	t.Run("Delete folder", func(t *testing.T) {
+		folder := &model.BookmarkFolder{ID: fixture.RandID(), UserID: uid, Name: "Recipes"}
+
+		mockBookmarkService := new(mocks.BookmarkService)
+		mockBookmarkService.On("FindFolder", uid, folder.ID).Return(folder, nil)
+		mockBookmarkService.On("DeleteFolder", folder).Return(nil)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodDelete, "/v1/bookmarks/folders/"+folder.ID, nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockBookmarkService).ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockBookmarkService.AssertExpectations(t)
+	})
+
+	t.Run("Delete folder of another user", func(t *testing.T) {
+		id := fixture.RandID()
+		mockErr := apperrors.NewNotFound("folder", id)
+
+		mockBookmarkService := new(mocks.BookmarkService)
+		mockBookmarkService.On("FindFolder", uid, id).Return(nil, mockErr)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodDelete, "/v1/bookmarks/folders/"+id, nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockBookmarkService).ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockBookmarkService.AssertNotCalled(t, "DeleteFolder", mock.Anything)
+	})
+}
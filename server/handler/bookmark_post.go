@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// BookmarkPost adds the post to the bookmarks of the current user or removes it.
+// The folder query parameter puts a new bookmark into one of the user's folders
+// or moves an existing bookmark there from another folder.
+func (h *Handler) BookmarkPost(c *gin.Context) {
+	userId := c.MustGet("userId").(string)
+	postId := c.Param("id")
+
+	post, err := h.PostService.FindPostByID(postId)
+
+	if err != nil {
+		log.Printf("Unable to find post: %v\n%v", postId, err)
+		e := apperrors.NewNotFound("post", postId)
+
+		c.JSON(e.Status(), gin.H{
+			"error": e,
+		})
+		return
+	}
+
+	err = h.BookmarkService.ToggleBookmark(post, userId, folderQuery(c))
+
+	if err != nil {
+		log.Printf("Failed to change bookmark status: %v\n", err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if ok := h.loadPostViewerState(c, userId, []*model.Post{post}); !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, post.NewPostResponse())
+}
+
+// folderQuery returns the folder query parameter or nil if there is none
+func folderQuery(c *gin.Context) *string {
+	folder := c.Query("folder")
+	if folder == "" {
+		return nil
+	}
+	return &folder
+}
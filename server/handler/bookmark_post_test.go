@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_BookmarkPost(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	current := fixture.GetMockUser()
+
+	setupRouter := func(mockPostService *mocks.PostService, mockBookmarkService *mocks.BookmarkService) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			c.Set("userId", current.ID)
+			session.Set("userId", current.ID)
+		})
+
+		NewHandler(&Config{
+			R:               router,
+			PostService:     mockPostService,
+			BookmarkService: mockBookmarkService,
+		})
+
+		return router
+	}
+
+	t.Run("Bookmark into folder", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		folderId := fixture.RandID()
+
+		mockPostService := new(mocks.PostService)
+		mockBookmarkService := new(mocks.BookmarkService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockBookmarkService.On("ToggleBookmark", mockPost, current.ID, &folderId).Return(nil)
+		mockPostService.On("LoadViewerState", current.ID, []*model.Post{mockPost}).
+			Run(func(args mock.Arguments) {
+				mockPost.Bookmarked = true
+			}).
+			Return(nil)
+
+		rr := httptest.NewRecorder()
+		url := fmt.Sprintf("/v1/posts/%s/bookmark?folder=%s", mockPost.ID, folderId)
+		request, err := http.NewRequest(http.MethodPost, url, nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockPostService, mockBookmarkService).ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+
+		post := &model.PostResponse{}
+		err = json.Unmarshal(rr.Body.Bytes(), post)
+		assert.NoError(t, err)
+		assert.True(t, post.Bookmarked)
+
+		mockBookmarkService.AssertExpectations(t)
+	})
+
+	t.Run("Remove bookmark", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.Bookmarked = true
+
+		mockPostService := new(mocks.PostService)
+		mockBookmarkService := new(mocks.BookmarkService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockBookmarkService.On("ToggleBookmark", mockPost, current.ID, (*string)(nil)).Return(nil)
+		mockPostService.On("LoadViewerState", current.ID, []*model.Post{mockPost}).
+			Run(func(args mock.Arguments) {
+				mockPost.Bookmarked = false
+			}).
+			Return(nil)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/posts/%s/bookmark", mockPost.ID), nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockPostService, mockBookmarkService).ServeHTTP(rr, request)
+
+		post := &model.PostResponse{}
+		err = json.Unmarshal(rr.Body.Bytes(), post)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.False(t, post.Bookmarked)
+		mockBookmarkService.AssertExpectations(t)
+	})
+
+	t.Run("Unknown folder", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		folderId := fixture.RandID()
+		mockErr := apperrors.NewNotFound("folder", folderId)
+
+		mockPostService := new(mocks.PostService)
+		mockBookmarkService := new(mocks.BookmarkService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockBookmarkService.On("ToggleBookmark", mockPost, current.ID, &folderId).Return(mockErr)
+
+		rr := httptest.NewRecorder()
+		url := fmt.Sprintf("/v1/posts/%s/bookmark?folder=%s", mockPost.ID, folderId)
+		request, err := http.NewRequest(http.MethodPost, url, nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockPostService, mockBookmarkService).ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(gin.H{
+			"error": mockErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		id := fixture.RandID()
+
+		mockPostService := new(mocks.PostService)
+		mockBookmarkService := new(mocks.BookmarkService)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		NewHandler(&Config{
+			R:               router,
+			PostService:     mockPostService,
+			BookmarkService: mockBookmarkService,
+		})
+
+		request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/posts/%s/bookmark", id), nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockPostService.AssertNotCalled(t, "FindPostByID", id)
+	})
+}
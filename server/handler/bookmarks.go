@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// GetBookmarks returns the posts the current user bookmarked.
+// The folder query parameter only returns the bookmarks in that folder.
+func (h *Handler) GetBookmarks(c *gin.Context) {
+	authUser := c.MustGet("userId").(string)
+
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	posts, err := h.BookmarkService.GetBookmarks(authUser, folderQuery(c), page)
+
+	if err != nil {
+		log.Printf("Unable to find bookmarks of user: %v\n%v", authUser, err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	items, hasMore := model.Trim(*posts, page)
+
+	if ok := h.loadPostViewerState(c, authUser, postRefs(items)); !ok {
+		return
+	}
+
+	response := make([]model.PostResponse, 0)
+
+	for _, p := range items {
+		response = append(response, p.NewPostResponse())
+	}
+
+	c.JSON(http.StatusOK, postsPage(items, response, hasMore))
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_GetBookmarks(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	uid := fixture.RandID()
+
+	setupRouter := func(mockPostService *mocks.PostService, mockBookmarkService *mocks.BookmarkService) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:               router,
+			PostService:     mockPostService,
+			BookmarkService: mockBookmarkService,
+		})
+
+		return router
+	}
+
+	t.Run("Sorted by the time they got bookmarked", func(t *testing.T) {
+		first := fixture.GetMockPost()
+		second := fixture.GetMockPost()
+		newer := time.Now()
+		older := newer.Add(-time.Hour)
+		first.BookmarkedAt = &newer
+		second.BookmarkedAt = &older
+		posts := []model.Post{*first, *second}
+
+		mockPostService := new(mocks.PostService)
+		mockBookmarkService := new(mocks.BookmarkService)
+		mockBookmarkService.On("GetBookmarks", uid, (*string)(nil), model.Page{}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", uid, mock.AnythingOfType("[]*model.Post")).
+			Run(func(args mock.Arguments) {
+				for _, post := range args.Get(1).([]*model.Post) {
+					post.Bookmarked = true
+				}
+			}).
+			Return(nil)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/v1/bookmarks", nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockPostService, mockBookmarkService).ServeHTTP(rr, request)
+
+		var body struct {
+			Posts      []model.PostResponse `json:"posts"`
+			HasMore    bool                 `json:"hasMore"`
+			NextCursor string               `json:"nextCursor"`
+		}
+		err = json.Unmarshal(rr.Body.Bytes(), &body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Len(t, body.Posts, 2)
+		assert.True(t, body.Posts[0].Bookmarked)
+		assert.False(t, body.HasMore)
+		assert.Equal(t, model.Cursor{Time: older, ID: second.ID}.Encode(), body.NextCursor)
+	})
+
+	t.Run("Folder", func(t *testing.T) {
+		folderId := fixture.RandID()
+		posts := make([]model.Post, 0)
+
+		mockPostService := new(mocks.PostService)
+		mockBookmarkService := new(mocks.BookmarkService)
+		mockBookmarkService.On("GetBookmarks", uid, &folderId, model.Page{}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", uid, mock.AnythingOfType("[]*model.Post")).Return(nil)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/v1/bookmarks?folder="+folderId, nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockPostService, mockBookmarkService).ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockBookmarkService.AssertExpectations(t)
+	})
+
+	t.Run("Unknown folder", func(t *testing.T) {
+		folderId := fixture.RandID()
+		mockErr := apperrors.NewNotFound("folder", folderId)
+
+		mockPostService := new(mocks.PostService)
+		mockBookmarkService := new(mocks.BookmarkService)
+		mockBookmarkService.On("GetBookmarks", uid, &folderId, model.Page{}).Return(nil, mockErr)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/v1/bookmarks?folder="+folderId, nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockPostService, mockBookmarkService).ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(gin.H{
+			"error": mockErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+	})
+}
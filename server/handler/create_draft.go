@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// draftReq is the body for creating and editing drafts.
+// Drafts without a scheduled time don't get published on their own.
+type draftReq struct {
+	Text        string     `json:"text" form:"text"`
+	ScheduledAt *time.Time `json:"scheduledAt" form:"scheduledAt"`
+}
+
+func (r draftReq) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Text, validation.Required, validation.By(notBlank), validation.Length(1, 280)),
+	)
+}
+
+func (r *draftReq) Sanitize() {
+	r.Text = strings.TrimSpace(r.Text)
+}
+
+func notBlank(value interface{}) error {
+	if strings.TrimSpace(value.(string)) == "" {
+		return errors.New("cannot be blank")
+	}
+	return nil
+}
+
+// CreateDraft handler
+func (h *Handler) CreateDraft(c *gin.Context) {
+	userId := c.MustGet("userId").(string)
+
+	var req draftReq
+
+	if ok := bindData(c, &req); !ok {
+		return
+	}
+
+	req.Sanitize()
+
+	draft, err := h.DraftService.CreateDraft(&model.Draft{
+		UserID:      userId,
+		Text:        req.Text,
+		ScheduledAt: req.ScheduledAt,
+	})
+
+	if err != nil {
+		log.Printf("Failed to create draft: %v\n", err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, draft.NewDraftResponse())
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_CreateDraft(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	uid := fixture.RandID()
+
+	setupRouter := func(mockDraftService *mocks.DraftService) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:            router,
+			DraftService: mockDraftService,
+		})
+
+		return router
+	}
+
+	newRequest := func(body gin.H) *http.Request {
+		reqBody, err := json.Marshal(body)
+		assert.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/v1/drafts", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		return request
+	}
+
+	t.Run("Scheduled", func(t *testing.T) {
+		at := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+		initial := &model.Draft{UserID: uid, Text: "Later", ScheduledAt: &at}
+		created := &model.Draft{ID: fixture.RandID(), UserID: uid, Text: "Later", ScheduledAt: &at, Status: model.DraftScheduled}
+
+		mockDraftService := new(mocks.DraftService)
+		mockDraftService.On("CreateDraft", initial).Return(created, nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockDraftService).ServeHTTP(rr, newRequest(gin.H{"text": " Later ", "scheduledAt": at}))
+
+		respBody, err := json.Marshal(created.NewDraftResponse())
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockDraftService.AssertExpectations(t)
+	})
+
+	t.Run("Blank text", func(t *testing.T) {
+		mockDraftService := new(mocks.DraftService)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockDraftService).ServeHTTP(rr, newRequest(gin.H{"text": "   "}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockDraftService.AssertNotCalled(t, "CreateDraft", mock.Anything)
+	})
+
+	t.Run("Text too long", func(t *testing.T) {
+		mockDraftService := new(mocks.DraftService)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockDraftService).ServeHTTP(rr, newRequest(gin.H{"text": fixture.RandStringRunes(300)}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockDraftService.AssertNotCalled(t, "CreateDraft", mock.Anything)
+	})
+
+	t.Run("Time in the past", func(t *testing.T) {
+		at := time.Now().Add(-time.Hour)
+		mockErr := apperrors.NewBadRequest("the scheduled time must be in the future")
+
+		mockDraftService := new(mocks.DraftService)
+		mockDraftService.On("CreateDraft", mock.AnythingOfType("*model.Draft")).Return(nil, mockErr)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockDraftService).ServeHTTP(rr, newRequest(gin.H{"text": "Later", "scheduledAt": at}))
+
+		respBody, err := json.Marshal(gin.H{
+			"error": mockErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		mockDraftService := new(mocks.DraftService)
+		NewHandler(&Config{
+			R:            router,
+			DraftService: mockDraftService,
+		})
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, newRequest(gin.H{"text": "Later"}))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockDraftService.AssertNotCalled(t, "CreateDraft", mock.Anything)
+	})
+}
@@ -10,11 +10,21 @@ import (
 	"mime/multipart"
 	"net/http"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 type createPostReq struct {
-	Text *string               `form:"text"`
-	File *multipart.FileHeader `form:"file"`
+	Text        *string               `form:"text"`
+	AltText     *string               `form:"altText"`
+	File        *multipart.FileHeader `form:"file"`
+	PollOptions []string              `form:"pollOptions"`
+	// PollDuration is the number of minutes the poll stays open
+	PollDuration       *int `form:"pollDuration"`
+	PollMultipleChoice bool `form:"pollMultipleChoice"`
+	// ReplyTo is the ID of the post this one replies to
+	ReplyTo     *string `form:"replyTo"`
+	ReplyPolicy *string `form:"replyPolicy"`
 }
 
 func (r createPostReq) Validate() error {
@@ -24,14 +34,49 @@ func (r createPostReq) Validate() error {
 				Error("text is required if no files are provided"),
 			validation.Length(1, 280),
 		),
+		validation.Field(&r.AltText, validation.Length(1, 1000)),
+		validation.Field(&r.File,
+			validation.Nil.When(len(r.PollOptions) > 0).
+				Error("a post can't have a file and a poll"),
+		),
+		validation.Field(&r.PollOptions,
+			validation.Length(model.PollMinOptions, model.PollMaxOptions),
+			validation.By(validPollOptions),
+		),
+		validation.Field(&r.PollDuration,
+			validation.Required.When(len(r.PollOptions) > 0),
+			validation.Nil.When(len(r.PollOptions) == 0).
+				Error("a duration requires poll options"),
+			validation.Min(int(model.PollMinDuration.Minutes())),
+			validation.Max(int(model.PollMaxDuration.Minutes())),
+		),
+		validation.Field(&r.ReplyPolicy,
+			validation.In(model.ReplyEveryone, model.ReplyFollowing, model.ReplyMentioned).
+				Error("reply policy must be everyone, following or mentioned"),
+		),
 	)
 }
 
+func validPollOptions(value interface{}) error {
+	for _, option := range value.([]string) {
+		length := utf8.RuneCountInString(strings.TrimSpace(option))
+		if length == 0 || length > model.PollMaxOptionLength {
+			return errors.New("options must have between 1 and 25 characters")
+		}
+	}
+	return nil
+}
+
 func (r *createPostReq) Sanitize() {
 	if r.Text != nil {
 		text := strings.TrimSpace(*r.Text)
 		r.Text = &text
 	}
+	r.AltText = trimToNil(r.AltText)
+	r.ReplyTo = trimToNil(r.ReplyTo)
+	for i, option := range r.PollOptions {
+		r.PollOptions[i] = strings.TrimSpace(option)
+	}
 }
 
 // CreatePost handler
@@ -63,6 +108,11 @@ func (h *Handler) CreatePost(c *gin.Context) {
 	}
 
 	initial.Text = req.Text
+	initial.ReplyToID = req.ReplyTo
+
+	if req.ReplyPolicy != nil {
+		initial.ReplyPolicy = *req.ReplyPolicy
+	}
 
 	if req.File != nil {
 
@@ -80,15 +130,26 @@ func (h *Handler) CreatePost(c *gin.Context) {
 		file, err := h.PostService.UploadFile(req.File)
 
 		if err != nil {
-			c.JSON(500, gin.H{
+			c.JSON(apperrors.Status(err), gin.H{
 				"error": err,
 			})
 			return
 		}
 
+		file.AltText = req.AltText
 		initial.File = file
 	}
 
+	if len(req.PollOptions) > 0 {
+		initial.Poll = &model.Poll{
+			MultipleChoice: req.PollMultipleChoice,
+			EndsAt:         time.Now().Add(time.Duration(*req.PollDuration) * time.Minute),
+		}
+		for i, option := range req.PollOptions {
+			initial.Poll.Options = append(initial.Poll.Options, model.PollOption{Position: i, Text: option})
+		}
+	}
+
 	post, err := h.PostService.CreatePost(initial)
 
 	if err != nil {
@@ -100,5 +161,5 @@ func (h *Handler) CreatePost(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, post.NewPostResponse(""))
+	c.JSON(http.StatusCreated, post.NewPostResponse())
 }
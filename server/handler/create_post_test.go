@@ -17,6 +17,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestHandler_CreatePost(t *testing.T) {
@@ -104,7 +105,7 @@ func TestHandler_CreatePost(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, _ := json.Marshal(mockPost.NewPostResponse(""))
+		respBody, _ := json.Marshal(mockPost.NewPostResponse())
 
 		assert.Equal(t, http.StatusCreated, rr.Code)
 		assert.Equal(t, respBody, rr.Body.Bytes())
@@ -145,6 +146,133 @@ func TestHandler_CreatePost(t *testing.T) {
 		mockPostService.AssertCalled(t, "CreatePost", initial)
 	})
 
+	t.Run("Poll Post Creation Success", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		mockPost := fixture.GetMockPost()
+		mockPost.User = *mockUser
+		mockPost.UserID = mockUser.ID
+
+		form := url.Values{}
+		form.Add("text", *mockPost.Text)
+		form.Add("pollOptions", " Yes ")
+		form.Add("pollOptions", "No")
+		form.Add("pollDuration", "60")
+		form.Add("pollMultipleChoice", "true")
+
+		request, _ := http.NewRequest(http.MethodPost, "/v1/posts", strings.NewReader(form.Encode()))
+		request.Form = form
+
+		start := time.Now()
+		hasPoll := mock.MatchedBy(func(p *model.Post) bool {
+			return p.Poll != nil &&
+				p.Poll.MultipleChoice &&
+				p.Poll.EndsAt.After(start.Add(59*time.Minute)) &&
+				len(p.Poll.Options) == 2 &&
+				p.Poll.Options[0] == model.PollOption{Position: 0, Text: "Yes"} &&
+				p.Poll.Options[1] == model.PollOption{Position: 1, Text: "No"}
+		})
+
+		mockPostService.
+			On("CreatePost", hasPoll).
+			Run(func(args mock.Arguments) {
+				mockPost.Poll = args.Get(0).(*model.Post).Poll
+			}).
+			Return(mockPost, nil)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, _ := json.Marshal(mockPost.NewPostResponse())
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+
+		post := &model.PostResponse{}
+		err := json.Unmarshal(rr.Body.Bytes(), post)
+		assert.NoError(t, err)
+		assert.Len(t, post.Poll.Options, 2)
+		assert.Nil(t, post.Poll.Options[0].Votes)
+		assert.False(t, post.Poll.Voted)
+	})
+
+	t.Run("Reply Post Creation Success", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		parentId := fixture.RandID()
+		mockPost := fixture.GetMockPost()
+		mockPost.User = *mockUser
+		mockPost.UserID = mockUser.ID
+		mockPost.ReplyToID = &parentId
+		mockPost.ReplyPolicy = model.ReplyMentioned
+		mockPost.CanReply = true
+
+		form := url.Values{}
+		form.Add("text", *mockPost.Text)
+		form.Add("replyTo", parentId)
+		form.Add("replyPolicy", model.ReplyMentioned)
+
+		request, _ := http.NewRequest(http.MethodPost, "/v1/posts", strings.NewReader(form.Encode()))
+		request.Form = form
+
+		initial := &model.Post{
+			Text:        mockPost.Text,
+			UserID:      mockUser.ID,
+			User:        *mockUser,
+			ReplyToID:   &parentId,
+			ReplyPolicy: model.ReplyMentioned,
+		}
+
+		mockPostService.On("CreatePost", initial).Return(mockPost, nil)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, _ := json.Marshal(mockPost.NewPostResponse())
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+
+		post := &model.PostResponse{}
+		err := json.Unmarshal(rr.Body.Bytes(), post)
+		assert.NoError(t, err)
+		assert.Equal(t, parentId, *post.ReplyTo)
+		assert.Equal(t, model.ReplyMentioned, post.ReplyPolicy)
+		assert.True(t, post.CanReply)
+	})
+
+	t.Run("Reply not allowed", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		parentId := fixture.RandID()
+		text := fixture.RandStringRunes(120)
+
+		form := url.Values{}
+		form.Add("text", text)
+		form.Add("replyTo", parentId)
+
+		request, _ := http.NewRequest(http.MethodPost, "/v1/posts", strings.NewReader(form.Encode()))
+		request.Form = form
+
+		initial := &model.Post{
+			Text:      &text,
+			UserID:    mockUser.ID,
+			User:      *mockUser,
+			ReplyToID: &parentId,
+		}
+
+		mockError := apperrors.NewForbidden("only people the author follows can reply")
+		mockPostService.On("CreatePost", initial).Return(nil, mockError)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, _ := json.Marshal(gin.H{
+			"error": mockError,
+		})
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertCalled(t, "CreatePost", initial)
+	})
+
 	t.Run("Disallowed mimetype", func(t *testing.T) {
 		rr := httptest.NewRecorder()
 
@@ -204,13 +332,59 @@ func TestHandler_CreatePost(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, _ := json.Marshal(mockPost.NewPostResponse(""))
+		respBody, _ := json.Marshal(mockPost.NewPostResponse())
 
 		assert.Equal(t, http.StatusCreated, rr.Code)
 		assert.Equal(t, respBody, rr.Body.Bytes())
 		mockPostService.AssertCalled(t, "CreatePost", initial)
 		mockPostService.AssertCalled(t, "UploadFile", formFile)
 	})
+
+	t.Run("Image rejected by upload", func(t *testing.T) {
+		mockPostService := new(mocks.PostService)
+
+		router := gin.Default()
+		router.Use(func(c *gin.Context) {
+			c.Set("userId", uid)
+		})
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:            router,
+			UserService:  mockUserService,
+			PostService:  mockPostService,
+			MaxBodyBytes: 4 * 1024 * 1024,
+		})
+
+		rr := httptest.NewRecorder()
+
+		multipartImageFixture := fixture.NewMultipartImage("image.gif", "image/gif")
+		defer multipartImageFixture.Close()
+
+		request, _ := http.NewRequest(http.MethodPost, "/v1/posts", multipartImageFixture.MultipartBody)
+		request.Header.Set("Content-Type", multipartImageFixture.ContentType)
+
+		mockError := apperrors.NewBadRequest("gifs can have at most 300 frames")
+		mockPostService.
+			On("UploadFile", mock.AnythingOfType("*multipart.FileHeader")).
+			Return(nil, mockError)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, _ := json.Marshal(gin.H{
+			"error": mockError,
+		})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertNotCalled(t, "CreatePost")
+	})
 }
 
 func TestHandler_CreatePost_BadRequests(t *testing.T) {
@@ -265,6 +439,75 @@ func TestHandler_CreatePost_BadRequests(t *testing.T) {
 				"text": {fixture.RandStringRunes(300)},
 			},
 		},
+		{
+			name: "Poll with one option",
+			body: map[string][]string{
+				"text":         {"Question?"},
+				"pollOptions":  {"Yes"},
+				"pollDuration": {"60"},
+			},
+		},
+		{
+			name: "Poll with five options",
+			body: map[string][]string{
+				"text":         {"Question?"},
+				"pollOptions":  {"A", "B", "C", "D", "E"},
+				"pollDuration": {"60"},
+			},
+		},
+		{
+			name: "Poll option too long",
+			body: map[string][]string{
+				"text":         {"Question?"},
+				"pollOptions":  {"Yes", fixture.RandStringRunes(26)},
+				"pollDuration": {"60"},
+			},
+		},
+		{
+			name: "Blank poll option",
+			body: map[string][]string{
+				"text":         {"Question?"},
+				"pollOptions":  {"Yes", "  "},
+				"pollDuration": {"60"},
+			},
+		},
+		{
+			name: "Poll without duration",
+			body: map[string][]string{
+				"text":        {"Question?"},
+				"pollOptions": {"Yes", "No"},
+			},
+		},
+		{
+			name: "Poll duration too short",
+			body: map[string][]string{
+				"text":         {"Question?"},
+				"pollOptions":  {"Yes", "No"},
+				"pollDuration": {"1"},
+			},
+		},
+		{
+			name: "Poll duration too long",
+			body: map[string][]string{
+				"text":         {"Question?"},
+				"pollOptions":  {"Yes", "No"},
+				"pollDuration": {"10081"},
+			},
+		},
+		{
+			name: "Unknown reply policy",
+			body: map[string][]string{
+				"text":        {"Hello"},
+				"replyPolicy": {"friends"},
+			},
+		},
+		{
+			name: "Duration without poll",
+			body: map[string][]string{
+				"text":         {"Question?"},
+				"pollDuration": {"60"},
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// DeleteDraft removes a draft of the current user
+func (h *Handler) DeleteDraft(c *gin.Context) {
+	userId := c.MustGet("userId").(string)
+	draftId := c.Param("id")
+
+	draft, err := h.DraftService.FindDraft(userId, draftId)
+
+	if err != nil {
+		log.Printf("Unable to find draft: %v\n%v", draftId, err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if err := h.DraftService.DeleteDraft(draft); err != nil {
+		log.Printf("Unable to delete draft: %v\n%v", draftId, err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, draft.NewDraftResponse())
+}
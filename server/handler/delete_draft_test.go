@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_DeleteDraft(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	uid := fixture.RandID()
+
+	setupRouter := func(mockDraftService *mocks.DraftService) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:            router,
+			DraftService: mockDraftService,
+		})
+
+		return router
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		draft := &model.Draft{ID: fixture.RandID(), UserID: uid, Text: "Later", Status: model.DraftSaved}
+
+		mockDraftService := new(mocks.DraftService)
+		mockDraftService.On("FindDraft", uid, draft.ID).Return(draft, nil)
+		mockDraftService.On("DeleteDraft", draft).Return(nil)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodDelete, "/v1/drafts/"+draft.ID, nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockDraftService).ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(draft.NewDraftResponse())
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockDraftService.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		id := fixture.RandID()
+		mockErr := apperrors.NewNotFound("draft", id)
+
+		mockDraftService := new(mocks.DraftService)
+		mockDraftService.On("FindDraft", uid, id).Return(nil, mockErr)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodDelete, "/v1/drafts/"+id, nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockDraftService).ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(gin.H{
+			"error": mockErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockDraftService.AssertNotCalled(t, "DeleteDraft", mock.Anything)
+	})
+
+	t.Run("Draft being published", func(t *testing.T) {
+		draft := &model.Draft{ID: fixture.RandID(), UserID: uid, Text: "Later", Status: model.DraftPublishing}
+		mockErr := apperrors.NewForbidden("the draft is being published")
+
+		mockDraftService := new(mocks.DraftService)
+		mockDraftService.On("FindDraft", uid, draft.ID).Return(draft, nil)
+		mockDraftService.On("DeleteDraft", draft).Return(mockErr)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodDelete, "/v1/drafts/"+draft.ID, nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockDraftService).ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
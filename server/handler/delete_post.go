@@ -45,5 +45,5 @@ func (h *Handler) DeletePost(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, post.NewPostResponse(userId))
+	c.JSON(http.StatusOK, post.NewPostResponse())
 }
@@ -52,7 +52,7 @@ func TestHandler_DeletePost(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockPost.NewPostResponse(uid))
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// GetDrafts returns the drafts and scheduled posts of the current user
+func (h *Handler) GetDrafts(c *gin.Context) {
+	authUser := c.MustGet("userId").(string)
+
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	drafts, err := h.DraftService.GetDrafts(authUser, page)
+
+	if err != nil {
+		log.Printf("Unable to find drafts of user: %v\n%v", authUser, err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	items, hasMore := model.Trim(*drafts, page)
+
+	response := make([]model.DraftResponse, 0)
+
+	for _, d := range items {
+		response = append(response, d.NewDraftResponse())
+	}
+
+	var next, previous *string
+
+	if len(items) > 0 {
+		older := items[len(items)-1].Cursor().Encode()
+		newer := items[0].Cursor().Encode()
+		next, previous = &older, &newer
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"drafts":         response,
+		"hasMore":        hasMore,
+		"nextCursor":     next,
+		"previousCursor": previous,
+	})
+}
+
+// GetDraft returns a draft of the current user
+func (h *Handler) GetDraft(c *gin.Context) {
+	authUser := c.MustGet("userId").(string)
+	draftId := c.Param("id")
+
+	draft, err := h.DraftService.FindDraft(authUser, draftId)
+
+	if err != nil {
+		log.Printf("Unable to find draft: %v\n%v", draftId, err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, draft.NewDraftResponse())
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_GetDrafts(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	uid := fixture.RandID()
+
+	setupRouter := func(mockDraftService *mocks.DraftService) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:            router,
+			DraftService: mockDraftService,
+		})
+
+		return router
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		reason := "the post could not be published"
+		drafts := []model.Draft{
+			{ID: fixture.RandID(), UserID: uid, Text: "Newer", Status: model.DraftFailed, Error: &reason, CreatedAt: time.Now()},
+			{ID: fixture.RandID(), UserID: uid, Text: "Older", Status: model.DraftSaved, CreatedAt: time.Now().Add(-time.Hour)},
+		}
+
+		mockDraftService := new(mocks.DraftService)
+		mockDraftService.On("GetDrafts", uid, model.Page{}).Return(&drafts, nil)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/v1/drafts", nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockDraftService).ServeHTTP(rr, request)
+
+		next := drafts[1].Cursor().Encode()
+		previous := drafts[0].Cursor().Encode()
+		respBody, err := json.Marshal(gin.H{
+			"drafts":         []model.DraftResponse{drafts[0].NewDraftResponse(), drafts[1].NewDraftResponse()},
+			"hasMore":        false,
+			"nextCursor":     &next,
+			"previousCursor": &previous,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockDraftService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		mockDraftService := new(mocks.DraftService)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/v1/drafts?before=invalid", nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockDraftService).ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockDraftService.AssertNotCalled(t, "GetDrafts", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Get one draft", func(t *testing.T) {
+		draft := &model.Draft{ID: fixture.RandID(), UserID: uid, Text: "Later", Status: model.DraftSaved}
+
+		mockDraftService := new(mocks.DraftService)
+		mockDraftService.On("FindDraft", uid, draft.ID).Return(draft, nil)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/v1/drafts/"+draft.ID, nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockDraftService).ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(draft.NewDraftResponse())
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+	})
+
+	t.Run("Draft of another user", func(t *testing.T) {
+		id := fixture.RandID()
+		mockErr := apperrors.NewNotFound("draft", id)
+
+		mockDraftService := new(mocks.DraftService)
+		mockDraftService.On("FindDraft", uid, id).Return(nil, mockErr)
+
+		rr := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/v1/drafts/"+id, nil)
+		assert.NoError(t, err)
+
+		setupRouter(mockDraftService).ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"errors"
-	"fmt"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/sentrionic/mirage/model/apperrors"
@@ -71,6 +70,10 @@ func (h *Handler) EditAccount(c *gin.Context) {
 	authUser.DisplayName = req.DisplayName
 	authUser.Bio = req.Bio
 
+	// Media that got replaced only gets released once the update went through
+	acquired := make([]string, 0)
+	released := make([]string, 0)
+
 	if req.Image != nil {
 
 		// Validate image mime-type is allowable
@@ -84,19 +87,19 @@ func (h *Handler) EditAccount(c *gin.Context) {
 			return
 		}
 
-		directory := fmt.Sprintf("profile_images/%s", authUser.ID)
-		url, err := h.UserService.ChangeAvatar(req.Image, directory)
+		if authUser.ImageMediaID != nil {
+			released = append(released, *authUser.ImageMediaID)
+		}
 
-		if err != nil {
-			c.JSON(500, gin.H{
+		if err := h.UserService.ChangeAvatar(authUser, req.Image); err != nil {
+			h.releaseMedia(acquired)
+			c.JSON(apperrors.Status(err), gin.H{
 				"error": err,
 			})
 			return
 		}
 
-		_ = h.UserService.DeleteImage(authUser.Image)
-
-		authUser.Image = url
+		acquired = append(acquired, *authUser.ImageMediaID)
 	}
 
 	if req.Banner != nil {
@@ -111,21 +114,19 @@ func (h *Handler) EditAccount(c *gin.Context) {
 			return
 		}
 
-		directory := fmt.Sprintf("header_photo/%s", authUser.ID)
-		url, err := h.UserService.ChangeBanner(req.Banner, directory)
+		if authUser.BannerMediaID != nil {
+			released = append(released, *authUser.BannerMediaID)
+		}
 
-		if err != nil {
-			c.JSON(500, gin.H{
+		if err := h.UserService.ChangeBanner(authUser, req.Banner); err != nil {
+			h.releaseMedia(acquired)
+			c.JSON(apperrors.Status(err), gin.H{
 				"error": err,
 			})
 			return
 		}
 
-		if authUser.Banner != nil {
-			_ = h.UserService.DeleteImage(authUser.Image)
-		}
-
-		authUser.Banner = &url
+		acquired = append(acquired, *authUser.BannerMediaID)
 	}
 
 	err = h.UserService.Update(authUser)
@@ -133,11 +134,24 @@ func (h *Handler) EditAccount(c *gin.Context) {
 	if err != nil {
 		log.Printf("Failed to update user: %v\n", err)
 
+		h.releaseMedia(acquired)
 		c.JSON(apperrors.Status(err), gin.H{
 			"error": err,
 		})
 		return
 	}
 
+	h.releaseMedia(released)
+
 	c.JSON(http.StatusOK, authUser.NewAccountResponse())
 }
+
+// releaseMedia releases the media for the given IDs and only logs failures,
+// as they should not fail the request
+func (h *Handler) releaseMedia(ids []string) {
+	for _, id := range ids {
+		if err := h.UserService.ReleaseMedia(id); err != nil {
+			log.Printf("Failed to release media: %v\n%v", id, err)
+		}
+	}
+}
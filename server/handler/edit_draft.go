@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// EditDraft replaces the text and schedule of a draft of the current user.
+// Leaving out the scheduled time turns a scheduled post back into a draft.
+func (h *Handler) EditDraft(c *gin.Context) {
+	userId := c.MustGet("userId").(string)
+	draftId := c.Param("id")
+
+	var req draftReq
+
+	if ok := bindData(c, &req); !ok {
+		return
+	}
+
+	req.Sanitize()
+
+	draft, err := h.DraftService.FindDraft(userId, draftId)
+
+	if err != nil {
+		log.Printf("Unable to find draft: %v\n%v", draftId, err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	draft, err = h.DraftService.UpdateDraft(draft, req.Text, req.ScheduledAt)
+
+	if err != nil {
+		log.Printf("Failed to edit draft: %v\n%v", draftId, err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, draft.NewDraftResponse())
+}
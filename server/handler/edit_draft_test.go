@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_EditDraft(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	uid := fixture.RandID()
+
+	setupRouter := func(mockDraftService *mocks.DraftService) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:            router,
+			DraftService: mockDraftService,
+		})
+
+		return router
+	}
+
+	newRequest := func(id string, body gin.H) *http.Request {
+		reqBody, err := json.Marshal(body)
+		assert.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPut, "/v1/drafts/"+id, bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		return request
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		at := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		reason := "the post could not be published"
+		draft := &model.Draft{ID: fixture.RandID(), UserID: uid, Text: "Later", Status: model.DraftFailed, Error: &reason}
+		updated := &model.Draft{ID: draft.ID, UserID: uid, Text: "Soon", ScheduledAt: &at, Status: model.DraftScheduled}
+
+		mockDraftService := new(mocks.DraftService)
+		mockDraftService.On("FindDraft", uid, draft.ID).Return(draft, nil)
+		mockDraftService.On("UpdateDraft", draft, "Soon", &at).Return(updated, nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockDraftService).ServeHTTP(rr, newRequest(draft.ID, gin.H{"text": "Soon", "scheduledAt": at}))
+
+		respBody, err := json.Marshal(updated.NewDraftResponse())
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockDraftService.AssertExpectations(t)
+	})
+
+	t.Run("Draft of another user", func(t *testing.T) {
+		id := fixture.RandID()
+		mockErr := apperrors.NewNotFound("draft", id)
+
+		mockDraftService := new(mocks.DraftService)
+		mockDraftService.On("FindDraft", uid, id).Return(nil, mockErr)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockDraftService).ServeHTTP(rr, newRequest(id, gin.H{"text": "Soon"}))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockDraftService.AssertNotCalled(t, "UpdateDraft", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Published draft", func(t *testing.T) {
+		draft := &model.Draft{ID: fixture.RandID(), UserID: uid, Text: "Later", Status: model.DraftPublished}
+		mockErr := apperrors.NewForbidden("the draft got published already")
+
+		mockDraftService := new(mocks.DraftService)
+		mockDraftService.On("FindDraft", uid, draft.ID).Return(draft, nil)
+		mockDraftService.On("UpdateDraft", draft, "Soon", (*time.Time)(nil)).Return(nil, mockErr)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockDraftService).ServeHTTP(rr, newRequest(draft.ID, gin.H{"text": "Soon"}))
+
+		respBody, err := json.Marshal(gin.H{
+			"error": mockErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+	})
+
+	t.Run("Missing text", func(t *testing.T) {
+		id := fixture.RandID()
+		mockDraftService := new(mocks.DraftService)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockDraftService).ServeHTTP(rr, newRequest(id, gin.H{}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockDraftService.AssertNotCalled(t, "FindDraft", uid, id)
+	})
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type editPostReq struct {
+	Text    *string `json:"text" form:"text"`
+	AltText *string `json:"altText" form:"altText"`
+}
+
+func (r editPostReq) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Text, validation.Length(1, 280)),
+		validation.Field(&r.AltText, validation.Length(1, 1000)),
+	)
+}
+
+// Sanitize trims the fields and drops the ones left empty
+func (r *editPostReq) Sanitize() {
+	r.Text = trimToNil(r.Text)
+	r.AltText = trimToNil(r.AltText)
+}
+
+func trimToNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// EditPost replaces the text and alt text of the current user's post
+func (h *Handler) EditPost(c *gin.Context) {
+	postId := c.Param("id")
+
+	userId := c.MustGet("userId").(string)
+
+	var req editPostReq
+
+	if ok := bindData(c, &req); !ok {
+		return
+	}
+
+	req.Sanitize()
+
+	post, err := h.PostService.FindPostByID(postId)
+
+	if err != nil {
+		log.Printf("Unable to find post: %v\n%v", postId, err)
+		e := apperrors.NewNotFound("post", postId)
+
+		c.JSON(e.Status(), gin.H{
+			"error": e,
+		})
+		return
+	}
+
+	if post.UserID != userId {
+		e := apperrors.NewAuthorization("you are not the owner")
+
+		c.JSON(e.Status(), gin.H{
+			"error": e,
+		})
+		return
+	}
+
+	edited, err := h.PostService.EditPost(post, req.Text, req.AltText)
+
+	if err != nil {
+		log.Printf("Unable to edit post: %v\n%v", postId, err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if ok := h.loadPostViewerState(c, userId, []*model.Post{edited}); !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, edited.NewPostResponse())
+}
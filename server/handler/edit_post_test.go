@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_EditPost(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	uid := fixture.RandID()
+
+	setupRouter := func(mockPostService *mocks.PostService) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		return router
+	}
+
+	newRequest := func(id string, body gin.H) *http.Request {
+		reqBody, err := json.Marshal(body)
+		assert.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPut, "/v1/posts/"+id, bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		return request
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.UserID = uid
+
+		text := "Edited text"
+		editedAt := time.Now()
+		edited := *mockPost
+		edited.Text = &text
+		edited.EditedAt = &editedAt
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("EditPost", mockPost, &text, (*string)(nil)).Return(&edited, nil)
+		mockPostService.On("LoadViewerState", uid, mock.Anything).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService)
+
+		router.ServeHTTP(rr, newRequest(mockPost.ID, gin.H{"text": "  Edited text  ", "altText": " "}))
+
+		respBody, err := json.Marshal(edited.NewPostResponse())
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		assert.Contains(t, rr.Body.String(), `"editedAt"`)
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Not the owner of the post", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService)
+
+		router.ServeHTTP(rr, newRequest(mockPost.ID, gin.H{"text": "Edited text"}))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockPostService.AssertNotCalled(t, "EditPost", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Text too long", func(t *testing.T) {
+		mockPostService := new(mocks.PostService)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService)
+
+		router.ServeHTTP(rr, newRequest(fixture.RandID(), gin.H{"text": strings.Repeat("a", 281)}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockPostService.AssertNotCalled(t, "FindPostByID", mock.Anything)
+	})
+
+	t.Run("Edit window closed", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.UserID = uid
+
+		mockErr := apperrors.NewForbidden("the post can't be edited anymore")
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("EditPost", mockPost, mock.Anything, mock.Anything).Return(nil, mockErr)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService)
+
+		router.ServeHTTP(rr, newRequest(mockPost.ID, gin.H{"text": "Edited text"}))
+
+		respBody, err := json.Marshal(gin.H{
+			"error": mockErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertExpectations(t)
+	})
+}
@@ -9,9 +9,18 @@ import (
 
 func (h *Handler) Feed(c *gin.Context) {
 	authUser := c.MustGet("userId").(string)
-	cursor := c.Query("cursor")
 
-	posts, err := h.PostService.GetUserFeed(authUser, cursor)
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	h.feedPage(c, authUser, page)
+}
+
+// feedPage writes the page of the user's home timeline
+func (h *Handler) feedPage(c *gin.Context, authUser string, page model.Page) {
+	posts, err := h.PostService.GetUserFeed(authUser, page)
 
 	if err != nil {
 		e := apperrors.NewNotFound("feed", authUser)
@@ -22,19 +31,17 @@ func (h *Handler) Feed(c *gin.Context) {
 		return
 	}
 
+	items, hasMore := model.Trim(*posts, page)
+
+	if ok := h.loadPostViewerState(c, authUser, postRefs(items)); !ok {
+		return
+	}
+
 	response := make([]model.PostResponse, 0)
 
-	if len(*posts) > 0 {
-		for i, p := range *posts {
-			if i != model.LIMIT {
-				post := p.NewFeedResponse(authUser)
-				response = append(response, post)
-			}
-		}
+	for _, p := range items {
+		response = append(response, p.NewPostResponse())
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"posts":   response,
-		"hasMore": len(*posts) == model.LIMIT+1,
-	})
+	c.JSON(http.StatusOK, postsPage(items, response, hasMore))
 }
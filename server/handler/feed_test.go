@@ -11,9 +11,11 @@ import (
 	"github.com/sentrionic/mirage/model/apperrors"
 	"github.com/sentrionic/mirage/model/fixture"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestHandler_Feed(t *testing.T) {
@@ -29,9 +31,15 @@ func TestHandler_Feed(t *testing.T) {
 		profile.Posts = append(profile.Posts, *mockPost)
 	}
 
+	// The newest entry is a retweet of a followed user
+	retweetedAt := time.Now()
+	profile.Posts[0].RetweetedBy = fixture.GetMockUser()
+	profile.Posts[0].RetweetedAt = &retweetedAt
+
 	t.Run("Success", func(t *testing.T) {
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("GetUserFeed", authUser.ID, "").Return(&profile.Posts, nil)
+		mockPostService.On("GetUserFeed", authUser.ID, model.Page{}).Return(&profile.Posts, nil)
+		mockPostService.On("LoadViewerState", authUser.ID, mock.Anything).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -59,14 +67,11 @@ func TestHandler_Feed(t *testing.T) {
 		rsp := make([]model.PostResponse, 0)
 
 		for _, p := range profile.Posts {
-			post := p.NewFeedResponse(authUser.ID)
+			post := p.NewPostResponse()
 			rsp = append(rsp, post)
 		}
 
-		respBody, err := json.Marshal(gin.H{
-			"posts":   rsp,
-			"hasMore": false,
-		})
+		respBody, err := json.Marshal(postsPage(profile.Posts, rsp, false))
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -76,7 +81,7 @@ func TestHandler_Feed(t *testing.T) {
 
 	t.Run("Unauthorized", func(t *testing.T) {
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("GetUserFeed", authUser.ID, "").Return(&profile.Posts, nil)
+		mockPostService.On("GetUserFeed", authUser.ID, model.Page{}).Return(&profile.Posts, nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -99,12 +104,12 @@ func TestHandler_Feed(t *testing.T) {
 
 		assert.Equal(t, http.StatusUnauthorized, rr.Code)
 
-		mockPostService.AssertNotCalled(t, "GetUserFeed", authUser.ID, "")
+		mockPostService.AssertNotCalled(t, "GetUserFeed", authUser.ID, model.Page{})
 	})
 
 	t.Run("Error", func(t *testing.T) {
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("GetUserFeed", authUser.ID, "").Return(nil, fmt.Errorf("some error down call chain"))
+		mockPostService.On("GetUserFeed", authUser.ID, model.Page{}).Return(nil, fmt.Errorf("some error down call chain"))
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -140,4 +145,92 @@ func TestHandler_Feed(t *testing.T) {
 		assert.Equal(t, respBody, rr.Body.Bytes())
 		mockPostService.AssertExpectations(t)
 	})
+
+	t.Run("Pages with cursors", func(t *testing.T) {
+		posts := make([]model.Post, 0)
+		for i := 0; i < model.LIMIT+1; i++ {
+			posts = append(posts, *fixture.GetMockPost())
+		}
+
+		before := fixture.GetMockPost().Cursor().Encode()
+		cursor, err := model.DecodeCursor(before)
+		assert.NoError(t, err)
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("GetUserFeed", authUser.ID, model.Page{Before: cursor}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", authUser.ID, mock.Anything).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", authUser.ID)
+			c.Set("userId", authUser.ID)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/feed?before="+before, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		var body struct {
+			Posts          []model.PostResponse `json:"posts"`
+			HasMore        bool                 `json:"hasMore"`
+			NextCursor     string               `json:"nextCursor"`
+			PreviousCursor string               `json:"previousCursor"`
+		}
+		err = json.Unmarshal(rr.Body.Bytes(), &body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Len(t, body.Posts, model.LIMIT)
+		assert.True(t, body.HasMore)
+		assert.Equal(t, posts[model.LIMIT-1].Cursor().Encode(), body.NextCursor)
+		assert.Equal(t, posts[0].Cursor().Encode(), body.PreviousCursor)
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		cursor := fixture.GetMockPost().Cursor().Encode()
+
+		for _, query := range []string{"before=2021-01-01T00:00:00Z", "after=e30", "before=" + cursor + "&after=" + cursor} {
+			mockPostService := new(mocks.PostService)
+
+			// a response recorder for getting written http response
+			rr := httptest.NewRecorder()
+
+			router := gin.Default()
+			store := cookie.NewStore([]byte("secret"))
+			router.Use(sessions.Sessions("mqk", store))
+
+			router.Use(func(c *gin.Context) {
+				session := sessions.Default(c)
+				session.Set("userId", authUser.ID)
+				c.Set("userId", authUser.ID)
+			})
+
+			NewHandler(&Config{
+				R:           router,
+				PostService: mockPostService,
+			})
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/posts/feed?"+query, nil)
+			assert.NoError(t, err)
+
+			router.ServeHTTP(rr, request)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mockPostService.AssertNotCalled(t, "GetUserFeed", mock.Anything, mock.Anything)
+		}
+	})
 }
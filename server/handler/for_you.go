@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// ForYou returns a page of the ranked For You timeline.
+// The first page starts a new session, the cursor pages through it.
+// With debug=true every post explains its ranking.
+func (h *Handler) ForYou(c *gin.Context) {
+	authUser := c.MustGet("userId").(string)
+
+	var cursor *model.RankingCursor
+	if value := c.Query("cursor"); value != "" {
+		var err error
+		if cursor, err = model.DecodeRankingCursor(value); err != nil {
+			c.JSON(apperrors.Status(err), gin.H{
+				"error": err,
+			})
+			return
+		}
+	}
+
+	posts, next, err := h.RankingService.ForYou(authUser, cursor)
+
+	if err != nil {
+		log.Printf("Unable to rank posts for user: %v\n%v", authUser, err)
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if ok := h.loadPostViewerState(c, authUser, postRefs(*posts)); !ok {
+		return
+	}
+
+	debug := c.Query("debug") == "true"
+	response := make([]model.PostResponse, 0)
+
+	for _, p := range *posts {
+		r := p.NewPostResponse()
+		if !debug {
+			r.Ranking = nil
+		}
+		response = append(response, r)
+	}
+
+	var nextCursor *string
+	if next != nil {
+		encoded := next.Encode()
+		nextCursor = &encoded
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts":      response,
+		"hasMore":    next != nil,
+		"nextCursor": nextCursor,
+	})
+}
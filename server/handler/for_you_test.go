@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_ForYou(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	authUser := fixture.GetMockUser()
+
+	setupRouter := func(mockRankingService *mocks.RankingService, mockPostService *mocks.PostService) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", authUser.ID)
+			c.Set("userId", authUser.ID)
+		})
+
+		NewHandler(&Config{
+			R:              router,
+			PostService:    mockPostService,
+			RankingService: mockRankingService,
+		})
+
+		return router
+	}
+
+	rankedPosts := func() []model.Post {
+		posts := []model.Post{*fixture.GetMockPost(), *fixture.GetMockPost()}
+		for i := range posts {
+			posts[i].Ranking = &model.Ranking{PostID: posts[i].ID, Rank: i + 1, Source: model.SourceFollowing}
+		}
+		return posts
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		posts := rankedPosts()
+		next := &model.RankingCursor{Session: fixture.RandID(), Offset: model.LIMIT}
+
+		mockRankingService := new(mocks.RankingService)
+		mockRankingService.On("ForYou", authUser.ID, (*model.RankingCursor)(nil)).Return(&posts, next, nil)
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("LoadViewerState", authUser.ID, mock.Anything).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockRankingService, mockPostService)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/foryou", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		rsp := make([]model.PostResponse, 0)
+		for _, p := range posts {
+			r := p.NewPostResponse()
+			r.Ranking = nil
+			rsp = append(rsp, r)
+		}
+
+		respBody, err := json.Marshal(gin.H{
+			"posts":      rsp,
+			"hasMore":    true,
+			"nextCursor": next.Encode(),
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockRankingService.AssertExpectations(t)
+	})
+
+	t.Run("Pages with the cursor and explains the ranking", func(t *testing.T) {
+		posts := rankedPosts()
+		cursor := &model.RankingCursor{Session: fixture.RandID(), Offset: model.LIMIT}
+
+		mockRankingService := new(mocks.RankingService)
+		mockRankingService.On("ForYou", authUser.ID, cursor).Return(&posts, (*model.RankingCursor)(nil), nil)
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("LoadViewerState", authUser.ID, mock.Anything).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockRankingService, mockPostService)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/foryou?debug=true&cursor="+cursor.Encode(), nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		rsp := make([]model.PostResponse, 0)
+		for _, p := range posts {
+			rsp = append(rsp, p.NewPostResponse())
+		}
+
+		respBody, err := json.Marshal(gin.H{
+			"posts":      rsp,
+			"hasMore":    false,
+			"nextCursor": nil,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		assert.Contains(t, rr.Body.String(), `"ranking":{`)
+		mockRankingService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		mockRankingService := new(mocks.RankingService)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockRankingService, new(mocks.PostService))
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/foryou?cursor=invalid", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockRankingService.AssertNotCalled(t, "ForYou", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Expired session", func(t *testing.T) {
+		cursor := &model.RankingCursor{Session: fixture.RandID(), Offset: model.LIMIT}
+		mockErr := apperrors.NewNotFound("session", cursor.Session)
+
+		mockRankingService := new(mocks.RankingService)
+		mockRankingService.On("ForYou", authUser.ID, cursor).Return(nil, nil, mockErr)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockRankingService, new(mocks.PostService))
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/foryou?cursor="+cursor.Encode(), nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(gin.H{
+			"error": mockErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, mockErr.Status(), rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockRankingService.AssertExpectations(t)
+	})
+}
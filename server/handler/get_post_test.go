@@ -12,6 +12,7 @@ import (
 	"github.com/sentrionic/mirage/model/fixture"
 	"github.com/sentrionic/mirage/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -29,6 +30,7 @@ func TestHandler_GetPost(t *testing.T) {
 
 		mockPostService := new(mocks.PostService)
 		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("LoadViewerState", uid, []*model.Post{mockPost}).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -53,7 +55,7 @@ func TestHandler_GetPost(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockPost.NewPostResponse(uid))
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -68,6 +70,7 @@ func TestHandler_GetPost(t *testing.T) {
 
 		mockPostService := new(mocks.PostService)
 		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("LoadViewerState", "", []*model.Post{mockPost}).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -86,7 +89,7 @@ func TestHandler_GetPost(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockPost.NewPostResponse(uid))
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -146,13 +149,16 @@ func TestHandler_GetPost(t *testing.T) {
 		mockPost := fixture.GetMockPost()
 		mockUser := fixture.GetMockUser()
 
-		current := fixture.GetMockUser()
-		current.ID = uid
-		mockUser.Followers = append(mockPost.User.Followers, current)
+		mockUser.FollowerCount = 1
 		mockPost.User = *mockUser
 
 		mockPostService := new(mocks.PostService)
 		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("LoadViewerState", uid, []*model.Post{mockPost}).
+			Run(func(args mock.Arguments) {
+				mockPost.User.Following = true
+			}).
+			Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -177,7 +183,7 @@ func TestHandler_GetPost(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockPost.NewPostResponse(uid))
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -194,12 +200,15 @@ func TestHandler_GetPost(t *testing.T) {
 
 	t.Run("Response post is liked by current user", func(t *testing.T) {
 		mockPost := fixture.GetMockPost()
-		current := fixture.GetMockUser()
-		current.ID = uid
-		mockPost.Likes = append(mockPost.Likes, *current)
+		mockPost.LikeCount = 1
 
 		mockPostService := new(mocks.PostService)
 		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("LoadViewerState", uid, []*model.Post{mockPost}).
+			Run(func(args mock.Arguments) {
+				mockPost.Liked = true
+			}).
+			Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -224,7 +233,7 @@ func TestHandler_GetPost(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockPost.NewPostResponse(uid))
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -241,12 +250,15 @@ func TestHandler_GetPost(t *testing.T) {
 
 	t.Run("Response post is retweeted by current user", func(t *testing.T) {
 		mockPost := fixture.GetMockPost()
-		current := fixture.GetMockUser()
-		current.ID = uid
-		mockPost.Retweets = append(mockPost.Retweets, *current)
+		mockPost.RetweetCount = 1
 
 		mockPostService := new(mocks.PostService)
 		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("LoadViewerState", uid, []*model.Post{mockPost}).
+			Run(func(args mock.Arguments) {
+				mockPost.Retweeted = true
+			}).
+			Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -271,7 +283,7 @@ func TestHandler_GetPost(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockPost.NewPostResponse(uid))
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
 	"github.com/sentrionic/mirage/model/apperrors"
 	"log"
 	"net/http"
@@ -29,5 +30,9 @@ func (h *Handler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, user.NewProfileResponse(userId))
+	if ok := h.loadUserViewerState(c, userId, []*model.User{user}); !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, user.NewProfileResponse())
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/sentrionic/mirage/model/fixture"
 	"github.com/sentrionic/mirage/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -27,6 +28,7 @@ func TestHandler_GetProfile(t *testing.T) {
 
 		mockUserService := new(mocks.UserService)
 		mockUserService.On("FindByUsername", mockUserResp.Username).Return(mockUserResp, nil)
+		mockUserService.On("LoadViewerState", uid, []*model.User{mockUserResp}).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -54,7 +56,7 @@ func TestHandler_GetProfile(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockUserResp.NewProfileResponse(uid))
+		respBody, err := json.Marshal(mockUserResp.NewProfileResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -67,6 +69,7 @@ func TestHandler_GetProfile(t *testing.T) {
 
 		mockUserService := new(mocks.UserService)
 		mockUserService.On("FindByUsername", mockUserResp.Username).Return(mockUserResp, nil)
+		mockUserService.On("LoadViewerState", "", []*model.User{mockUserResp}).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -85,7 +88,7 @@ func TestHandler_GetProfile(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockUserResp.NewProfileResponse(""))
+		respBody, err := json.Marshal(mockUserResp.NewProfileResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -135,12 +138,15 @@ func TestHandler_GetProfile(t *testing.T) {
 
 	t.Run("Response profile contains current user as follower", func(t *testing.T) {
 		mockUserResp := fixture.GetMockUser()
-		current := fixture.GetMockUser()
-		current.ID = uid
-		mockUserResp.Followers = append(mockUserResp.Followers, current)
+		mockUserResp.FollowerCount = 1
 
 		mockUserService := new(mocks.UserService)
 		mockUserService.On("FindByUsername", mockUserResp.Username).Return(mockUserResp, nil)
+		mockUserService.On("LoadViewerState", uid, []*model.User{mockUserResp}).
+			Run(func(args mock.Arguments) {
+				mockUserResp.Following = true
+			}).
+			Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -165,7 +171,7 @@ func TestHandler_GetProfile(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockUserResp.NewProfileResponse(uid))
+		respBody, err := json.Marshal(mockUserResp.NewProfileResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -182,11 +188,11 @@ func TestHandler_GetProfile(t *testing.T) {
 
 	t.Run("Response profile contains follows a person", func(t *testing.T) {
 		mockUserResp := fixture.GetMockUser()
-		user := fixture.GetMockUser()
-		mockUserResp.Followee = append(mockUserResp.Followers, user)
+		mockUserResp.FolloweeCount = 1
 
 		mockUserService := new(mocks.UserService)
 		mockUserService.On("FindByUsername", mockUserResp.Username).Return(mockUserResp, nil)
+		mockUserService.On("LoadViewerState", uid, []*model.User{mockUserResp}).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -211,7 +217,7 @@ func TestHandler_GetProfile(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockUserResp.NewProfileResponse(uid))
+		respBody, err := json.Marshal(mockUserResp.NewProfileResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -14,24 +14,39 @@ import (
 )
 
 type Handler struct {
-	UserService  model.UserService
-	PostService  model.PostService
-	MaxBodyBytes int64
+	UserService     model.UserService
+	PostService     model.PostService
+	RankingService  model.RankingService
+	SearchService   model.SearchService
+	TrendService    model.TrendService
+	DraftService    model.DraftService
+	BookmarkService model.BookmarkService
+	MaxBodyBytes    int64
 }
 
 type Config struct {
 	R               *gin.Engine
 	UserService     model.UserService
 	PostService     model.PostService
+	RankingService  model.RankingService
+	SearchService   model.SearchService
+	TrendService    model.TrendService
+	DraftService    model.DraftService
+	BookmarkService model.BookmarkService
 	TimeoutDuration time.Duration
 	MaxBodyBytes    int64
 }
 
 func NewHandler(c *Config) {
 	h := &Handler{
-		UserService:  c.UserService,
-		PostService:  c.PostService,
-		MaxBodyBytes: c.MaxBodyBytes,
+		UserService:     c.UserService,
+		PostService:     c.PostService,
+		RankingService:  c.RankingService,
+		SearchService:   c.SearchService,
+		TrendService:    c.TrendService,
+		DraftService:    c.DraftService,
+		BookmarkService: c.BookmarkService,
+		MaxBodyBytes:    c.MaxBodyBytes,
 	}
 
 	// set cors settings
@@ -80,14 +95,50 @@ func NewHandler(c *Config) {
 	// Post group
 	pg := c.R.Group("v1/posts")
 	pg.GET("/:id", h.GetPost)
+	pg.GET("/:id/history", h.GetPostHistory)
+	pg.GET("/:id/replies", h.GetReplies)
 
 	pg.Use(middleware.AuthUser())
 	pg.POST("", h.CreatePost)
 	pg.GET("", h.SearchPosts)
 	pg.GET("/feed", h.Feed)
+	pg.GET("/feed/new", h.NewFeedPosts)
+	pg.GET("/foryou", h.ForYou)
+	pg.GET("/mentions", h.GetMentions)
 	pg.POST("/:id/like", h.LikePost)
+	pg.PUT("/:id", h.EditPost)
 	pg.DELETE("/:id", h.DeletePost)
 	pg.POST("/:id/retweet", h.Retweet)
+	pg.POST("/:id/vote", h.VotePoll)
+	pg.POST("/:id/bookmark", h.BookmarkPost)
+	pg.POST("/:id/pin", h.PinPost)
+	pg.POST("/:id/hide", h.HideReply)
+
+	// Draft group
+	dg := c.R.Group("v1/drafts")
+	dg.Use(middleware.AuthUser())
+	dg.GET("", h.GetDrafts)
+	dg.POST("", h.CreateDraft)
+	dg.GET("/:id", h.GetDraft)
+	dg.PUT("/:id", h.EditDraft)
+	dg.DELETE("/:id", h.DeleteDraft)
+
+	// Bookmark group
+	bg := c.R.Group("v1/bookmarks")
+	bg.Use(middleware.AuthUser())
+	bg.GET("", h.GetBookmarks)
+	bg.GET("/folders", h.GetBookmarkFolders)
+	bg.POST("/folders", h.CreateBookmarkFolder)
+	bg.DELETE("/folders/:id", h.DeleteBookmarkFolder)
+
+	// Search group
+	sg := c.R.Group("v1/search")
+	sg.Use(middleware.AuthUser())
+	sg.GET("/typeahead", h.Typeahead)
+
+	// Trend group
+	tg := c.R.Group("v1/trends")
+	tg.GET("", h.GetTrends)
 }
 
 // setUserSession saves the users ID in the session
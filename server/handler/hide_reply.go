@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// HideReply folds the reply away in the thread of the current user's post or shows it again
+func (h *Handler) HideReply(c *gin.Context) {
+	userId := c.MustGet("userId").(string)
+	postId := c.Param("id")
+
+	reply, err := h.PostService.FindPostByID(postId)
+
+	if err != nil {
+		log.Printf("Unable to find post: %v\n%v", postId, err)
+		e := apperrors.NewNotFound("post", postId)
+
+		c.JSON(e.Status(), gin.H{
+			"error": e,
+		})
+		return
+	}
+
+	err = h.PostService.ToggleHidden(reply, userId)
+
+	if err != nil {
+		log.Printf("Failed to change hidden status: %v\n", err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if ok := h.loadPostViewerState(c, userId, []*model.Post{reply}); !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, reply.NewPostResponse())
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_HideReply(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	current := fixture.GetMockUser()
+
+	t.Run("Successful hide", func(t *testing.T) {
+		parentId := fixture.RandID()
+		mockPost := fixture.GetMockPost()
+		mockPost.ReplyToID = &parentId
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("ToggleHidden", mockPost, current.ID).
+			Run(func(args mock.Arguments) {
+				mockPost.Hidden = true
+			}).
+			Return(nil)
+		mockPostService.On("LoadViewerState", current.ID, []*model.Post{mockPost}).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			c.Set("userId", current.ID)
+			session.Set("userId", current.ID)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		url := fmt.Sprintf("/v1/posts/%s/hide", mockPost.ID)
+		request, err := http.NewRequest(http.MethodPost, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+
+		post := &model.PostResponse{}
+		err = json.Unmarshal(rr.Body.Bytes(), post)
+		assert.NoError(t, err)
+		assert.Equal(t, true, post.Hidden)
+
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Successful show", func(t *testing.T) {
+		parentId := fixture.RandID()
+		mockPost := fixture.GetMockPost()
+		mockPost.ReplyToID = &parentId
+		mockPost.Hidden = true
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("ToggleHidden", mockPost, current.ID).
+			Run(func(args mock.Arguments) {
+				mockPost.Hidden = false
+			}).
+			Return(nil)
+		mockPostService.On("LoadViewerState", current.ID, []*model.Post{mockPost}).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			c.Set("userId", current.ID)
+			session.Set("userId", current.ID)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		url := fmt.Sprintf("/v1/posts/%s/hide", mockPost.ID)
+		request, err := http.NewRequest(http.MethodPost, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+
+		post := &model.PostResponse{}
+		err = json.Unmarshal(rr.Body.Bytes(), post)
+		assert.NoError(t, err)
+		assert.Equal(t, false, post.Hidden)
+
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		id := fixture.RandID()
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", id).Return(nil, nil)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		url := fmt.Sprintf("/v1/posts/%s/hide", id)
+		request, err := http.NewRequest(http.MethodPost, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockPostService.AssertNotCalled(t, "FindPostByID", id)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		id := fixture.RandID()
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", id).Return(nil, fmt.Errorf("some error down call chain"))
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			c.Set("userId", current.ID)
+			session.Set("userId", current.ID)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		url := fmt.Sprintf("/v1/posts/%s/hide", id)
+		request, err := http.NewRequest(http.MethodPost, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respErr := apperrors.NewNotFound("post", id)
+
+		respBody, err := json.Marshal(gin.H{
+			"error": respErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, respErr.Status(), rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Reply to another user's post", func(t *testing.T) {
+		parentId := fixture.RandID()
+		mockPost := fixture.GetMockPost()
+		mockPost.ReplyToID = &parentId
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+
+		mockError := apperrors.NewForbidden("only the author of the post can hide its replies")
+		mockPostService.On("ToggleHidden", mockPost, current.ID).Return(mockError)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			c.Set("userId", current.ID)
+			session.Set("userId", current.ID)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		url := fmt.Sprintf("/v1/posts/%s/hide", mockPost.ID)
+		request, err := http.NewRequest(http.MethodPost, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, _ := json.Marshal(gin.H{
+			"error": mockError,
+		})
+
+		assert.Equal(t, mockError.Status(), rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+
+		mockPostService.AssertExpectations(t)
+	})
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
 	"github.com/sentrionic/mirage/model/apperrors"
 	"log"
 	"net/http"
@@ -36,5 +37,9 @@ func (h *Handler) LikePost(c *gin.Context) {
 
 	post, _ = h.PostService.FindPostByID(postId)
 
-	c.JSON(http.StatusOK, post.NewPostResponse(userId))
+	if ok := h.loadPostViewerState(c, userId, []*model.Post{post}); !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, post.NewPostResponse())
 }
@@ -29,9 +29,11 @@ func TestHandler_LikePost(t *testing.T) {
 		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
 		mockPostService.On("ToggleLike", mockPost, current.ID).
 			Run(func(args mock.Arguments) {
-				mockPost.Likes = append(mockPost.Likes, *current)
+				mockPost.LikeCount = 1
+				mockPost.Liked = true
 			}).
 			Return(nil)
+		mockPostService.On("LoadViewerState", current.ID, []*model.Post{mockPost}).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -57,7 +59,7 @@ func TestHandler_LikePost(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockPost.NewPostResponse(current.ID))
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -74,16 +76,18 @@ func TestHandler_LikePost(t *testing.T) {
 
 	t.Run("Successful unlike", func(t *testing.T) {
 		mockPost := fixture.GetMockPost()
-		mockPost.Likes = append(mockPost.Likes, *current)
-		assert.Equal(t, true, mockPost.IsLiked(current.ID))
+		mockPost.LikeCount = 1
+		mockPost.Liked = true
 
 		mockPostService := new(mocks.PostService)
 		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
 		mockPostService.On("ToggleLike", mockPost, current.ID).
 			Run(func(args mock.Arguments) {
-				mockPost.Likes = mockPost.Likes[1:]
+				mockPost.LikeCount = 0
+				mockPost.Liked = false
 			}).
 			Return(nil)
+		mockPostService.On("LoadViewerState", current.ID, []*model.Post{mockPost}).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -109,7 +113,7 @@ func TestHandler_LikePost(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockPost.NewPostResponse(current.ID))
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// GetMentions returns the posts mentioning the current user
+func (h *Handler) GetMentions(c *gin.Context) {
+	authUser := c.MustGet("userId").(string)
+
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	posts, err := h.PostService.GetMentions(authUser, page)
+
+	if err != nil {
+		log.Printf("Unable to find mentions of user: %v\n%v", authUser, err)
+		e := apperrors.NewNotFound("mentions", authUser)
+
+		c.JSON(e.Status(), gin.H{
+			"error": e,
+		})
+		return
+	}
+
+	items, hasMore := model.Trim(*posts, page)
+
+	if ok := h.loadPostViewerState(c, authUser, postRefs(items)); !ok {
+		return
+	}
+
+	response := make([]model.PostResponse, 0)
+
+	for _, p := range items {
+		response = append(response, p.NewPostResponse())
+	}
+
+	c.JSON(http.StatusOK, postsPage(items, response, hasMore))
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_GetMentions(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	authUser := fixture.GetMockUser()
+
+	setupRouter := func(mockPostService *mocks.PostService, authenticated bool) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		if authenticated {
+			router.Use(func(c *gin.Context) {
+				session := sessions.Default(c)
+				session.Set("userId", authUser.ID)
+				c.Set("userId", authUser.ID)
+			})
+		}
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		return router
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		posts := make([]model.Post, 0)
+
+		for i := 0; i < 3; i++ {
+			mockPost := fixture.GetMockPost()
+			mockPost.Mentions = []model.User{*authUser}
+			posts = append(posts, *mockPost)
+		}
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("GetMentions", authUser.ID, model.Page{}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", authUser.ID, mock.Anything).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService, true)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/mentions", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		rsp := make([]model.PostResponse, 0)
+		for _, p := range posts {
+			rsp = append(rsp, p.NewPostResponse())
+		}
+
+		respBody, err := json.Marshal(postsPage(posts, rsp, false))
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		assert.Equal(t, authUser.ID, rsp[0].Mentions[0].ID)
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		mockPostService := new(mocks.PostService)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService, false)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/mentions", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockPostService.AssertNotCalled(t, "GetMentions", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("GetMentions", authUser.ID, model.Page{}).Return(nil, apperrors.NewInternal())
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService, true)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/mentions", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respErr := apperrors.NewNotFound("mentions", authUser.ID)
+		respBody, err := json.Marshal(gin.H{
+			"error": respErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, respErr.Status(), rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertExpectations(t)
+	})
+}
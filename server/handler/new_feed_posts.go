@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// NewFeedPosts returns the number and IDs of the home timeline items newer than the since cursor.
+// With mode=items it returns the items themselves, like the feed does for an after cursor.
+func (h *Handler) NewFeedPosts(c *gin.Context) {
+	authUser := c.MustGet("userId").(string)
+
+	since, err := model.DecodeCursor(c.Query("since"))
+
+	if err != nil {
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if c.Query("mode") == "items" {
+		h.feedPage(c, authUser, model.Page{After: since})
+		return
+	}
+
+	ids, hasMore, err := h.PostService.NewFeedPostIDs(authUser, *since)
+
+	if err != nil {
+		log.Printf("Unable to find new posts for user: %v\n%v", authUser, err)
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(ids),
+		"ids":     ids,
+		"hasMore": hasMore,
+	})
+}
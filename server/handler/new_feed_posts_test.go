@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_NewFeedPosts(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	authUser := fixture.GetMockUser()
+
+	since := fixture.GetMockPost().Cursor().Encode()
+	cursor, _ := model.DecodeCursor(since)
+
+	setupRouter := func(mockPostService *mocks.PostService) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", authUser.ID)
+			c.Set("userId", authUser.ID)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		return router
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		ids := []string{fixture.RandID(), fixture.RandID()}
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("NewFeedPostIDs", authUser.ID, *cursor).Return(ids, false, nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/feed/new?since="+since, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(gin.H{
+			"count":   2,
+			"ids":     ids,
+			"hasMore": false,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertExpectations(t)
+		mockPostService.AssertNotCalled(t, "GetUserFeed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Returns the items", func(t *testing.T) {
+		posts := []model.Post{*fixture.GetMockPost(), *fixture.GetMockPost()}
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("GetUserFeed", authUser.ID, model.Page{After: cursor}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", authUser.ID, mock.Anything).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/feed/new?mode=items&since="+since, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		rsp := make([]model.PostResponse, 0)
+		for _, p := range posts {
+			rsp = append(rsp, p.NewPostResponse())
+		}
+
+		respBody, err := json.Marshal(postsPage(posts, rsp, false))
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertExpectations(t)
+		mockPostService.AssertNotCalled(t, "NewFeedPostIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		mockPostService := new(mocks.PostService)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/feed/new", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockPostService.AssertNotCalled(t, "NewFeedPostIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("NewFeedPostIDs", authUser.ID, *cursor).Return(nil, false, fmt.Errorf("some error down call chain"))
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/feed/new?since="+since, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		mockPostService.AssertExpectations(t)
+	})
+}
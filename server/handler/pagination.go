@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+)
+
+// bindPage reads the before and after cursors of the request.
+// It writes the error response and returns false if they are invalid.
+func bindPage(c *gin.Context) (model.Page, bool) {
+	page, err := model.NewPage(c.Query("before"), c.Query("after"))
+
+	if err != nil {
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return page, false
+	}
+
+	return page, true
+}
+
+// postsPage returns the body for a page of posts together with the cursors
+// to fetch the posts before and after it
+func postsPage(posts []model.Post, response []model.PostResponse, hasMore bool) gin.H {
+	var next, previous *string
+
+	if len(posts) > 0 {
+		older := posts[len(posts)-1].Cursor().Encode()
+		newer := posts[0].Cursor().Encode()
+		next, previous = &older, &newer
+	}
+
+	return gin.H{
+		"posts":          response,
+		"hasMore":        hasMore,
+		"nextCursor":     next,
+		"previousCursor": previous,
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// PinPost pins the post to the profile of the current user or unpins it
+func (h *Handler) PinPost(c *gin.Context) {
+	userId := c.MustGet("userId").(string)
+	postId := c.Param("id")
+
+	post, err := h.PostService.FindPostByID(postId)
+
+	if err != nil {
+		log.Printf("Unable to find post: %v\n%v", postId, err)
+		e := apperrors.NewNotFound("post", postId)
+
+		c.JSON(e.Status(), gin.H{
+			"error": e,
+		})
+		return
+	}
+
+	err = h.PostService.TogglePin(post, userId)
+
+	if err != nil {
+		log.Printf("Failed to change pin status: %v\n", err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if ok := h.loadPostViewerState(c, userId, []*model.Post{post}); !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, post.NewPostResponse())
+}
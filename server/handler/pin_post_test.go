@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_PinPost(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	current := fixture.GetMockUser()
+
+	t.Run("Successful pin", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.UserID = current.ID
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("TogglePin", mockPost, current.ID).
+			Run(func(args mock.Arguments) {
+				mockPost.Pinned = true
+			}).
+			Return(nil)
+		mockPostService.On("LoadViewerState", current.ID, []*model.Post{mockPost}).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			c.Set("userId", current.ID)
+			session.Set("userId", current.ID)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		url := fmt.Sprintf("/v1/posts/%s/pin", mockPost.ID)
+		request, err := http.NewRequest(http.MethodPost, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+
+		post := &model.PostResponse{}
+		err = json.Unmarshal(rr.Body.Bytes(), post)
+		assert.NoError(t, err)
+		assert.Equal(t, true, post.Pinned)
+
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Successful unpin", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.UserID = current.ID
+		mockPost.Pinned = true
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("TogglePin", mockPost, current.ID).
+			Run(func(args mock.Arguments) {
+				mockPost.Pinned = false
+			}).
+			Return(nil)
+		mockPostService.On("LoadViewerState", current.ID, []*model.Post{mockPost}).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			c.Set("userId", current.ID)
+			session.Set("userId", current.ID)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		url := fmt.Sprintf("/v1/posts/%s/pin", mockPost.ID)
+		request, err := http.NewRequest(http.MethodPost, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+
+		post := &model.PostResponse{}
+		err = json.Unmarshal(rr.Body.Bytes(), post)
+		assert.NoError(t, err)
+		assert.Equal(t, false, post.Pinned)
+
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		id := fixture.RandID()
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", id).Return(nil, nil)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		url := fmt.Sprintf("/v1/posts/%s/pin", id)
+		request, err := http.NewRequest(http.MethodPost, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockPostService.AssertNotCalled(t, "FindPostByID", id)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		id := fixture.RandID()
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", id).Return(nil, fmt.Errorf("some error down call chain"))
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			c.Set("userId", current.ID)
+			session.Set("userId", current.ID)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		url := fmt.Sprintf("/v1/posts/%s/pin", id)
+		request, err := http.NewRequest(http.MethodPost, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respErr := apperrors.NewNotFound("post", id)
+
+		respBody, err := json.Marshal(gin.H{
+			"error": respErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, respErr.Status(), rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Post of another user", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+
+		mockError := apperrors.NewForbidden("you can only pin your own posts")
+		mockPostService.On("TogglePin", mockPost, current.ID).Return(mockError)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			c.Set("userId", current.ID)
+			session.Set("userId", current.ID)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		url := fmt.Sprintf("/v1/posts/%s/pin", mockPost.ID)
+		request, err := http.NewRequest(http.MethodPost, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, _ := json.Marshal(gin.H{
+			"error": mockError,
+		})
+
+		assert.Equal(t, mockError.Status(), rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+
+		mockPostService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// GetPostHistory returns every version of the post, newest first
+func (h *Handler) GetPostHistory(c *gin.Context) {
+	postId := c.Param("id")
+
+	post, err := h.PostService.FindPostByID(postId)
+
+	if err != nil {
+		log.Printf("Unable to find post: %v\n%v", postId, err)
+		e := apperrors.NewNotFound("post", postId)
+
+		c.JSON(e.Status(), gin.H{
+			"error": e,
+		})
+		return
+	}
+
+	versions, err := h.PostService.GetPostHistory(post)
+
+	if err != nil {
+		log.Printf("Unable to find history of post: %v\n%v", postId, err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	response := make([]model.PostVersionResponse, 0, len(versions))
+
+	for _, v := range versions {
+		response = append(response, v.NewPostVersionResponse())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"versions": response,
+	})
+}
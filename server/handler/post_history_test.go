@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_GetPostHistory(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+
+	setupRouter := func(mockPostService *mocks.PostService) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		return router
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		original := "First try"
+		editedAt := time.Now()
+		versions := []model.PostVersion{
+			{PostID: mockPost.ID, Text: mockPost.Text, PublishedAt: editedAt},
+			{PostID: mockPost.ID, Text: &original, PublishedAt: mockPost.CreatedAt, ReplacedAt: &editedAt},
+		}
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("GetPostHistory", mockPost).Return(versions, nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/"+mockPost.ID+"/history", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(gin.H{
+			"versions": []model.PostVersionResponse{
+				versions[0].NewPostVersionResponse(),
+				versions[1].NewPostVersionResponse(),
+			},
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		id := fixture.RandID()
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", id).Return(nil, apperrors.NewNotFound("id", id))
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts/"+id+"/history", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockPostService.AssertNotCalled(t, "GetPostHistory", mock.Anything)
+	})
+}
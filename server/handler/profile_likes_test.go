@@ -37,7 +37,8 @@ func TestHandler_GetProfileLikes(t *testing.T) {
 		}
 
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("ProfileLikes", mockUserResp.ID, "").Return(&posts, nil)
+		mockPostService.On("ProfileLikes", mockUserResp.ID, model.Page{}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", uid, mock.Anything).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -67,14 +68,11 @@ func TestHandler_GetProfileLikes(t *testing.T) {
 		rsp := make([]model.PostResponse, 0)
 
 		for _, p := range posts {
-			post := p.NewPostResponse(uid)
+			post := p.NewPostResponse()
 			rsp = append(rsp, post)
 		}
 
-		respBody, err := json.Marshal(gin.H{
-			"posts":   rsp,
-			"hasMore": false,
-		})
+		respBody, err := json.Marshal(postsPage(posts, rsp, false))
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -96,7 +94,8 @@ func TestHandler_GetProfileLikes(t *testing.T) {
 		}
 
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("ProfileLikes", mockUserResp.ID, "").Return(&posts, nil)
+		mockPostService.On("ProfileLikes", mockUserResp.ID, model.Page{}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", "", mock.Anything).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -120,14 +119,11 @@ func TestHandler_GetProfileLikes(t *testing.T) {
 		rsp := make([]model.PostResponse, 0)
 
 		for _, p := range posts {
-			post := p.NewPostResponse(uid)
+			post := p.NewPostResponse()
 			rsp = append(rsp, post)
 		}
 
-		respBody, err := json.Marshal(gin.H{
-			"posts":   rsp,
-			"hasMore": false,
-		})
+		respBody, err := json.Marshal(postsPage(posts, rsp, false))
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -142,7 +138,7 @@ func TestHandler_GetProfileLikes(t *testing.T) {
 		mockUserService.On("FindByUsername", username).Return(nil, fmt.Errorf("some error down call chain"))
 
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("ProfileLikes", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil, nil)
+		mockPostService.On("ProfileLikes", mock.AnythingOfType("string"), mock.AnythingOfType("model.Page")).Return(nil, nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
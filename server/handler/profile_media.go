@@ -10,7 +10,11 @@ import (
 
 func (h *Handler) GetProfileMedia(c *gin.Context) {
 	username := c.Param("username")
-	cursor := c.Query("cursor")
+
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
 
 	var userId string
 	value, exists := c.Get("userId")
@@ -31,7 +35,7 @@ func (h *Handler) GetProfileMedia(c *gin.Context) {
 		return
 	}
 
-	posts, err := h.PostService.ProfileMedia(user.ID, cursor)
+	posts, err := h.PostService.ProfileMedia(user.ID, page)
 
 	if err != nil {
 		log.Printf("Unable to find media posts for user: %v\n%v", username, err)
@@ -43,19 +47,17 @@ func (h *Handler) GetProfileMedia(c *gin.Context) {
 		return
 	}
 
+	items, hasMore := model.Trim(*posts, page)
+
+	if ok := h.loadPostViewerState(c, userId, postRefs(items)); !ok {
+		return
+	}
+
 	response := make([]model.PostResponse, 0)
 
-	if len(*posts) > 0 {
-		for i, p := range *posts {
-			if i != model.LIMIT {
-				post := p.NewPostResponse(userId)
-				response = append(response, post)
-			}
-		}
+	for _, p := range items {
+		response = append(response, p.NewPostResponse())
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"posts":   response,
-		"hasMore": len(*posts) == model.LIMIT+1,
-	})
+	c.JSON(http.StatusOK, postsPage(items, response, hasMore))
 }
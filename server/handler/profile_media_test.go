@@ -38,7 +38,8 @@ func TestHandler_GetProfileMedia(t *testing.T) {
 		}
 
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("ProfileMedia", mockUserResp.ID, "").Return(&posts, nil)
+		mockPostService.On("ProfileMedia", mockUserResp.ID, model.Page{}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", uid, mock.Anything).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -68,14 +69,11 @@ func TestHandler_GetProfileMedia(t *testing.T) {
 		rsp := make([]model.PostResponse, 0)
 
 		for _, p := range posts {
-			post := p.NewPostResponse(uid)
+			post := p.NewPostResponse()
 			rsp = append(rsp, post)
 		}
 
-		respBody, err := json.Marshal(gin.H{
-			"posts":   rsp,
-			"hasMore": false,
-		})
+		respBody, err := json.Marshal(postsPage(posts, rsp, false))
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -98,7 +96,8 @@ func TestHandler_GetProfileMedia(t *testing.T) {
 		}
 
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("ProfileMedia", mockUserResp.ID, "").Return(&posts, nil)
+		mockPostService.On("ProfileMedia", mockUserResp.ID, model.Page{}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", "", mock.Anything).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -122,14 +121,11 @@ func TestHandler_GetProfileMedia(t *testing.T) {
 		rsp := make([]model.PostResponse, 0)
 
 		for _, p := range posts {
-			post := p.NewPostResponse(uid)
+			post := p.NewPostResponse()
 			rsp = append(rsp, post)
 		}
 
-		respBody, err := json.Marshal(gin.H{
-			"posts":   rsp,
-			"hasMore": false,
-		})
+		respBody, err := json.Marshal(postsPage(posts, rsp, false))
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -144,7 +140,7 @@ func TestHandler_GetProfileMedia(t *testing.T) {
 		mockUserService.On("FindByUsername", username).Return(nil, fmt.Errorf("some error down call chain"))
 
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("ProfileMedia", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil, nil)
+		mockPostService.On("ProfileMedia", mock.AnythingOfType("string"), mock.AnythingOfType("model.Page")).Return(nil, nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -10,7 +10,11 @@ import (
 
 func (h *Handler) GetProfilePosts(c *gin.Context) {
 	username := c.Param("username")
-	cursor := c.Query("cursor")
+
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
 
 	var userId string
 	value, exists := c.Get("userId")
@@ -31,7 +35,7 @@ func (h *Handler) GetProfilePosts(c *gin.Context) {
 		return
 	}
 
-	posts, err := h.PostService.ProfilePosts(user.ID, cursor)
+	posts, err := h.PostService.ProfilePosts(user.ID, page)
 
 	if err != nil {
 		log.Printf("Unable to find posts for user: %v\n%v", username, err)
@@ -43,30 +47,34 @@ func (h *Handler) GetProfilePosts(c *gin.Context) {
 		return
 	}
 
-	response := make([]model.PostResponse, 0)
+	items, hasMore := model.Trim(*posts, page)
+	refs := postRefs(items)
 
-	if len(*posts) > 0 {
-		for i, p := range *posts {
-			if i != model.LIMIT {
-				post := model.PostResponse{
-					ID:        p.ID,
-					Text:      p.Text,
-					Likes:     uint(len(p.Likes)),
-					Liked:     p.IsLiked(userId),
-					Retweets:  uint(len(p.Retweets)),
-					Retweeted: p.IsRetweeted(userId),
-					IsRetweet: p.UserID != user.ID,
-					File:      p.File,
-					Author:    p.User.NewProfileResponse(userId),
-					CreatedAt: p.CreatedAt,
-				}
-				response = append(response, post)
-			}
+	// The pinned post leads the first page and doesn't move the cursors
+	var pinned *model.Post
+	if page.Before == nil && page.After == nil && user.PinnedPostID != nil {
+		if pinned, err = h.PostService.FindPostByID(*user.PinnedPostID); err != nil {
+			log.Printf("Unable to find pinned post: %v\n%v", *user.PinnedPostID, err)
+			pinned = nil
+		} else {
+			pinned.Pinned = true
+			refs = append(refs, pinned)
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"posts":   response,
-		"hasMore": len(*posts) == model.LIMIT+1,
-	})
+	if ok := h.loadPostViewerState(c, userId, refs); !ok {
+		return
+	}
+
+	response := make([]model.PostResponse, 0)
+
+	if pinned != nil {
+		response = append(response, pinned.NewPostResponse())
+	}
+
+	for _, p := range items {
+		response = append(response, p.NewPostResponse())
+	}
+
+	c.JSON(http.StatusOK, postsPage(items, response, hasMore))
 }
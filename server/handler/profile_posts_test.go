@@ -25,10 +25,12 @@ func getPostResponse(posts *[]model.Post) []model.PostResponse {
 		post := model.PostResponse{
 			ID:        p.ID,
 			Text:      p.Text,
+			Entities:  model.Entities{},
+			Mentions:  make([]model.Profile, 0),
 			Likes:     uint(len(p.Likes)),
 			Retweets:  uint(len(p.Retweets)),
 			File:      p.File,
-			Author:    p.User.NewProfileResponse(""),
+			Author:    p.User.NewProfileResponse(),
 			CreatedAt: p.CreatedAt,
 		}
 		response = append(response, post)
@@ -57,7 +59,8 @@ func TestHandler_GetProfilePosts(t *testing.T) {
 		}
 
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("ProfilePosts", mockUserResp.ID, "").Return(&posts, nil)
+		mockPostService.On("ProfilePosts", mockUserResp.ID, model.Page{}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", uid, mock.Anything).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -84,10 +87,7 @@ func TestHandler_GetProfilePosts(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(gin.H{
-			"posts":   getPostResponse(&posts),
-			"hasMore": false,
-		})
+		respBody, err := json.Marshal(postsPage(posts, getPostResponse(&posts), false))
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -110,7 +110,8 @@ func TestHandler_GetProfilePosts(t *testing.T) {
 		}
 
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("ProfilePosts", mockUserResp.ID, "").Return(&posts, nil)
+		mockPostService.On("ProfilePosts", mockUserResp.ID, model.Page{}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", "", mock.Anything).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -131,15 +132,131 @@ func TestHandler_GetProfilePosts(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(gin.H{
-			"posts":   getPostResponse(&posts),
-			"hasMore": false,
+		respBody, err := json.Marshal(postsPage(posts, getPostResponse(&posts), false))
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("Pinned post first", func(t *testing.T) {
+		mockUserResp := fixture.GetMockUser()
+		pinned := fixture.GetMockPost()
+		pinned.UserID = mockUserResp.ID
+		mockUserResp.PinnedPostID = &pinned.ID
+
+		mockUserService := new(mocks.UserService)
+		mockUserService.On("FindByUsername", mockUserResp.Username).Return(mockUserResp, nil)
+
+		posts := make([]model.Post, 0)
+
+		for i := 0; i < 5; i++ {
+			mockPost := fixture.GetMockPost()
+			mockPost.UserID = mockUserResp.ID
+			posts = append(posts, *mockPost)
+		}
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("ProfilePosts", mockUserResp.ID, model.Page{}).Return(&posts, nil)
+		mockPostService.On("FindPostByID", pinned.ID).Return(pinned, nil)
+		mockPostService.On("LoadViewerState", uid, mock.MatchedBy(func(refs []*model.Post) bool {
+			return len(refs) == 6 && refs[5] == pinned
+		})).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
 		})
+
+		NewHandler(&Config{
+			R:           router,
+			UserService: mockUserService,
+			PostService: mockPostService,
+		})
+
+		url := fmt.Sprintf("/v1/profiles/%s/posts", mockUserResp.Username)
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		pinnedResp := getPostResponse(&[]model.Post{*pinned})[0]
+		pinnedResp.Pinned = true
+		response := append([]model.PostResponse{pinnedResp}, getPostResponse(&posts)...)
+
+		// The cursors only come from the regular posts
+		respBody, err := json.Marshal(postsPage(posts, response, false))
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
 		assert.Equal(t, respBody, rr.Body.Bytes())
 		mockUserService.AssertExpectations(t)
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Pinned post not on later pages", func(t *testing.T) {
+		mockUserResp := fixture.GetMockUser()
+		pinnedId := fixture.RandID()
+		mockUserResp.PinnedPostID = &pinnedId
+
+		mockUserService := new(mocks.UserService)
+		mockUserService.On("FindByUsername", mockUserResp.Username).Return(mockUserResp, nil)
+
+		posts := make([]model.Post, 0)
+
+		for i := 0; i < 5; i++ {
+			mockPost := fixture.GetMockPost()
+			mockPost.UserID = mockUserResp.ID
+			posts = append(posts, *mockPost)
+		}
+
+		cursor := posts[0].Cursor()
+		page := model.Page{Before: &cursor}
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("ProfilePosts", mockUserResp.ID, mock.AnythingOfType("model.Page")).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", uid, mock.Anything).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			UserService: mockUserService,
+			PostService: mockPostService,
+		})
+
+		url := fmt.Sprintf("/v1/profiles/%s/posts?before=%s", mockUserResp.Username, page.Before.Encode())
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(postsPage(posts, getPostResponse(&posts), false))
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertNotCalled(t, "FindPostByID", pinnedId)
 	})
 
 	t.Run("NotFound", func(t *testing.T) {
@@ -149,7 +266,7 @@ func TestHandler_GetProfilePosts(t *testing.T) {
 		mockUserService.On("FindByUsername", username).Return(nil, fmt.Errorf("some error down call chain"))
 
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("ProfilePosts", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil, nil)
+		mockPostService.On("ProfilePosts", mock.AnythingOfType("string"), mock.AnythingOfType("model.Page")).Return(nil, nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// GetReplies returns the thread of the post, newest replies first.
+// Replies the author hid are folded away and only returned with ?hidden=true.
+func (h *Handler) GetReplies(c *gin.Context) {
+	postId := c.Param("id")
+	hidden := c.Query("hidden") == "true"
+
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	var userId string
+	value, exists := c.Get("userId")
+
+	if exists {
+		userId = value.(string)
+	}
+
+	post, err := h.PostService.FindPostByID(postId)
+
+	if err != nil {
+		log.Printf("Unable to find post: %v\n%v", postId, err)
+		e := apperrors.NewNotFound("post", postId)
+
+		c.JSON(e.Status(), gin.H{
+			"error": e,
+		})
+		return
+	}
+
+	replies, err := h.PostService.GetReplies(post, hidden, page)
+
+	if err != nil {
+		log.Printf("Unable to find replies to post: %v\n%v", postId, err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	items, hasMore := model.Trim(*replies, page)
+
+	if ok := h.loadPostViewerState(c, userId, postRefs(items)); !ok {
+		return
+	}
+
+	response := make([]model.PostResponse, 0)
+
+	for _, p := range items {
+		response = append(response, p.NewPostResponse())
+	}
+
+	c.JSON(http.StatusOK, postsPage(items, response, hasMore))
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_GetReplies(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	uid := fixture.RandID()
+
+	setupRouter := func(mockPostService *mocks.PostService, userId string) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		if userId != "" {
+			router.Use(func(c *gin.Context) {
+				session := sessions.Default(c)
+				session.Set("userId", userId)
+				c.Set("userId", userId)
+			})
+		}
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		return router
+	}
+
+	getReplies := func(post *model.Post) []model.Post {
+		replies := make([]model.Post, 0)
+		for i := 0; i < 5; i++ {
+			reply := fixture.GetMockPost()
+			reply.ReplyToID = &post.ID
+			reply.ReplyPolicy = model.ReplyEveryone
+			replies = append(replies, *reply)
+		}
+		return replies
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		replies := getReplies(mockPost)
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("GetReplies", mockPost, false, model.Page{}).Return(&replies, nil)
+		mockPostService.
+			On("LoadViewerState", uid, mock.Anything).
+			Run(func(args mock.Arguments) {
+				for _, reply := range args.Get(1).([]*model.Post) {
+					reply.CanReply = true
+				}
+			}).
+			Return(nil)
+
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService, uid)
+
+		url := fmt.Sprintf("/v1/posts/%s/replies", mockPost.ID)
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		response := make([]model.PostResponse, 0)
+		for _, reply := range replies {
+			response = append(response, reply.NewPostResponse())
+		}
+
+		respBody, err := json.Marshal(postsPage(replies, response, false))
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		for _, reply := range response {
+			assert.True(t, reply.CanReply)
+			assert.Equal(t, mockPost.ID, *reply.ReplyTo)
+		}
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Hidden replies", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		replies := getReplies(mockPost)
+		for i := range replies {
+			replies[i].Hidden = true
+		}
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("GetReplies", mockPost, true, model.Page{}).Return(&replies, nil)
+		mockPostService.On("LoadViewerState", "", mock.Anything).Return(nil)
+
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService, "")
+
+		url := fmt.Sprintf("/v1/posts/%s/replies?hidden=true", mockPost.ID)
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		response := make([]model.PostResponse, 0)
+		for _, reply := range replies {
+			response = append(response, reply.NewPostResponse())
+		}
+
+		respBody, err := json.Marshal(postsPage(replies, response, false))
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		for _, reply := range response {
+			assert.True(t, reply.Hidden)
+			assert.False(t, reply.CanReply)
+		}
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		id := fixture.RandID()
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", id).Return(nil, fmt.Errorf("some error down call chain"))
+
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService, uid)
+
+		url := fmt.Sprintf("/v1/posts/%s/replies", id)
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respErr := apperrors.NewNotFound("post", id)
+
+		respBody, err := json.Marshal(gin.H{
+			"error": respErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, respErr.Status(), rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertNotCalled(t, "GetReplies", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+
+		mockError := apperrors.NewInternal()
+		mockPostService.On("GetReplies", mockPost, false, model.Page{}).Return(nil, mockError)
+
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockPostService, uid)
+
+		url := fmt.Sprintf("/v1/posts/%s/replies", mockPost.ID)
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, _ := json.Marshal(gin.H{
+			"error": mockError,
+		})
+
+		assert.Equal(t, mockError.Status(), rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertExpectations(t)
+	})
+}
@@ -29,9 +29,11 @@ func TestHandler_Retweet(t *testing.T) {
 		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
 		mockPostService.On("ToggleRetweet", mockPost, current.ID).
 			Run(func(args mock.Arguments) {
-				mockPost.Retweets = append(mockPost.Retweets, *current)
+				mockPost.RetweetCount = 1
+				mockPost.Retweeted = true
 			}).
 			Return(nil)
+		mockPostService.On("LoadViewerState", current.ID, []*model.Post{mockPost}).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -57,7 +59,7 @@ func TestHandler_Retweet(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockPost.NewPostResponse(current.ID))
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -74,16 +76,18 @@ func TestHandler_Retweet(t *testing.T) {
 
 	t.Run("Successful retweet removal", func(t *testing.T) {
 		mockPost := fixture.GetMockPost()
-		mockPost.Retweets = append(mockPost.Retweets, *current)
-		assert.Equal(t, true, mockPost.IsRetweeted(current.ID))
+		mockPost.RetweetCount = 1
+		mockPost.Retweeted = true
 
 		mockPostService := new(mocks.PostService)
 		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
 		mockPostService.On("ToggleRetweet", mockPost, current.ID).
 			Run(func(args mock.Arguments) {
-				mockPost.Retweets = mockPost.Retweets[1:]
+				mockPost.RetweetCount = 0
+				mockPost.Retweeted = false
 			}).
 			Return(nil)
+		mockPostService.On("LoadViewerState", current.ID, []*model.Post{mockPost}).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -109,7 +113,7 @@ func TestHandler_Retweet(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockPost.NewPostResponse(current.ID))
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
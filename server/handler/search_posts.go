@@ -8,16 +8,40 @@ import (
 	"net/http"
 )
 
+// SearchPosts finds posts by the words, phrases and hashtags in their text.
+// The search supports web search syntax like "quoted phrases" and -excluded words,
+// as well as operators like from:username or min_likes:10.
+// Results are sorted by recency unless sort=relevance is given.
 func (h *Handler) SearchPosts(c *gin.Context) {
 	search := c.Query("search")
-	cursor := c.Query("cursor")
+
+	sort, err := model.ParseSearchSort(c.Query("sort"))
+
+	if err != nil {
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
 
 	userId := c.MustGet("userId").(string)
 
-	posts, err := h.PostService.SearchPosts(search, cursor)
+	posts, err := h.PostService.SearchPosts(search, sort, page)
 
 	if err != nil {
 		log.Printf("Unable to find posts for term: %v\n%v", search, err)
+		if apperrors.Status(err) == http.StatusBadRequest {
+			c.JSON(apperrors.Status(err), gin.H{
+				"error": err,
+			})
+			return
+		}
+
 		e := apperrors.NewNotFound("posts", search)
 
 		c.JSON(e.Status(), gin.H{
@@ -26,19 +50,17 @@ func (h *Handler) SearchPosts(c *gin.Context) {
 		return
 	}
 
+	items, hasMore := model.Trim(*posts, page)
+
+	if ok := h.loadPostViewerState(c, userId, postRefs(items)); !ok {
+		return
+	}
+
 	response := make([]model.PostResponse, 0)
 
-	if len(*posts) > 0 {
-		for i, p := range *posts {
-			if i != model.LIMIT {
-				post := p.NewPostResponse(userId)
-				response = append(response, post)
-			}
-		}
+	for _, p := range items {
+		response = append(response, p.NewPostResponse())
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"posts":   response,
-		"hasMore": len(*posts) == model.LIMIT+1,
-	})
+	c.JSON(http.StatusOK, postsPage(items, response, hasMore))
 }
@@ -7,11 +7,14 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sentrionic/mirage/mocks"
 	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
 	"github.com/sentrionic/mirage/model/fixture"
 	"github.com/sentrionic/mirage/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 )
 
@@ -29,7 +32,8 @@ func TestHandler_SearchPosts(t *testing.T) {
 		}
 
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("SearchPosts", "", "").Return(&posts, nil)
+		mockPostService.On("SearchPosts", "", model.SortRecent, model.Page{}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", uid, mock.Anything).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -54,10 +58,7 @@ func TestHandler_SearchPosts(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(gin.H{
-			"posts":   getPostResponse(&posts),
-			"hasMore": false,
-		})
+		respBody, err := json.Marshal(postsPage(posts, getPostResponse(&posts), false))
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -67,7 +68,7 @@ func TestHandler_SearchPosts(t *testing.T) {
 
 	t.Run("Unauthorized", func(t *testing.T) {
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("SearchPosts", "", "").Return(nil, nil)
+		mockPostService.On("SearchPosts", "", model.SortRecent, model.Page{}).Return(nil, nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -87,14 +88,15 @@ func TestHandler_SearchPosts(t *testing.T) {
 		router.ServeHTTP(rr, request)
 
 		assert.Equal(t, http.StatusUnauthorized, rr.Code)
-		mockPostService.AssertNotCalled(t, "SearchPosts", "", "")
+		mockPostService.AssertNotCalled(t, "SearchPosts", mock.Anything, mock.Anything, mock.Anything)
 	})
 
 	t.Run("No results", func(t *testing.T) {
 		posts := make([]model.Post, 0)
 
 		mockPostService := new(mocks.PostService)
-		mockPostService.On("SearchPosts", "", "").Return(&posts, nil)
+		mockPostService.On("SearchPosts", "", model.SortRecent, model.Page{}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", uid, mock.Anything).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -119,14 +121,130 @@ func TestHandler_SearchPosts(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(gin.H{
-			"posts":   &posts,
-			"hasMore": false,
+		respBody, err := json.Marshal(postsPage(posts, []model.PostResponse{}, false))
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Sorted by relevance", func(t *testing.T) {
+		posts := make([]model.Post, 0)
+
+		for i := 0; i < 3; i++ {
+			rank := 0.5 - float64(i)/10
+			mockPost := fixture.GetMockPost()
+			mockPost.SearchRank = &rank
+			posts = append(posts, *mockPost)
+		}
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("SearchPosts", "\"hello world\" -bye", model.SortRelevance, model.Page{}).Return(&posts, nil)
+		mockPostService.On("LoadViewerState", uid, mock.Anything).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
 		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts?sort=relevance&search="+url.QueryEscape("\"hello world\" -bye"), nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(postsPage(posts, getPostResponse(&posts), false))
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
 		assert.Equal(t, respBody, rr.Body.Bytes())
+
+		next, err := model.DecodeCursor(posts[2].Cursor().Encode())
+		assert.NoError(t, err)
+		assert.Equal(t, posts[2].SearchRank, next.Rank)
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid query", func(t *testing.T) {
+		mockErr := apperrors.NewBadRequestAt("since: needs a date like 2024-01-31", 7)
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("SearchPosts", "since:today", model.SortRecent, model.Page{}).Return(nil, mockErr)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts?search=since:today", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(gin.H{
+			"error": mockErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		assert.Contains(t, rr.Body.String(), `"position":7`)
 		mockPostService.AssertExpectations(t)
 	})
+
+	t.Run("Invalid sort", func(t *testing.T) {
+		mockPostService := new(mocks.PostService)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/posts?sort=popular&search=hello", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockPostService.AssertNotCalled(t, "SearchPosts", mock.Anything, mock.Anything, mock.Anything)
+	})
 }
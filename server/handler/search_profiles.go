@@ -8,12 +8,25 @@ import (
 	"net/http"
 )
 
+// SearchProfiles finds users by their username or display name.
+// Results are ranked for the viewer and paged with the cursor of the last result.
 func (h *Handler) SearchProfiles(c *gin.Context) {
 	search := c.Query("search")
 
+	var cursor *model.ProfileCursor
+	if value := c.Query("cursor"); value != "" {
+		var err error
+		if cursor, err = model.DecodeProfileCursor(value); err != nil {
+			c.JSON(apperrors.Status(err), gin.H{
+				"error": err,
+			})
+			return
+		}
+	}
+
 	userId := c.MustGet("userId").(string)
 
-	users, err := h.UserService.Search(search)
+	users, err := h.UserService.Search(search, userId, cursor)
 
 	if err != nil {
 		log.Printf("Unable to find profiles for term: %v\n%v", search, err)
@@ -25,14 +38,32 @@ func (h *Handler) SearchProfiles(c *gin.Context) {
 		return
 	}
 
+	items := *users
+	hasMore := len(items) > model.LIMIT
+	if hasMore {
+		items = items[:model.LIMIT]
+	}
+
+	if ok := h.loadUserViewerState(c, userId, userRefs(items)); !ok {
+		return
+	}
+
 	response := make([]model.Profile, 0)
 
-	if len(*users) > 0 {
-		for _, p := range *users {
-			profile := p.NewProfileResponse(userId)
-			response = append(response, profile)
-		}
+	for _, p := range items {
+		profile := p.NewProfileResponse()
+		response = append(response, profile)
+	}
+
+	var next *string
+	if hasMore {
+		encoded := items[len(items)-1].SearchCursor().Encode()
+		next = &encoded
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, gin.H{
+		"profiles":   response,
+		"hasMore":    hasMore,
+		"nextCursor": next,
+	})
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/sentrionic/mirage/model/fixture"
 	"github.com/sentrionic/mirage/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -29,7 +30,8 @@ func TestHandler_SearchProfiles(t *testing.T) {
 		}
 
 		mockUserService := new(mocks.UserService)
-		mockUserService.On("Search", "").Return(&users, nil)
+		mockUserService.On("Search", "ali", uid, (*model.ProfileCursor)(nil)).Return(&users, nil)
+		mockUserService.On("LoadViewerState", uid, mock.Anything).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -49,7 +51,7 @@ func TestHandler_SearchProfiles(t *testing.T) {
 			UserService: mockUserService,
 		})
 
-		request, err := http.NewRequest(http.MethodGet, "/v1/profiles", nil)
+		request, err := http.NewRequest(http.MethodGet, "/v1/profiles?search=ali", nil)
 		assert.NoError(t, err)
 
 		router.ServeHTTP(rr, request)
@@ -57,11 +59,15 @@ func TestHandler_SearchProfiles(t *testing.T) {
 		rsp := make([]model.Profile, 0)
 
 		for _, u := range users {
-			profile := u.NewProfileResponse(uid)
+			profile := u.NewProfileResponse()
 			rsp = append(rsp, profile)
 		}
 
-		respBody, err := json.Marshal(rsp)
+		respBody, err := json.Marshal(gin.H{
+			"profiles":   rsp,
+			"hasMore":    false,
+			"nextCursor": nil,
+		})
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -71,7 +77,7 @@ func TestHandler_SearchProfiles(t *testing.T) {
 
 	t.Run("Unauthorized", func(t *testing.T) {
 		mockUserService := new(mocks.UserService)
-		mockUserService.On("Search", "").Return(nil, nil)
+		mockUserService.On("Search", "", "", (*model.ProfileCursor)(nil)).Return(nil, nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -91,14 +97,15 @@ func TestHandler_SearchProfiles(t *testing.T) {
 		router.ServeHTTP(rr, request)
 
 		assert.Equal(t, http.StatusUnauthorized, rr.Code)
-		mockUserService.AssertNotCalled(t, "Search")
+		mockUserService.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything)
 	})
 
 	t.Run("No results", func(t *testing.T) {
 		users := make([]model.User, 0)
 
 		mockUserService := new(mocks.UserService)
-		mockUserService.On("Search", "").Return(&users, nil)
+		mockUserService.On("Search", "", uid, (*model.ProfileCursor)(nil)).Return(&users, nil)
+		mockUserService.On("LoadViewerState", uid, mock.Anything).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -123,13 +130,103 @@ func TestHandler_SearchProfiles(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
+		respBody, err := json.Marshal(gin.H{
+			"profiles":   []model.Profile{},
+			"hasMore":    false,
+			"nextCursor": nil,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("Pages with cursors", func(t *testing.T) {
+		users := make([]model.User, 0)
+
+		for i := 0; i < model.LIMIT+1; i++ {
+			mockUser := fixture.GetMockUser()
+			mockUser.SearchRank = 2.5
+			mockUser.FollowerCount = uint(100 - i)
+			users = append(users, *mockUser)
+		}
+
+		cursor := &model.ProfileCursor{Rank: 2.5, Followers: 101, ID: fixture.RandID()}
+
+		mockUserService := new(mocks.UserService)
+		mockUserService.On("Search", "ali", uid, cursor).Return(&users, nil)
+		mockUserService.On("LoadViewerState", uid, mock.Anything).Return(nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			UserService: mockUserService,
+		})
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/profiles?search=ali&cursor="+cursor.Encode(), nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
 		rsp := make([]model.Profile, 0)
 
-		respBody, err := json.Marshal(rsp)
+		for _, u := range users[:model.LIMIT] {
+			profile := u.NewProfileResponse()
+			rsp = append(rsp, profile)
+		}
+
+		respBody, err := json.Marshal(gin.H{
+			"profiles":   rsp,
+			"hasMore":    true,
+			"nextCursor": users[model.LIMIT-1].SearchCursor().Encode(),
+		})
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
 		assert.Equal(t, respBody, rr.Body.Bytes())
 		mockUserService.AssertExpectations(t)
 	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		mockUserService := new(mocks.UserService)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			UserService: mockUserService,
+		})
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/profiles?search=ali&cursor=invalid", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockUserService.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything)
+	})
 }
@@ -30,9 +30,11 @@ func TestHandler_ToggleFollow(t *testing.T) {
 		mockUserService.On("FindByUsername", mockUser.Username).Return(mockUser, nil)
 		mockUserService.On("ChangeFollow", mockUser, current.ID).
 			Run(func(args mock.Arguments) {
-				mockUser.Followers = append(mockUser.Followers, current)
+				mockUser.FollowerCount = 1
+				mockUser.Following = true
 			}).
 			Return(nil)
+		mockUserService.On("LoadViewerState", current.ID, []*model.User{mockUser}).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -58,7 +60,7 @@ func TestHandler_ToggleFollow(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockUser.NewProfileResponse(current.ID))
+		respBody, err := json.Marshal(mockUser.NewProfileResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -75,16 +77,18 @@ func TestHandler_ToggleFollow(t *testing.T) {
 
 	t.Run("Successful unfollow", func(t *testing.T) {
 		mockUser := fixture.GetMockUser()
-		mockUser.Followers = append(mockUser.Followers, current)
-		assert.Equal(t, true, mockUser.IsFollowing(current.ID))
+		mockUser.FollowerCount = 1
+		mockUser.Following = true
 
 		mockUserService := new(mocks.UserService)
 		mockUserService.On("FindByUsername", mockUser.Username).Return(mockUser, nil)
 		mockUserService.On("ChangeFollow", mockUser, current.ID).
 			Run(func(args mock.Arguments) {
-				mockUser.Followers = mockUser.Followers[1:]
+				mockUser.FollowerCount = 0
+				mockUser.Following = false
 			}).
 			Return(nil)
+		mockUserService.On("LoadViewerState", current.ID, []*model.User{mockUser}).Return(nil)
 
 		// a response recorder for getting written http response
 		rr := httptest.NewRecorder()
@@ -110,7 +114,7 @@ func TestHandler_ToggleFollow(t *testing.T) {
 
 		router.ServeHTTP(rr, request)
 
-		respBody, err := json.Marshal(mockUser.NewProfileResponse(current.ID))
+		respBody, err := json.Marshal(mockUser.NewProfileResponse())
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// GetTrends returns the trending hashtags and the number of posts using them recently
+func (h *Handler) GetTrends(c *gin.Context) {
+	trends, err := h.TrendService.GetTrends()
+
+	if err != nil {
+		log.Printf("Unable to get trends\n%v", err)
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trends": trends,
+	})
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_GetTrends(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		trends := []model.Trend{
+			{Hashtag: "golang", Posts: 12, Authors: 8, Score: 3.5},
+			{Hashtag: "gophers", Posts: 4, Authors: 4, Score: 1.2},
+		}
+
+		mockTrendService := new(mocks.TrendService)
+		mockTrendService.On("GetTrends").Return(trends, nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		NewHandler(&Config{
+			R:            router,
+			TrendService: mockTrendService,
+		})
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/trends", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"trends":[{"hashtag":"golang","posts":12},{"hashtag":"gophers","posts":4}]}`, rr.Body.String())
+		mockTrendService.AssertExpectations(t)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockErr := apperrors.NewInternal()
+
+		mockTrendService := new(mocks.TrendService)
+		mockTrendService.On("GetTrends").Return(nil, mockErr)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		NewHandler(&Config{
+			R:            router,
+			TrendService: mockTrendService,
+		})
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/trends", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(gin.H{
+			"error": mockErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, mockErr.Status(), rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockTrendService.AssertExpectations(t)
+	})
+}
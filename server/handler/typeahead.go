@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+// Typeahead suggests users and hashtags for what the user typed so far.
+// Start the query with @ or # to only get users or hashtags.
+func (h *Handler) Typeahead(c *gin.Context) {
+	userId := c.MustGet("userId").(string)
+	query := c.Query("q")
+
+	suggestions, err := h.SearchService.Typeahead(userId, query)
+
+	if err != nil {
+		log.Printf("Unable to find suggestions for query: %v\n%v", query, err)
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	response := make([]model.TypeaheadResponse, 0)
+
+	for _, s := range suggestions {
+		response = append(response, s.NewTypeaheadResponse())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": response,
+	})
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_Typeahead(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	authUser := fixture.GetMockUser()
+
+	setupRouter := func(mockSearchService *mocks.SearchService, authenticated bool) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		if authenticated {
+			router.Use(func(c *gin.Context) {
+				session := sessions.Default(c)
+				session.Set("userId", authUser.ID)
+				c.Set("userId", authUser.ID)
+			})
+		}
+
+		NewHandler(&Config{
+			R:             router,
+			SearchService: mockSearchService,
+		})
+
+		return router
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		user := fixture.GetMockUser()
+		suggestions := []model.TypeaheadSuggestion{
+			{Type: model.SuggestionUser, User: user},
+			{Type: model.SuggestionHashtag, Hashtag: &model.HashtagCount{Tag: "golang", Posts: 4}},
+		}
+
+		mockSearchService := new(mocks.SearchService)
+		mockSearchService.On("Typeahead", authUser.ID, "go").Return(suggestions, nil)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockSearchService, true)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/search/typeahead?q=go", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		profile := user.NewProfileResponse()
+		tag := "golang"
+		posts := uint(4)
+		respBody, err := json.Marshal(gin.H{
+			"results": []model.TypeaheadResponse{
+				{Type: model.SuggestionUser, Profile: &profile},
+				{Type: model.SuggestionHashtag, Hashtag: &tag, Posts: &posts},
+			},
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockSearchService.AssertExpectations(t)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		mockSearchService := new(mocks.SearchService)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockSearchService, false)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/search/typeahead?q=go", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockSearchService.AssertNotCalled(t, "Typeahead", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockErr := apperrors.NewInternal()
+
+		mockSearchService := new(mocks.SearchService)
+		mockSearchService.On("Typeahead", authUser.ID, "go").Return(nil, mockErr)
+
+		// a response recorder for getting written http response
+		rr := httptest.NewRecorder()
+		router := setupRouter(mockSearchService, true)
+
+		request, err := http.NewRequest(http.MethodGet, "/v1/search/typeahead?q=go", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, request)
+
+		respBody, err := json.Marshal(gin.H{
+			"error": mockErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, mockErr.Status(), rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockSearchService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+)
+
+// loadPostViewerState loads if the viewer liked or retweeted the posts and follows their authors.
+// It writes the error response and returns false if that fails.
+func (h *Handler) loadPostViewerState(c *gin.Context, viewerId string, posts []*model.Post) bool {
+	if err := h.PostService.LoadViewerState(viewerId, posts); err != nil {
+		log.Printf("Unable to load post state for viewer: %v\n%v", viewerId, err)
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return false
+	}
+
+	return true
+}
+
+// loadUserViewerState loads if the viewer follows the users.
+// It writes the error response and returns false if that fails.
+func (h *Handler) loadUserViewerState(c *gin.Context, viewerId string, users []*model.User) bool {
+	if err := h.UserService.LoadViewerState(viewerId, users); err != nil {
+		log.Printf("Unable to load user state for viewer: %v\n%v", viewerId, err)
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return false
+	}
+
+	return true
+}
+
+// postRefs returns pointers into the slice, so the posts can be updated in place
+func postRefs(posts []model.Post) []*model.Post {
+	refs := make([]*model.Post, 0, len(posts))
+	for i := range posts {
+		refs = append(refs, &posts[i])
+	}
+	return refs
+}
+
+// userRefs returns pointers into the slice, so the users can be updated in place
+func userRefs(users []model.User) []*model.User {
+	refs := make([]*model.User, 0, len(users))
+	for i := range users {
+		refs = append(refs, &users[i])
+	}
+	return refs
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"net/http"
+)
+
+type votePollReq struct {
+	// Choices are the positions of the chosen options, starting at 0
+	Choices []int `json:"choices"`
+}
+
+func (r votePollReq) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Choices, validation.Required, validation.Length(1, model.PollMaxOptions)),
+	)
+}
+
+// VotePoll handler
+func (h *Handler) VotePoll(c *gin.Context) {
+	userId := c.MustGet("userId").(string)
+	postId := c.Param("id")
+
+	var req votePollReq
+
+	if ok := bindData(c, &req); !ok {
+		return
+	}
+
+	post, err := h.PostService.FindPostByID(postId)
+
+	if err != nil {
+		log.Printf("Unable to find post: %v\n%v", postId, err)
+		e := apperrors.NewNotFound("post", postId)
+
+		c.JSON(e.Status(), gin.H{
+			"error": e,
+		})
+		return
+	}
+
+	err = h.PostService.Vote(post, userId, req.Choices)
+
+	if err != nil {
+		log.Printf("Failed to vote in poll: %v\n", err)
+
+		c.JSON(apperrors.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	post, _ = h.PostService.FindPostByID(postId)
+
+	if ok := h.loadPostViewerState(c, userId, []*model.Post{post}); !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, post.NewPostResponse())
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_VotePoll(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	uid := fixture.RandID()
+
+	setupRouter := func(mockPostService *mocks.PostService) *gin.Engine {
+		router := gin.Default()
+		store := cookie.NewStore([]byte("secret"))
+		router.Use(sessions.Sessions("mqk", store))
+
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			session.Set("userId", uid)
+			c.Set("userId", uid)
+		})
+
+		NewHandler(&Config{
+			R:           router,
+			PostService: mockPostService,
+		})
+
+		return router
+	}
+
+	newRequest := func(id string, body gin.H) *http.Request {
+		reqBody, err := json.Marshal(body)
+		assert.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/v1/posts/"+id+"/vote", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		return request
+	}
+
+	newPoll := func() *model.Poll {
+		return &model.Poll{
+			EndsAt:  time.Now().Add(time.Hour),
+			Options: []model.PollOption{{Position: 0, Text: "Yes", VoteCount: 2}, {Position: 1, Text: "No", VoteCount: 1}},
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.Poll = newPoll()
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("Vote", mockPost, uid, []int{1}).
+			Run(func(args mock.Arguments) {
+				mockPost.Poll.Options[1].VoteCount = 2
+				mockPost.Poll.VoterCount = 4
+			}).
+			Return(nil)
+		mockPostService.On("LoadViewerState", uid, []*model.Post{mockPost}).
+			Run(func(args mock.Arguments) {
+				mockPost.Poll.Choices = []int{1}
+			}).
+			Return(nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockPostService).ServeHTTP(rr, newRequest(mockPost.ID, gin.H{"choices": []int{1}}))
+
+		respBody, err := json.Marshal(mockPost.NewPostResponse())
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+
+		post := &model.PostResponse{}
+		err = json.Unmarshal(rr.Body.Bytes(), post)
+		assert.NoError(t, err)
+		assert.True(t, post.Poll.Voted)
+		assert.Equal(t, []int{1}, post.Poll.Choices)
+		assert.Equal(t, uint(2), *post.Poll.Options[1].Votes)
+
+		mockPostService.AssertExpectations(t)
+	})
+
+	t.Run("Missing choices", func(t *testing.T) {
+		id := fixture.RandID()
+		mockPostService := new(mocks.PostService)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockPostService).ServeHTTP(rr, newRequest(id, gin.H{"choices": []int{}}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockPostService.AssertNotCalled(t, "FindPostByID", id)
+		mockPostService.AssertNotCalled(t, "Vote", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		id := fixture.RandID()
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", id).Return(nil, apperrors.NewNotFound("id", id))
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockPostService).ServeHTTP(rr, newRequest(id, gin.H{"choices": []int{0}}))
+
+		respErr := apperrors.NewNotFound("post", id)
+		respBody, err := json.Marshal(gin.H{
+			"error": respErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, respErr.Status(), rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertNotCalled(t, "Vote", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Already voted", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.Poll = newPoll()
+
+		mockErr := apperrors.NewConflict("vote")
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("Vote", mockPost, uid, []int{0}).Return(mockErr)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockPostService).ServeHTTP(rr, newRequest(mockPost.ID, gin.H{"choices": []int{0}}))
+
+		respBody, err := json.Marshal(gin.H{
+			"error": mockErr,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, respBody, rr.Body.Bytes())
+		mockPostService.AssertNotCalled(t, "LoadViewerState", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Closed poll", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.Poll = newPoll()
+
+		mockErr := apperrors.NewForbidden("the poll is closed")
+
+		mockPostService := new(mocks.PostService)
+		mockPostService.On("FindPostByID", mockPost.ID).Return(mockPost, nil)
+		mockPostService.On("Vote", mockPost, uid, []int{0}).Return(mockErr)
+
+		rr := httptest.NewRecorder()
+		setupRouter(mockPostService).ServeHTTP(rr, newRequest(mockPost.ID, gin.H{"choices": []int{0}}))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/redis"
 	"github.com/sentrionic/mirage/handler"
+	"github.com/sentrionic/mirage/model"
 	"github.com/sentrionic/mirage/repository"
 	"github.com/sentrionic/mirage/service"
 	"log"
@@ -26,6 +27,16 @@ func inject(d *dataSources) (*gin.Engine, error) {
 	 */
 	userRepository := repository.NewUserRepository(d.DB)
 	postRepository := repository.NewPostRepository(d.DB)
+	mediaRepository := repository.NewMediaRepository(d.DB)
+	timelineRepository := repository.NewTimelineRepository(d.DB, d.RedisClient)
+	rankingRepository := repository.NewRankingRepository(d.DB, d.RedisClient)
+	typeaheadRepository := repository.NewTypeaheadRepository(d.DB, d.RedisClient)
+	trendRepository := repository.NewTrendRepository(d.RedisClient)
+	cardRepository := repository.NewCardRepository(d.DB)
+	cardFetcher := repository.NewCardFetcher(model.CardFetchTimeout)
+	draftRepository := repository.NewDraftRepository(d.DB)
+	leaseRepository := repository.NewLeaseRepository(d.RedisClient)
+	bookmarkRepository := repository.NewBookmarkRepository(d.DB)
 
 	bucketName := os.Getenv("AWS_STORAGE_BUCKET_NAME")
 	fileRepository := repository.NewFileRepository(d.S3Session, bucketName)
@@ -34,15 +45,90 @@ func inject(d *dataSources) (*gin.Engine, error) {
 	 * service layer
 	 */
 	userService := service.NewUserService(&service.USConfig{
-		UserRepository: userRepository,
-		FileRepository: fileRepository,
+		UserRepository:      userRepository,
+		FileRepository:      fileRepository,
+		MediaRepository:     mediaRepository,
+		TimelineRepository:  timelineRepository,
+		TypeaheadRepository: typeaheadRepository,
 	})
 
+	editWindow, err := readEditWindow()
+	if err != nil {
+		return nil, err
+	}
+
 	postService := service.NewPostService(&service.PSConfig{
-		PostRepository: postRepository,
-		FileRepository: fileRepository,
+		PostRepository:      postRepository,
+		UserRepository:      userRepository,
+		FileRepository:      fileRepository,
+		MediaRepository:     mediaRepository,
+		TimelineRepository:  timelineRepository,
+		TypeaheadRepository: typeaheadRepository,
+		TrendRepository:     trendRepository,
+		CardRepository:      cardRepository,
+		CardFetcher:         cardFetcher,
+		EditWindow:          editWindow,
+	})
+
+	draftService := service.NewDraftService(&service.DSConfig{
+		DraftRepository: draftRepository,
+		LeaseRepository: leaseRepository,
+		PostService:     postService,
 	})
 
+	schedulerInterval, err := readSchedulerInterval()
+	if err != nil {
+		return nil, err
+	}
+
+	startScheduler(draftService, schedulerInterval)
+
+	bookmarkService := service.NewBookmarkService(&service.BSConfig{
+		BookmarkRepository: bookmarkRepository,
+	})
+
+	trendService := service.NewTrendService(&service.TSConfig{
+		TrendRepository: trendRepository,
+	})
+
+	searchService := service.NewSearchService(&service.SSConfig{
+		TypeaheadRepository: typeaheadRepository,
+		UserRepository:      userRepository,
+	})
+
+	// Users and hashtags from before the typeahead existed only get suggested once they are indexed
+	go func() {
+		if err := typeaheadRepository.Backfill(); err != nil {
+			log.Printf("Unable to backfill typeahead: %v\n", err)
+		}
+	}()
+
+	rankingConfig, err := readRankingConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	rankingService := service.NewRankingService(&service.RSConfig{
+		RankingRepository: rankingRepository,
+		PostRepository:    postRepository,
+		Config:            rankingConfig,
+	})
+
+	mediaService := service.NewMediaService(&service.MSConfig{
+		MediaRepository: mediaRepository,
+		FileRepository:  fileRepository,
+		LeaseRepository: leaseRepository,
+	})
+
+	mediaGC, err := readMediaGCConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if mediaGC != nil {
+		startMediaGC(mediaService, mediaGC)
+	}
+
 	// initialize gin.Engine
 	router := gin.Default()
 	redisURL := os.Getenv("REDIS_URL")
@@ -89,6 +175,11 @@ func inject(d *dataSources) (*gin.Engine, error) {
 		R:               router,
 		UserService:     userService,
 		PostService:     postService,
+		RankingService:  rankingService,
+		SearchService:   searchService,
+		TrendService:    trendService,
+		DraftService:    draftService,
+		BookmarkService: bookmarkService,
 		TimeoutDuration: time.Duration(ht) * time.Second,
 		MaxBodyBytes:    mbb,
 	})
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// runWithLease calls fn in the background once every interval with the holder ID of this instance
+// and the time a lease it acquires lasts. Every instance runs it, but only the one holding the lease
+// does the work. The lease outlives a few intervals, so it only moves once its holder stops renewing it.
+func runWithLease(name string, interval time.Duration, fn func(holder string, lease time.Duration)) {
+	hostname, _ := os.Hostname()
+	holder := fmt.Sprintf("%s:%d:%d", hostname, os.Getpid(), time.Now().UnixNano())
+	lease := 3 * interval
+
+	log.Printf("Running %v every %v as %v\n", name, interval, holder)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			fn(holder, lease)
+		}
+	}()
+}
@@ -22,6 +22,11 @@ type PostListResponse struct {
 	HasMore bool                 `json:"hasMore"`
 }
 
+type ProfileListResponse struct {
+	Profiles []model.Profile `json:"profiles"`
+	HasMore  bool            `json:"hasMore"`
+}
+
 func TestMain_E2E(t *testing.T) {
 	gin.SetMode(gin.ReleaseMode)
 
@@ -555,12 +560,12 @@ func TestMain_E2E(t *testing.T) {
 				assert.Equal(t, http.StatusOK, recorder.Code)
 				assert.NoError(t, err)
 
-				respBody := &[]model.Profile{}
+				respBody := &ProfileListResponse{}
 				err = json.Unmarshal(recorder.Body.Bytes(), respBody)
 				assert.NoError(t, err)
-				assert.NotNil(t, respBody)
+				assert.False(t, respBody.HasMore)
 
-				profiles := *respBody
+				profiles := respBody.Profiles
 				assert.Equal(t, 1, len(profiles))
 				profile := profiles[0]
 
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sentrionic/mirage/model"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// defaultMediaGCGrace is the age objects need to reach before they can be collected
+const defaultMediaGCGrace = 24 * time.Hour
+
+// mediaGCConfig configures the job that deletes orphaned objects from the bucket
+type mediaGCConfig struct {
+	Interval time.Duration
+	Grace    time.Duration
+	DryRun   bool
+}
+
+// readMediaGCConfig reads MEDIA_GC_INTERVAL, MEDIA_GC_GRACE and MEDIA_GC_DRY_RUN.
+// It returns nil if no interval is set, which disables the job.
+func readMediaGCConfig() (*mediaGCConfig, error) {
+	interval := os.Getenv("MEDIA_GC_INTERVAL")
+
+	if interval == "" {
+		return nil, nil
+	}
+
+	config := mediaGCConfig{Grace: defaultMediaGCGrace}
+
+	var err error
+	if config.Interval, err = time.ParseDuration(interval); err != nil || config.Interval <= 0 {
+		return nil, fmt.Errorf("could not parse MEDIA_GC_INTERVAL as a positive duration: %v", interval)
+	}
+
+	if grace := os.Getenv("MEDIA_GC_GRACE"); grace != "" {
+		// Without a grace period uploads get collected before their rows are created
+		if config.Grace, err = time.ParseDuration(grace); err != nil || config.Grace <= 0 {
+			return nil, fmt.Errorf("could not parse MEDIA_GC_GRACE as a positive duration: %v", grace)
+		}
+	}
+
+	if dryRun := os.Getenv("MEDIA_GC_DRY_RUN"); dryRun != "" {
+		if config.DryRun, err = strconv.ParseBool(dryRun); err != nil {
+			return nil, fmt.Errorf("could not parse MEDIA_GC_DRY_RUN as bool: %w", err)
+		}
+	}
+
+	return &config, nil
+}
+
+// startMediaGC collects orphaned objects once every interval and logs a report of each run
+func startMediaGC(mediaService model.MediaService, config *mediaGCConfig) {
+	log.Printf("Collecting orphaned media (dry run: %v)\n", config.DryRun)
+
+	runWithLease("media GC", config.Interval, func(holder string, lease time.Duration) {
+		report, err := mediaService.CollectGarbage(holder, lease, config.Grace, config.DryRun)
+
+		if err != nil {
+			log.Printf("Failed to collect orphaned media: %v\n", err)
+			return
+		}
+
+		if !report.Leader {
+			return
+		}
+
+		b, _ := json.Marshal(report)
+		log.Printf("Collected orphaned media: %s\n", b)
+	})
+}
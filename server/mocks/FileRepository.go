@@ -3,6 +3,7 @@
 package mocks
 
 import (
+	model "github.com/sentrionic/mirage/model"
 	mock "github.com/stretchr/testify/mock"
 
 	multipart "mime/multipart"
@@ -27,20 +28,22 @@ func (_m *FileRepository) DeleteImage(key string) error {
 	return r0
 }
 
-// UploadAvatar provides a mock function with given fields: header, directory
-func (_m *FileRepository) UploadAvatar(header *multipart.FileHeader, directory string) (string, error) {
-	ret := _m.Called(header, directory)
+// ListObjects provides a mock function with given fields: prefix
+func (_m *FileRepository) ListObjects(prefix string) ([]model.StoredObject, error) {
+	ret := _m.Called(prefix)
 
-	var r0 string
-	if rf, ok := ret.Get(0).(func(*multipart.FileHeader, string) string); ok {
-		r0 = rf(header, directory)
+	var r0 []model.StoredObject
+	if rf, ok := ret.Get(0).(func(string) []model.StoredObject); ok {
+		r0 = rf(prefix)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.StoredObject)
+		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*multipart.FileHeader, string) error); ok {
-		r1 = rf(header, directory)
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(prefix)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -48,25 +51,88 @@ func (_m *FileRepository) UploadAvatar(header *multipart.FileHeader, directory s
 	return r0, r1
 }
 
-// UploadBanner provides a mock function with given fields: header, directory
-func (_m *FileRepository) UploadBanner(header *multipart.FileHeader, directory string) (string, error) {
-	ret := _m.Called(header, directory)
+// UploadAvatar provides a mock function with given fields: header, directory, slug
+func (_m *FileRepository) UploadAvatar(header *multipart.FileHeader, directory string, slug string) (model.FileVariant, model.Placeholder, error) {
+	ret := _m.Called(header, directory, slug)
 
-	var r0 string
-	if rf, ok := ret.Get(0).(func(*multipart.FileHeader, string) string); ok {
-		r0 = rf(header, directory)
+	var r0 model.FileVariant
+	if rf, ok := ret.Get(0).(func(*multipart.FileHeader, string, string) model.FileVariant); ok {
+		r0 = rf(header, directory, slug)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(model.FileVariant)
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(*multipart.FileHeader, string) error); ok {
-		r1 = rf(header, directory)
+	var r1 model.Placeholder
+	if rf, ok := ret.Get(1).(func(*multipart.FileHeader, string, string) model.Placeholder); ok {
+		r1 = rf(header, directory, slug)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(model.Placeholder)
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(*multipart.FileHeader, string, string) error); ok {
+		r2 = rf(header, directory, slug)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UploadBanner provides a mock function with given fields: header, directory, slug
+func (_m *FileRepository) UploadBanner(header *multipart.FileHeader, directory string, slug string) (model.FileVariant, model.Placeholder, error) {
+	ret := _m.Called(header, directory, slug)
+
+	var r0 model.FileVariant
+	if rf, ok := ret.Get(0).(func(*multipart.FileHeader, string, string) model.FileVariant); ok {
+		r0 = rf(header, directory, slug)
+	} else {
+		r0 = ret.Get(0).(model.FileVariant)
+	}
+
+	var r1 model.Placeholder
+	if rf, ok := ret.Get(1).(func(*multipart.FileHeader, string, string) model.Placeholder); ok {
+		r1 = rf(header, directory, slug)
+	} else {
+		r1 = ret.Get(1).(model.Placeholder)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(*multipart.FileHeader, string, string) error); ok {
+		r2 = rf(header, directory, slug)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UploadCardImage provides a mock function with given fields: data, directory, slug
+func (_m *FileRepository) UploadCardImage(data []byte, directory string, slug string) (model.FileVariant, model.Placeholder, error) {
+	ret := _m.Called(data, directory, slug)
+
+	var r0 model.FileVariant
+	if rf, ok := ret.Get(0).(func([]byte, string, string) model.FileVariant); ok {
+		r0 = rf(data, directory, slug)
+	} else {
+		r0 = ret.Get(0).(model.FileVariant)
+	}
+
+	var r1 model.Placeholder
+	if rf, ok := ret.Get(1).(func([]byte, string, string) model.Placeholder); ok {
+		r1 = rf(data, directory, slug)
+	} else {
+		r1 = ret.Get(1).(model.Placeholder)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func([]byte, string, string) error); ok {
+		r2 = rf(data, directory, slug)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // UploadFile provides a mock function with given fields: header, directory, filename, mimetype
@@ -89,3 +155,33 @@ func (_m *FileRepository) UploadFile(header *multipart.FileHeader, directory str
 
 	return r0, r1
 }
+
+// UploadImage provides a mock function with given fields: header, directory, slug
+func (_m *FileRepository) UploadImage(header *multipart.FileHeader, directory string, slug string) ([]model.FileVariant, model.Placeholder, error) {
+	ret := _m.Called(header, directory, slug)
+
+	var r0 []model.FileVariant
+	if rf, ok := ret.Get(0).(func(*multipart.FileHeader, string, string) []model.FileVariant); ok {
+		r0 = rf(header, directory, slug)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.FileVariant)
+		}
+	}
+
+	var r1 model.Placeholder
+	if rf, ok := ret.Get(1).(func(*multipart.FileHeader, string, string) model.Placeholder); ok {
+		r1 = rf(header, directory, slug)
+	} else {
+		r1 = ret.Get(1).(model.Placeholder)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(*multipart.FileHeader, string, string) error); ok {
+		r2 = rf(header, directory, slug)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
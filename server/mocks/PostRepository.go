@@ -40,6 +40,20 @@ func (_m *PostRepository) AddRetweet(post *model.Post, uid string) error {
 	return r0
 }
 
+// AddVote provides a mock function with given fields: postId, userId, choices
+func (_m *PostRepository) AddVote(postId string, userId string, choices []int) error {
+	ret := _m.Called(postId, userId, choices)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, []int) error); ok {
+		r0 = rf(postId, userId, choices)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Create provides a mock function with given fields: post
 func (_m *PostRepository) Create(post *model.Post) (*model.Post, error) {
 	ret := _m.Called(post)
@@ -77,22 +91,22 @@ func (_m *PostRepository) Delete(post *model.Post) error {
 	return r0
 }
 
-// Feed provides a mock function with given fields: userId, cursor
-func (_m *PostRepository) Feed(userId string, cursor string) (*[]model.Post, error) {
-	ret := _m.Called(userId, cursor)
+// FindByID provides a mock function with given fields: id
+func (_m *PostRepository) FindByID(id string) (*model.Post, error) {
+	ret := _m.Called(id)
 
-	var r0 *[]model.Post
-	if rf, ok := ret.Get(0).(func(string, string) *[]model.Post); ok {
-		r0 = rf(userId, cursor)
+	var r0 *model.Post
+	if rf, ok := ret.Get(0).(func(string) *model.Post); ok {
+		r0 = rf(id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*[]model.Post)
+			r0 = ret.Get(0).(*model.Post)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(userId, cursor)
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -100,22 +114,22 @@ func (_m *PostRepository) Feed(userId string, cursor string) (*[]model.Post, err
 	return r0, r1
 }
 
-// FindByID provides a mock function with given fields: id
-func (_m *PostRepository) FindByID(id string) (*model.Post, error) {
-	ret := _m.Called(id)
+// FindByIDs provides a mock function with given fields: ids
+func (_m *PostRepository) FindByIDs(ids []string) (*[]model.Post, error) {
+	ret := _m.Called(ids)
 
-	var r0 *model.Post
-	if rf, ok := ret.Get(0).(func(string) *model.Post); ok {
-		r0 = rf(id)
+	var r0 *[]model.Post
+	if rf, ok := ret.Get(0).(func([]string) *[]model.Post); ok {
+		r0 = rf(ids)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Post)
+			r0 = ret.Get(0).(*[]model.Post)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(ids)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -123,22 +137,22 @@ func (_m *PostRepository) FindByID(id string) (*model.Post, error) {
 	return r0, r1
 }
 
-// GetPostsForHashtag provides a mock function with given fields: tag, cursor
-func (_m *PostRepository) GetPostsForHashtag(tag string, cursor string) (*[]model.Post, error) {
-	ret := _m.Called(tag, cursor)
+// FindVersions provides a mock function with given fields: postId
+func (_m *PostRepository) FindVersions(postId string) ([]model.PostVersion, error) {
+	ret := _m.Called(postId)
 
-	var r0 *[]model.Post
-	if rf, ok := ret.Get(0).(func(string, string) *[]model.Post); ok {
-		r0 = rf(tag, cursor)
+	var r0 []model.PostVersion
+	if rf, ok := ret.Get(0).(func(string) []model.PostVersion); ok {
+		r0 = rf(postId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*[]model.Post)
+			r0 = ret.Get(0).([]model.PostVersion)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(tag, cursor)
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(postId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -146,13 +160,13 @@ func (_m *PostRepository) GetPostsForHashtag(tag string, cursor string) (*[]mode
 	return r0, r1
 }
 
-// Likes provides a mock function with given fields: id, cursor
-func (_m *PostRepository) Likes(id string, cursor string) (*[]model.Post, error) {
-	ret := _m.Called(id, cursor)
+// Likes provides a mock function with given fields: id, page
+func (_m *PostRepository) Likes(id string, page model.Page) (*[]model.Post, error) {
+	ret := _m.Called(id, page)
 
 	var r0 *[]model.Post
-	if rf, ok := ret.Get(0).(func(string, string) *[]model.Post); ok {
-		r0 = rf(id, cursor)
+	if rf, ok := ret.Get(0).(func(string, model.Page) *[]model.Post); ok {
+		r0 = rf(id, page)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*[]model.Post)
@@ -160,8 +174,8 @@ func (_m *PostRepository) Likes(id string, cursor string) (*[]model.Post, error)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(id, cursor)
+	if rf, ok := ret.Get(1).(func(string, model.Page) error); ok {
+		r1 = rf(id, page)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -169,13 +183,27 @@ func (_m *PostRepository) Likes(id string, cursor string) (*[]model.Post, error)
 	return r0, r1
 }
 
-// List provides a mock function with given fields: id, cursor
-func (_m *PostRepository) List(id string, cursor string) (*[]model.Post, error) {
-	ret := _m.Called(id, cursor)
+// LoadViewerState provides a mock function with given fields: viewerId, posts
+func (_m *PostRepository) LoadViewerState(viewerId string, posts []*model.Post) error {
+	ret := _m.Called(viewerId, posts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []*model.Post) error); ok {
+		r0 = rf(viewerId, posts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Media provides a mock function with given fields: id, page
+func (_m *PostRepository) Media(id string, page model.Page) (*[]model.Post, error) {
+	ret := _m.Called(id, page)
 
 	var r0 *[]model.Post
-	if rf, ok := ret.Get(0).(func(string, string) *[]model.Post); ok {
-		r0 = rf(id, cursor)
+	if rf, ok := ret.Get(0).(func(string, model.Page) *[]model.Post); ok {
+		r0 = rf(id, page)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*[]model.Post)
@@ -183,8 +211,8 @@ func (_m *PostRepository) List(id string, cursor string) (*[]model.Post, error)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(id, cursor)
+	if rf, ok := ret.Get(1).(func(string, model.Page) error); ok {
+		r1 = rf(id, page)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -192,13 +220,13 @@ func (_m *PostRepository) List(id string, cursor string) (*[]model.Post, error)
 	return r0, r1
 }
 
-// Media provides a mock function with given fields: id, cursor
-func (_m *PostRepository) Media(id string, cursor string) (*[]model.Post, error) {
-	ret := _m.Called(id, cursor)
+// Mentions provides a mock function with given fields: userId, page
+func (_m *PostRepository) Mentions(userId string, page model.Page) (*[]model.Post, error) {
+	ret := _m.Called(userId, page)
 
 	var r0 *[]model.Post
-	if rf, ok := ret.Get(0).(func(string, string) *[]model.Post); ok {
-		r0 = rf(id, cursor)
+	if rf, ok := ret.Get(0).(func(string, model.Page) *[]model.Post); ok {
+		r0 = rf(userId, page)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*[]model.Post)
@@ -206,8 +234,8 @@ func (_m *PostRepository) Media(id string, cursor string) (*[]model.Post, error)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(id, cursor)
+	if rf, ok := ret.Get(1).(func(string, model.Page) error); ok {
+		r1 = rf(userId, page)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -242,3 +270,77 @@ func (_m *PostRepository) RemoveRetweet(post *model.Post, uid string) error {
 
 	return r0
 }
+
+// Replies provides a mock function with given fields: postId, hidden, page
+func (_m *PostRepository) Replies(postId string, hidden bool, page model.Page) (*[]model.Post, error) {
+	ret := _m.Called(postId, hidden, page)
+
+	var r0 *[]model.Post
+	if rf, ok := ret.Get(0).(func(string, bool, model.Page) *[]model.Post); ok {
+		r0 = rf(postId, hidden, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*[]model.Post)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, bool, model.Page) error); ok {
+		r1 = rf(postId, hidden, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: query, page
+func (_m *PostRepository) Search(query model.PostQuery, page model.Page) (*[]model.Post, error) {
+	ret := _m.Called(query, page)
+
+	var r0 *[]model.Post
+	if rf, ok := ret.Get(0).(func(model.PostQuery, model.Page) *[]model.Post); ok {
+		r0 = rf(query, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*[]model.Post)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(model.PostQuery, model.Page) error); ok {
+		r1 = rf(query, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetHidden provides a mock function with given fields: reply, hidden
+func (_m *PostRepository) SetHidden(reply *model.Post, hidden bool) error {
+	ret := _m.Called(reply, hidden)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Post, bool) error); ok {
+		r0 = rf(reply, hidden)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: post
+func (_m *PostRepository) Update(post *model.Post) error {
+	ret := _m.Called(post)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Post) error); ok {
+		r0 = rf(post)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -51,6 +51,29 @@ func (_m *PostService) DeletePost(post *model.Post) error {
 	return r0
 }
 
+// EditPost provides a mock function with given fields: post, text, altText
+func (_m *PostService) EditPost(post *model.Post, text *string, altText *string) (*model.Post, error) {
+	ret := _m.Called(post, text, altText)
+
+	var r0 *model.Post
+	if rf, ok := ret.Get(0).(func(*model.Post, *string, *string) *model.Post); ok {
+		r0 = rf(post, text, altText)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Post)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.Post, *string, *string) error); ok {
+		r1 = rf(post, text, altText)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindPostByID provides a mock function with given fields: id
 func (_m *PostService) FindPostByID(id string) (*model.Post, error) {
 	ret := _m.Called(id)
@@ -74,13 +97,82 @@ func (_m *PostService) FindPostByID(id string) (*model.Post, error) {
 	return r0, r1
 }
 
-// GetUserFeed provides a mock function with given fields: userId, cursor
-func (_m *PostService) GetUserFeed(userId string, cursor string) (*[]model.Post, error) {
-	ret := _m.Called(userId, cursor)
+// GetMentions provides a mock function with given fields: userId, page
+func (_m *PostService) GetMentions(userId string, page model.Page) (*[]model.Post, error) {
+	ret := _m.Called(userId, page)
+
+	var r0 *[]model.Post
+	if rf, ok := ret.Get(0).(func(string, model.Page) *[]model.Post); ok {
+		r0 = rf(userId, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*[]model.Post)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, model.Page) error); ok {
+		r1 = rf(userId, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPostHistory provides a mock function with given fields: post
+func (_m *PostService) GetPostHistory(post *model.Post) ([]model.PostVersion, error) {
+	ret := _m.Called(post)
+
+	var r0 []model.PostVersion
+	if rf, ok := ret.Get(0).(func(*model.Post) []model.PostVersion); ok {
+		r0 = rf(post)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.PostVersion)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.Post) error); ok {
+		r1 = rf(post)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReplies provides a mock function with given fields: post, hidden, page
+func (_m *PostService) GetReplies(post *model.Post, hidden bool, page model.Page) (*[]model.Post, error) {
+	ret := _m.Called(post, hidden, page)
+
+	var r0 *[]model.Post
+	if rf, ok := ret.Get(0).(func(*model.Post, bool, model.Page) *[]model.Post); ok {
+		r0 = rf(post, hidden, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*[]model.Post)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.Post, bool, model.Page) error); ok {
+		r1 = rf(post, hidden, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserFeed provides a mock function with given fields: userId, page
+func (_m *PostService) GetUserFeed(userId string, page model.Page) (*[]model.Post, error) {
+	ret := _m.Called(userId, page)
 
 	var r0 *[]model.Post
-	if rf, ok := ret.Get(0).(func(string, string) *[]model.Post); ok {
-		r0 = rf(userId, cursor)
+	if rf, ok := ret.Get(0).(func(string, model.Page) *[]model.Post); ok {
+		r0 = rf(userId, page)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*[]model.Post)
@@ -88,8 +180,8 @@ func (_m *PostService) GetUserFeed(userId string, cursor string) (*[]model.Post,
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(userId, cursor)
+	if rf, ok := ret.Get(1).(func(string, model.Page) error); ok {
+		r1 = rf(userId, page)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -97,13 +189,57 @@ func (_m *PostService) GetUserFeed(userId string, cursor string) (*[]model.Post,
 	return r0, r1
 }
 
-// ProfileLikes provides a mock function with given fields: id, cursor
-func (_m *PostService) ProfileLikes(id string, cursor string) (*[]model.Post, error) {
-	ret := _m.Called(id, cursor)
+// LoadViewerState provides a mock function with given fields: viewerId, posts
+func (_m *PostService) LoadViewerState(viewerId string, posts []*model.Post) error {
+	ret := _m.Called(viewerId, posts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []*model.Post) error); ok {
+		r0 = rf(viewerId, posts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewFeedPostIDs provides a mock function with given fields: userId, since
+func (_m *PostService) NewFeedPostIDs(userId string, since model.Cursor) ([]string, bool, error) {
+	ret := _m.Called(userId, since)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string, model.Cursor) []string); ok {
+		r0 = rf(userId, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(string, model.Cursor) bool); ok {
+		r1 = rf(userId, since)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, model.Cursor) error); ok {
+		r2 = rf(userId, since)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ProfileLikes provides a mock function with given fields: id, page
+func (_m *PostService) ProfileLikes(id string, page model.Page) (*[]model.Post, error) {
+	ret := _m.Called(id, page)
 
 	var r0 *[]model.Post
-	if rf, ok := ret.Get(0).(func(string, string) *[]model.Post); ok {
-		r0 = rf(id, cursor)
+	if rf, ok := ret.Get(0).(func(string, model.Page) *[]model.Post); ok {
+		r0 = rf(id, page)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*[]model.Post)
@@ -111,8 +247,8 @@ func (_m *PostService) ProfileLikes(id string, cursor string) (*[]model.Post, er
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(id, cursor)
+	if rf, ok := ret.Get(1).(func(string, model.Page) error); ok {
+		r1 = rf(id, page)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -120,13 +256,13 @@ func (_m *PostService) ProfileLikes(id string, cursor string) (*[]model.Post, er
 	return r0, r1
 }
 
-// ProfileMedia provides a mock function with given fields: id, cursor
-func (_m *PostService) ProfileMedia(id string, cursor string) (*[]model.Post, error) {
-	ret := _m.Called(id, cursor)
+// ProfileMedia provides a mock function with given fields: id, page
+func (_m *PostService) ProfileMedia(id string, page model.Page) (*[]model.Post, error) {
+	ret := _m.Called(id, page)
 
 	var r0 *[]model.Post
-	if rf, ok := ret.Get(0).(func(string, string) *[]model.Post); ok {
-		r0 = rf(id, cursor)
+	if rf, ok := ret.Get(0).(func(string, model.Page) *[]model.Post); ok {
+		r0 = rf(id, page)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*[]model.Post)
@@ -134,8 +270,8 @@ func (_m *PostService) ProfileMedia(id string, cursor string) (*[]model.Post, er
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(id, cursor)
+	if rf, ok := ret.Get(1).(func(string, model.Page) error); ok {
+		r1 = rf(id, page)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -143,13 +279,13 @@ func (_m *PostService) ProfileMedia(id string, cursor string) (*[]model.Post, er
 	return r0, r1
 }
 
-// ProfilePosts provides a mock function with given fields: id, cursor
-func (_m *PostService) ProfilePosts(id string, cursor string) (*[]model.Post, error) {
-	ret := _m.Called(id, cursor)
+// ProfilePosts provides a mock function with given fields: id, page
+func (_m *PostService) ProfilePosts(id string, page model.Page) (*[]model.Post, error) {
+	ret := _m.Called(id, page)
 
 	var r0 *[]model.Post
-	if rf, ok := ret.Get(0).(func(string, string) *[]model.Post); ok {
-		r0 = rf(id, cursor)
+	if rf, ok := ret.Get(0).(func(string, model.Page) *[]model.Post); ok {
+		r0 = rf(id, page)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*[]model.Post)
@@ -157,8 +293,8 @@ func (_m *PostService) ProfilePosts(id string, cursor string) (*[]model.Post, er
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(id, cursor)
+	if rf, ok := ret.Get(1).(func(string, model.Page) error); ok {
+		r1 = rf(id, page)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -166,13 +302,13 @@ func (_m *PostService) ProfilePosts(id string, cursor string) (*[]model.Post, er
 	return r0, r1
 }
 
-// SearchPosts provides a mock function with given fields: tag, cursor
-func (_m *PostService) SearchPosts(tag string, cursor string) (*[]model.Post, error) {
-	ret := _m.Called(tag, cursor)
+// SearchPosts provides a mock function with given fields: query, sort, page
+func (_m *PostService) SearchPosts(query string, sort string, page model.Page) (*[]model.Post, error) {
+	ret := _m.Called(query, sort, page)
 
 	var r0 *[]model.Post
-	if rf, ok := ret.Get(0).(func(string, string) *[]model.Post); ok {
-		r0 = rf(tag, cursor)
+	if rf, ok := ret.Get(0).(func(string, string, model.Page) *[]model.Post); ok {
+		r0 = rf(query, sort, page)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*[]model.Post)
@@ -180,8 +316,8 @@ func (_m *PostService) SearchPosts(tag string, cursor string) (*[]model.Post, er
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(tag, cursor)
+	if rf, ok := ret.Get(1).(func(string, string, model.Page) error); ok {
+		r1 = rf(query, sort, page)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -189,6 +325,20 @@ func (_m *PostService) SearchPosts(tag string, cursor string) (*[]model.Post, er
 	return r0, r1
 }
 
+// ToggleHidden provides a mock function with given fields: reply, uid
+func (_m *PostService) ToggleHidden(reply *model.Post, uid string) error {
+	ret := _m.Called(reply, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Post, string) error); ok {
+		r0 = rf(reply, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ToggleLike provides a mock function with given fields: post, uid
 func (_m *PostService) ToggleLike(post *model.Post, uid string) error {
 	ret := _m.Called(post, uid)
@@ -203,6 +353,20 @@ func (_m *PostService) ToggleLike(post *model.Post, uid string) error {
 	return r0
 }
 
+// TogglePin provides a mock function with given fields: post, uid
+func (_m *PostService) TogglePin(post *model.Post, uid string) error {
+	ret := _m.Called(post, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Post, string) error); ok {
+		r0 = rf(post, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ToggleRetweet provides a mock function with given fields: post, uid
 func (_m *PostService) ToggleRetweet(post *model.Post, uid string) error {
 	ret := _m.Called(post, uid)
@@ -239,3 +403,17 @@ func (_m *PostService) UploadFile(header *multipart.FileHeader) (*model.File, er
 
 	return r0, r1
 }
+
+// Vote provides a mock function with given fields: post, uid, choices
+func (_m *PostService) Vote(post *model.Post, uid string, choices []int) error {
+	ret := _m.Called(post, uid, choices)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Post, string, []int) error); ok {
+		r0 = rf(post, uid, choices)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
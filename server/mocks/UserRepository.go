@@ -95,6 +95,29 @@ func (_m *UserRepository) FindByID(uid string) (*model.User, error) {
 	return r0, r1
 }
 
+// FindByIDs provides a mock function with given fields: ids
+func (_m *UserRepository) FindByIDs(ids []string) (*[]model.User, error) {
+	ret := _m.Called(ids)
+
+	var r0 *[]model.User
+	if rf, ok := ret.Get(0).(func([]string) *[]model.User); ok {
+		r0 = rf(ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*[]model.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByUsername provides a mock function with given fields: username
 func (_m *UserRepository) FindByUsername(username string) (*model.User, error) {
 	ret := _m.Called(username)
@@ -118,6 +141,43 @@ func (_m *UserRepository) FindByUsername(username string) (*model.User, error) {
 	return r0, r1
 }
 
+// FindByUsernames provides a mock function with given fields: usernames
+func (_m *UserRepository) FindByUsernames(usernames []string) (*[]model.User, error) {
+	ret := _m.Called(usernames)
+
+	var r0 *[]model.User
+	if rf, ok := ret.Get(0).(func([]string) *[]model.User); ok {
+		r0 = rf(usernames)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*[]model.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(usernames)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LoadViewerState provides a mock function with given fields: viewerId, users
+func (_m *UserRepository) LoadViewerState(viewerId string, users []*model.User) error {
+	ret := _m.Called(viewerId, users)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []*model.User) error); ok {
+		r0 = rf(viewerId, users)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RemoveFollow provides a mock function with given fields: userId, currentId
 func (_m *UserRepository) RemoveFollow(userId string, currentId string) error {
 	ret := _m.Called(userId, currentId)
@@ -132,13 +192,13 @@ func (_m *UserRepository) RemoveFollow(userId string, currentId string) error {
 	return r0
 }
 
-// SearchProfiles provides a mock function with given fields: term
-func (_m *UserRepository) SearchProfiles(term string) (*[]model.User, error) {
-	ret := _m.Called(term)
+// SearchProfiles provides a mock function with given fields: term, viewerId, cursor
+func (_m *UserRepository) SearchProfiles(term string, viewerId string, cursor *model.ProfileCursor) (*[]model.User, error) {
+	ret := _m.Called(term, viewerId, cursor)
 
 	var r0 *[]model.User
-	if rf, ok := ret.Get(0).(func(string) *[]model.User); ok {
-		r0 = rf(term)
+	if rf, ok := ret.Get(0).(func(string, string, *model.ProfileCursor) *[]model.User); ok {
+		r0 = rf(term, viewerId, cursor)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*[]model.User)
@@ -146,8 +206,8 @@ func (_m *UserRepository) SearchProfiles(term string) (*[]model.User, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(term)
+	if rf, ok := ret.Get(1).(func(string, string, *model.ProfileCursor) error); ok {
+		r1 = rf(term, viewerId, cursor)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -155,6 +215,20 @@ func (_m *UserRepository) SearchProfiles(term string) (*[]model.User, error) {
 	return r0, r1
 }
 
+// SetPinnedPost provides a mock function with given fields: userId, postId
+func (_m *UserRepository) SetPinnedPost(userId string, postId *string) error {
+	ret := _m.Called(userId, postId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *string) error); ok {
+		r0 = rf(userId, postId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: user
 func (_m *UserRepository) Update(user *model.User) error {
 	ret := _m.Called(user)
@@ -14,46 +14,32 @@ type UserService struct {
 	mock.Mock
 }
 
-// ChangeAvatar provides a mock function with given fields: header, directory
-func (_m *UserService) ChangeAvatar(header *multipart.FileHeader, directory string) (string, error) {
-	ret := _m.Called(header, directory)
+// ChangeAvatar provides a mock function with given fields: user, header
+func (_m *UserService) ChangeAvatar(user *model.User, header *multipart.FileHeader) error {
+	ret := _m.Called(user, header)
 
-	var r0 string
-	if rf, ok := ret.Get(0).(func(*multipart.FileHeader, string) string); ok {
-		r0 = rf(header, directory)
-	} else {
-		r0 = ret.Get(0).(string)
-	}
-
-	var r1 error
-	if rf, ok := ret.Get(1).(func(*multipart.FileHeader, string) error); ok {
-		r1 = rf(header, directory)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.User, *multipart.FileHeader) error); ok {
+		r0 = rf(user, header)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// ChangeBanner provides a mock function with given fields: header, directory
-func (_m *UserService) ChangeBanner(header *multipart.FileHeader, directory string) (string, error) {
-	ret := _m.Called(header, directory)
-
-	var r0 string
-	if rf, ok := ret.Get(0).(func(*multipart.FileHeader, string) string); ok {
-		r0 = rf(header, directory)
-	} else {
-		r0 = ret.Get(0).(string)
-	}
+// ChangeBanner provides a mock function with given fields: user, header
+func (_m *UserService) ChangeBanner(user *model.User, header *multipart.FileHeader) error {
+	ret := _m.Called(user, header)
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(*multipart.FileHeader, string) error); ok {
-		r1 = rf(header, directory)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.User, *multipart.FileHeader) error); ok {
+		r0 = rf(user, header)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
 // ChangeFollow provides a mock function with given fields: user, current
@@ -70,20 +56,6 @@ func (_m *UserService) ChangeFollow(user *model.User, current string) error {
 	return r0
 }
 
-// DeleteImage provides a mock function with given fields: key
-func (_m *UserService) DeleteImage(key string) error {
-	ret := _m.Called(key)
-
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(key)
-	} else {
-		r0 = ret.Error(0)
-	}
-
-	return r0
-}
-
 // FindByUsername provides a mock function with given fields: username
 func (_m *UserService) FindByUsername(username string) (*model.User, error) {
 	ret := _m.Called(username)
@@ -130,6 +102,20 @@ func (_m *UserService) Get(uid string) (*model.User, error) {
 	return r0, r1
 }
 
+// LoadViewerState provides a mock function with given fields: viewerId, users
+func (_m *UserService) LoadViewerState(viewerId string, users []*model.User) error {
+	ret := _m.Called(viewerId, users)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []*model.User) error); ok {
+		r0 = rf(viewerId, users)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Login provides a mock function with given fields: email, password
 func (_m *UserService) Login(email string, password string) (*model.User, error) {
 	ret := _m.Called(email, password)
@@ -176,13 +162,27 @@ func (_m *UserService) Register(user *model.User) (*model.User, error) {
 	return r0, r1
 }
 
-// Search provides a mock function with given fields: term
-func (_m *UserService) Search(term string) (*[]model.User, error) {
-	ret := _m.Called(term)
+// ReleaseMedia provides a mock function with given fields: id
+func (_m *UserService) ReleaseMedia(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Search provides a mock function with given fields: term, viewerId, cursor
+func (_m *UserService) Search(term string, viewerId string, cursor *model.ProfileCursor) (*[]model.User, error) {
+	ret := _m.Called(term, viewerId, cursor)
 
 	var r0 *[]model.User
-	if rf, ok := ret.Get(0).(func(string) *[]model.User); ok {
-		r0 = rf(term)
+	if rf, ok := ret.Get(0).(func(string, string, *model.ProfileCursor) *[]model.User); ok {
+		r0 = rf(term, viewerId, cursor)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*[]model.User)
@@ -190,8 +190,8 @@ func (_m *UserService) Search(term string) (*[]model.User, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(term)
+	if rf, ok := ret.Get(1).(func(string, string, *model.ProfileCursor) error); ok {
+		r1 = rf(term, viewerId, cursor)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -14,6 +14,7 @@ const (
 	Authorization        Type = "AUTHORIZATION"          // Authentication Failures -
 	BadRequest           Type = "BAD_REQUEST"            // Validation errors / BadInput
 	Conflict             Type = "CONFLICT"               // Already exists (eg, create account with existent email) - 409
+	Forbidden            Type = "FORBIDDEN"              // Authenticated, but not allowed to do this - 403
 	Internal             Type = "INTERNAL"               // Server (500) and fallback errors
 	NotFound             Type = "NOT_FOUND"              // For not finding resource
 	PayloadTooLarge      Type = "PAYLOAD_TOO_LARGE"      // for uploading tons of JSON, or an image over the limit - 413
@@ -27,6 +28,8 @@ const (
 type Error struct {
 	Type    Type   `json:"type"`
 	Message string `json:"message"`
+	// Position is the character of the input the error refers to, starting at 1
+	Position *int `json:"position,omitempty"`
 }
 
 // Error satisfies standard error interface
@@ -47,6 +50,8 @@ func (e *Error) Status() int {
 		return http.StatusBadRequest
 	case Conflict:
 		return http.StatusConflict
+	case Forbidden:
+		return http.StatusForbidden
 	case Internal:
 		return http.StatusInternalServerError
 	case NotFound:
@@ -93,6 +98,15 @@ func NewBadRequest(reason string) *Error {
 	}
 }
 
+// NewBadRequestAt to create 400 errors for input that is invalid at the given position
+func NewBadRequestAt(reason string, position int) *Error {
+	return &Error{
+		Type:     BadRequest,
+		Message:  fmt.Sprintf("Bad request. Reason: %v at position %v", reason, position),
+		Position: &position,
+	}
+}
+
 // NewConflict to create an error for 409
 func NewConflict(name string) *Error {
 	return &Error{
@@ -101,6 +115,14 @@ func NewConflict(name string) *Error {
 	}
 }
 
+// NewForbidden to create an error for 403
+func NewForbidden(reason string) *Error {
+	return &Error{
+		Type:    Forbidden,
+		Message: reason,
+	}
+}
+
 // NewInternal for 500 errors and unknown errors
 func NewInternal() *Error {
 	return &Error{
@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// BookmarkFolderMaxName is the longest name of a folder
+const BookmarkFolderMaxName = 50
+
+// Bookmark saves a post for later. Only the user who bookmarked it can see it.
+// Bookmarks without a folder are unsorted.
+type Bookmark struct {
+	UserID    string          `gorm:"primaryKey"`
+	User      User            `gorm:"constraint:OnDelete:CASCADE;"`
+	PostID    string          `gorm:"primaryKey"`
+	Post      Post            `gorm:"constraint:OnDelete:CASCADE;"`
+	FolderID  *string         `gorm:"index"`
+	Folder    *BookmarkFolder `gorm:"constraint:OnDelete:SET NULL;"`
+	CreatedAt time.Time       `gorm:"index"`
+}
+
+// BookmarkFolder groups the bookmarks of a user under a name.
+// Deleting a folder keeps its bookmarks unsorted.
+type BookmarkFolder struct {
+	ID        string    `gorm:"primaryKey"`
+	UserID    string    `gorm:"not null;uniqueIndex:idx_bookmark_folder_name"`
+	User      User      `gorm:"constraint:OnDelete:CASCADE;"`
+	Name      string    `gorm:"not null;uniqueIndex:idx_bookmark_folder_name"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+type BookmarkFolderResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (folder *BookmarkFolder) NewBookmarkFolderResponse() BookmarkFolderResponse {
+	return BookmarkFolderResponse{
+		ID:        folder.ID,
+		Name:      folder.Name,
+		CreatedAt: folder.CreatedAt,
+	}
+}
+
+type BookmarkService interface {
+	ToggleBookmark(post *Post, uid string, folderId *string) error
+	GetBookmarks(uid string, folderId *string, page Page) (*[]Post, error)
+	GetFolders(uid string) ([]BookmarkFolder, error)
+	FindFolder(uid, id string) (*BookmarkFolder, error)
+	CreateFolder(folder *BookmarkFolder) (*BookmarkFolder, error)
+	DeleteFolder(folder *BookmarkFolder) error
+}
+
+type BookmarkRepository interface {
+	FindBookmark(userId, postId string) (*Bookmark, error)
+	AddBookmark(post *Post, uid string, folderId *string) error
+	MoveBookmark(post *Post, uid string, folderId *string) error
+	RemoveBookmark(post *Post, uid string) error
+	Bookmarks(userId string, folderId *string, page Page) (*[]Post, error)
+	FindFolders(userId string) ([]BookmarkFolder, error)
+	FindFolderByID(id string) (*BookmarkFolder, error)
+	CreateFolder(folder *BookmarkFolder) error
+	DeleteFolder(folder *BookmarkFolder) error
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"io"
+	"time"
+)
+
+const (
+	// CardFetchTimeout limits each request for a page or its image
+	CardFetchTimeout = 5 * time.Second
+	// CardMaxPageBytes is the part of a page that gets searched for metadata
+	CardMaxPageBytes = 1 << 20
+	// CardMaxImageBytes is the largest image that gets rehosted
+	CardMaxImageBytes = 5 << 20
+	// CardMaxURLLength is the longest link that gets a card
+	CardMaxURLLength = 2048
+	// CardMaxAge is the time until a card gets fetched again
+	CardMaxAge = 24 * time.Hour
+
+	CardPending = "pending"
+	CardReady   = "ready"
+	CardFailed  = "failed"
+)
+
+// Card is the preview of a linked page, cached by the URL of the page.
+// Its status is pending until the page got fetched.
+type Card struct {
+	URL              string `gorm:"primaryKey"`
+	Status           string `gorm:"not null"`
+	Title            string
+	Description      string
+	SiteName         string
+	Image            *string
+	ImagePlaceholder Placeholder `gorm:"embedded;embeddedPrefix:image_"`
+	ClaimedAt        time.Time
+	FetchedAt        *time.Time
+}
+
+type CardResponse struct {
+	URL              string      `json:"url"`
+	Title            string      `json:"title"`
+	Description      string      `json:"description"`
+	SiteName         string      `json:"siteName"`
+	Image            *string     `json:"image"`
+	ImagePlaceholder Placeholder `json:"imagePlaceholder"`
+}
+
+func (card *Card) NewCardResponse() CardResponse {
+	return CardResponse{
+		URL:              card.URL,
+		Title:            card.Title,
+		Description:      card.Description,
+		SiteName:         card.SiteName,
+		Image:            card.Image,
+		ImagePlaceholder: card.ImagePlaceholder,
+	}
+}
+
+// FetchedResource is a page or image downloaded for a card.
+// URL is where it got fetched from after following redirects.
+type FetchedResource struct {
+	URL         string
+	ContentType string
+	Body        io.ReadCloser
+}
+
+// CardFetcher downloads the resources of cards.
+// Implementations must not reach hosts on private networks.
+type CardFetcher interface {
+	Fetch(url string, maxBytes int64) (*FetchedResource, error)
+}
+
+// CardRepository caches cards by URL
+type CardRepository interface {
+	Add(url string) error
+	Claim(url string) (bool, error)
+	Save(card *Card) error
+}
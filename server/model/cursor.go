@@ -0,0 +1,135 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"time"
+)
+
+// Cursor is the position of an item in a list that is sorted newest first.
+// Items with the same time are sorted by their ID.
+// Lists sorted by relevance are sorted by Rank before that.
+type Cursor struct {
+	Rank *float64  `json:"r,omitempty"`
+	Time time.Time `json:"t"`
+	ID   string    `json:"id"`
+}
+
+// Page selects the items of a list that are older than Before or newer than After.
+// Without either it selects the newest items.
+type Page struct {
+	Before *Cursor
+	After  *Cursor
+}
+
+// Contains reports if the item at the cursor belongs to the page
+func (p Page) Contains(cursor Cursor) bool {
+	if p.Before != nil && !p.Before.NewerThan(cursor) {
+		return false
+	}
+	return p.After == nil || cursor.NewerThan(*p.After)
+}
+
+// NewerThan reports if the item at c comes before the item at other in a list sorted newest first
+func (c Cursor) NewerThan(other Cursor) bool {
+	if c.Time.Equal(other.Time) {
+		return c.ID > other.ID
+	}
+	return c.Time.After(other.Time)
+}
+
+// Encode returns the opaque form of the cursor that gets handed to clients
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor returned by Encode
+func DecodeCursor(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil {
+		return nil, apperrors.NewBadRequest("invalid cursor")
+	}
+
+	cursor := &Cursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.ID == "" || cursor.Time.IsZero() {
+		return nil, apperrors.NewBadRequest("invalid cursor")
+	}
+
+	return cursor, nil
+}
+
+// NewPage parses the before and after cursors of a request.
+// Only one of them may be set.
+func NewPage(before, after string) (Page, error) {
+	page := Page{}
+
+	if before != "" && after != "" {
+		return page, apperrors.NewBadRequest("before and after can't be combined")
+	}
+
+	if before != "" {
+		cursor, err := DecodeCursor(before)
+		if err != nil {
+			return page, err
+		}
+		page.Before = cursor
+	}
+
+	if after != "" {
+		cursor, err := DecodeCursor(after)
+		if err != nil {
+			return page, err
+		}
+		page.After = cursor
+	}
+
+	return page, nil
+}
+
+// Trim drops the extra item that gets fetched to tell if there are more items.
+// Items are sorted newest first, so that's the oldest item or,
+// when paging towards newer items, the newest one.
+func Trim[T any](items []T, page Page) ([]T, bool) {
+	if len(items) <= LIMIT {
+		return items, false
+	}
+
+	if page.After != nil {
+		return items[len(items)-LIMIT:], true
+	}
+
+	return items[:LIMIT], true
+}
+
+// ProfileCursor is the position of a user in profile search results.
+// Results are sorted by rank, then by followers and by ID last.
+type ProfileCursor struct {
+	Rank      float64 `json:"r"`
+	Followers uint    `json:"f"`
+	ID        string  `json:"id"`
+}
+
+// Encode returns the opaque form of the cursor that gets handed to clients
+func (c ProfileCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeProfileCursor parses a cursor returned by Encode
+func DecodeProfileCursor(value string) (*ProfileCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil {
+		return nil, apperrors.NewBadRequest("invalid cursor")
+	}
+
+	cursor := &ProfileCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.ID == "" {
+		return nil, apperrors.NewBadRequest("invalid cursor")
+	}
+
+	return cursor, nil
+}
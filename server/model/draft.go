@@ -0,0 +1,104 @@
+package model
+
+import "time"
+
+const (
+	// DraftSaved drafts have no schedule and only get published by their author
+	DraftSaved      = "draft"
+	DraftScheduled  = "scheduled"
+	DraftPublishing = "publishing"
+	DraftPublished  = "published"
+	DraftFailed     = "failed"
+
+	// DraftMaxSchedule is how far ahead posts can be scheduled
+	DraftMaxSchedule = 365 * 24 * time.Hour
+	// DraftPublishTimeout is the time after which a draft that is still publishing counts as failed
+	DraftPublishTimeout = 5 * time.Minute
+	// DefaultSchedulerInterval is the time between two runs of the scheduler
+	DefaultSchedulerInterval = 30 * time.Second
+)
+
+// Draft is a post that hasn't been published yet.
+// Scheduled drafts get published by the scheduler once their time has come.
+// Error holds the reason the last attempt to publish failed.
+type Draft struct {
+	ID          string     `gorm:"primaryKey"`
+	UserID      string     `gorm:"not null;index"`
+	User        User       `gorm:"constraint:OnDelete:CASCADE;"`
+	Text        string     `gorm:"not null"`
+	ScheduledAt *time.Time `gorm:"index"`
+	Status      string     `gorm:"not null;index"`
+	Error       *string
+	PostID      *string
+	CreatedAt   time.Time `gorm:"index"`
+	UpdatedAt   time.Time
+}
+
+type DraftResponse struct {
+	ID          string     `json:"id"`
+	Text        string     `json:"text"`
+	ScheduledAt *time.Time `json:"scheduledAt"`
+	Status      string     `json:"status"`
+	Error       *string    `json:"error"`
+	PostID      *string    `json:"postId"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func (draft *Draft) NewDraftResponse() DraftResponse {
+	return DraftResponse{
+		ID:          draft.ID,
+		Text:        draft.Text,
+		ScheduledAt: draft.ScheduledAt,
+		Status:      draft.Status,
+		Error:       draft.Error,
+		PostID:      draft.PostID,
+		CreatedAt:   draft.CreatedAt,
+		UpdatedAt:   draft.UpdatedAt,
+	}
+}
+
+// Cursor returns the position of the draft in the list of drafts
+func (draft *Draft) Cursor() Cursor {
+	return Cursor{Time: draft.CreatedAt, ID: draft.ID}
+}
+
+// IsEditable reports if the author can still change or delete the draft
+func (draft *Draft) IsEditable() bool {
+	return draft.Status != DraftPublishing && draft.Status != DraftPublished
+}
+
+// PublishReport counts the drafts a run of the scheduler handled
+type PublishReport struct {
+	Leader    bool `json:"leader"`
+	Expired   int  `json:"expired"`
+	Published int  `json:"published"`
+	Failed    int  `json:"failed"`
+}
+
+type DraftService interface {
+	GetDrafts(userId string, page Page) (*[]Draft, error)
+	FindDraft(userId, id string) (*Draft, error)
+	CreateDraft(draft *Draft) (*Draft, error)
+	UpdateDraft(draft *Draft, text string, scheduledAt *time.Time) (*Draft, error)
+	DeleteDraft(draft *Draft) error
+	PublishScheduled(holder string, lease time.Duration) (*PublishReport, error)
+}
+
+type DraftRepository interface {
+	FindByID(id string) (*Draft, error)
+	FindByUser(userId string, page Page) (*[]Draft, error)
+	FindDue(until time.Time, limit int) ([]Draft, error)
+	Create(draft *Draft) error
+	Update(draft *Draft) error
+	Delete(draft *Draft) error
+	Claim(id string) (bool, error)
+	MarkPublished(id, postId string) error
+	MarkFailed(id, reason string) error
+	ExpirePublishing(before time.Time) (int, error)
+}
+
+// LeaseRepository hands out named leases to a single holder at a time
+type LeaseRepository interface {
+	Acquire(name, holder string, ttl time.Duration) (bool, error)
+}
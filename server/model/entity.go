@@ -0,0 +1,86 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	EntityHashtag = "hashtag"
+	EntityMention = "mention"
+	EntityURL     = "url"
+)
+
+// Entity is a hashtag, mention or URL within the text of a post.
+// Start and End are byte offsets into the UTF-8 text,
+// UTF16Start and UTF16End are the same range counted in UTF-16 code units for clients that index strings that way.
+type Entity struct {
+	Type       string `json:"type"`
+	Text       string `json:"text"`
+	Normalized string `json:"normalized"`
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+	UTF16Start int    `json:"utf16Start"`
+	UTF16End   int    `json:"utf16End"`
+}
+
+// Entities are stored as JSON next to the text they got extracted from
+type Entities []Entity
+
+// Value implements driver.Valuer
+func (e Entities) Value() (driver.Value, error) {
+	if e == nil {
+		return "[]", nil
+	}
+	value, err := json.Marshal(e)
+	return string(value), err
+}
+
+// Scan implements sql.Scanner
+func (e *Entities) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*e = Entities{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, e)
+	case string:
+		return json.Unmarshal([]byte(v), e)
+	default:
+		return errors.New("unsupported type for entities")
+	}
+}
+
+// Hashtags returns the distinct normalized hashtags in the order they appear
+func (e Entities) Hashtags() []string {
+	return e.distinct(EntityHashtag)
+}
+
+// Mentions returns the distinct normalized usernames in the order they appear
+func (e Entities) Mentions() []string {
+	return e.distinct(EntityMention)
+}
+
+// FirstURL returns the normalized first URL or an empty string
+func (e Entities) FirstURL() string {
+	for _, entity := range e {
+		if entity.Type == EntityURL {
+			return entity.Normalized
+		}
+	}
+	return ""
+}
+
+func (e Entities) distinct(entityType string) []string {
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, entity := range e {
+		if entity.Type != entityType || seen[entity.Normalized] {
+			continue
+		}
+		seen[entity.Normalized] = true
+		result = append(result, entity.Normalized)
+	}
+	return result
+}
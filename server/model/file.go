@@ -5,18 +5,66 @@ import (
 	"time"
 )
 
+// Sizes and formats that get generated for uploaded post images.
+// Animated gifs only get an original gif and a poster for the first frame.
+const (
+	ThumbSize    = "thumb"
+	MediumSize   = "medium"
+	OriginalSize = "original"
+	PosterSize   = "poster"
+
+	JPEG = "jpeg"
+	WEBP = "webp"
+	GIF  = "gif"
+)
+
 type File struct {
-	ID        string    `gorm:"primaryKey" json:"-"`
-	PostId    string    `gorm:"not null;constraint:OnDelete:CASCADE;" json:"-"`
-	Url       string    `json:"url"`
-	FileType  string    `json:"filetype"`
-	Filename  string    `json:"filename"`
-	CreatedAt time.Time `json:"-"`
+	ID          string        `gorm:"primaryKey" json:"-"`
+	PostId      string        `gorm:"not null;constraint:OnDelete:CASCADE;" json:"-"`
+	Url         string        `json:"url"`
+	FileType    string        `json:"filetype"`
+	Filename    string        `json:"filename"`
+	AltText     *string       `json:"altText"`
+	MediaID     *string       `gorm:"index" json:"-"`
+	Variants    []FileVariant `gorm:"constraint:OnDelete:CASCADE;" json:"variants"`
+	Placeholder `gorm:"embedded"`
+	CreatedAt   time.Time `json:"-"`
+}
+
+// Placeholder can be shown by clients while the actual image is loading.
+// DominantColor is a hex color like #1da1f2.
+type Placeholder struct {
+	BlurHash      string `json:"blurHash"`
+	DominantColor string `json:"dominantColor"`
+}
+
+// FileVariant is a resized and re-encoded version of an uploaded image
+type FileVariant struct {
+	FileID string `gorm:"primaryKey" json:"-"`
+	Size   string `gorm:"primaryKey" json:"size"`
+	Format string `gorm:"primaryKey" json:"format"`
+	Key    string `gorm:"not null" json:"-"`
+	Url    string `gorm:"not null" json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Variant returns the variant for the given size and format if it exists
+func (file *File) Variant(size, format string) *FileVariant {
+	for i, v := range file.Variants {
+		if v.Size == size && v.Format == format {
+			return &file.Variants[i]
+		}
+	}
+	return nil
 }
 
 type FileRepository interface {
-	UploadAvatar(header *multipart.FileHeader, directory string) (string, error)
-	UploadBanner(header *multipart.FileHeader, directory string) (string, error)
+	UploadAvatar(header *multipart.FileHeader, directory, slug string) (FileVariant, Placeholder, error)
+	UploadBanner(header *multipart.FileHeader, directory, slug string) (FileVariant, Placeholder, error)
 	UploadFile(header *multipart.FileHeader, directory, filename, mimetype string) (string, error)
+	UploadImage(header *multipart.FileHeader, directory, slug string) ([]FileVariant, Placeholder, error)
+	UploadCardImage(data []byte, directory, slug string) (FileVariant, Placeholder, error)
 	DeleteImage(key string) error
+	ListObjects(prefix string) ([]StoredObject, error)
 }
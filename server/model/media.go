@@ -0,0 +1,58 @@
+package model
+
+import (
+	"github.com/lib/pq"
+	"time"
+)
+
+// Media is an upload stored in the bucket under the hash of its content,
+// so the same image uploaded several times only gets stored once.
+// Refs counts the files and users that use it. Its objects only get
+// deleted from the bucket once the last reference goes away.
+type Media struct {
+	ID          string         `gorm:"primaryKey"` // directory/hash
+	Hash        string         `gorm:"not null;index"`
+	Refs        int            `gorm:"not null"`
+	Url         string         `gorm:"not null"`
+	Keys        pq.StringArray `gorm:"type:text[]"`
+	Placeholder `gorm:"embedded"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// StoredObject is an object in the bucket
+type StoredObject struct {
+	Key          string
+	LastModified time.Time
+}
+
+// GarbageReport lists the objects in the bucket that no row references anymore.
+// Objects younger than the grace period are skipped, as their rows
+// might not have been created yet. Reconciled and Unused count the media
+// whose references got corrected and the media that got deleted as nothing uses it.
+type GarbageReport struct {
+	Leader     bool     `json:"leader"`
+	DryRun     bool     `json:"dryRun"`
+	Reconciled int      `json:"reconciled"`
+	Unused     int      `json:"unused"`
+	Scanned    int      `json:"scanned"`
+	Referenced int      `json:"referenced"`
+	Recent     int      `json:"recent"`
+	Orphaned   []string `json:"orphaned"`
+	Deleted    int      `json:"deleted"`
+	Failed     int      `json:"failed"`
+}
+
+type MediaService interface {
+	CollectGarbage(holder string, lease, grace time.Duration, dryRun bool) (*GarbageReport, error)
+}
+
+type MediaRepository interface {
+	Acquire(id string) (*Media, error)
+	Create(media *Media) error
+	Release(id string, deleteObjects func(keys []string) error) error
+	AddKeys(id string, keys []string) error
+	Reconcile(before time.Time) (int, int, error)
+	FindVariants(id string) ([]FileVariant, error)
+	FindReferences() ([]string, error)
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"github.com/lib/pq"
+	"time"
+)
+
+const (
+	PollMinOptions      = 2
+	PollMaxOptions      = 4
+	PollMaxOptionLength = 25
+	// PollMinDuration and PollMaxDuration limit how long polls stay open
+	PollMinDuration = 5 * time.Minute
+	PollMaxDuration = 7 * 24 * time.Hour
+)
+
+// Poll belongs to a post and is open for votes until it ends
+type Poll struct {
+	PostID         string       `gorm:"primaryKey"`
+	MultipleChoice bool         `gorm:"not null"`
+	EndsAt         time.Time    `gorm:"not null"`
+	Options        []PollOption `gorm:"foreignKey:PostID;references:PostID;constraint:OnDelete:CASCADE;"`
+	Votes          []PollVote   `gorm:"foreignKey:PostID;references:PostID;constraint:OnDelete:CASCADE;"`
+
+	// VoterCount gets updated together with the votes, so the tallies don't have to be summed up
+	VoterCount uint `gorm:"not null;default:0"`
+
+	// Choices and ShowResults describe the viewer of the poll and are only set by LoadViewerState.
+	// Choices is nil if the viewer hasn't voted.
+	Choices     []int `gorm:"-"`
+	ShowResults bool  `gorm:"-"`
+}
+
+// PollOption is identified by its position in the poll, starting at 0
+type PollOption struct {
+	PostID    string `gorm:"primaryKey"`
+	Position  int    `gorm:"primaryKey;autoIncrement:false"`
+	Text      string `gorm:"not null"`
+	VoteCount uint   `gorm:"not null;default:0"`
+}
+
+// PollVote holds the positions of the options a user voted for.
+// Each user votes once and can't change the vote.
+type PollVote struct {
+	PostID    string        `gorm:"primaryKey"`
+	UserID    string        `gorm:"primaryKey"`
+	Choices   pq.Int64Array `gorm:"type:integer[];not null"`
+	CreatedAt time.Time
+}
+
+// IsClosed returns true once the poll doesn't take votes anymore
+func (poll *Poll) IsClosed() bool {
+	return !time.Now().Before(poll.EndsAt)
+}
+
+type PollResponse struct {
+	Options        []PollOptionResponse `json:"options"`
+	MultipleChoice bool                 `json:"multipleChoice"`
+	EndsAt         time.Time            `json:"endsAt"`
+	Closed         bool                 `json:"closed"`
+	Voters         uint                 `json:"voters"`
+	Voted          bool                 `json:"voted"`
+	Choices        []int                `json:"choices"`
+}
+
+// PollOptionResponse only contains the votes once the viewer may see the results
+type PollOptionResponse struct {
+	Text  string `json:"text"`
+	Votes *uint  `json:"votes"`
+}
+
+// NewPollResponse hides the tallies until the viewer voted or the poll closed
+func (poll *Poll) NewPollResponse() PollResponse {
+	closed := poll.IsClosed()
+	showResults := closed || poll.ShowResults || poll.Choices != nil
+
+	options := make([]PollOptionResponse, 0, len(poll.Options))
+	for _, option := range poll.Options {
+		response := PollOptionResponse{Text: option.Text}
+		if showResults {
+			votes := option.VoteCount
+			response.Votes = &votes
+		}
+		options = append(options, response)
+	}
+
+	choices := poll.Choices
+	if choices == nil {
+		choices = make([]int, 0)
+	}
+
+	return PollResponse{
+		Options:        options,
+		MultipleChoice: poll.MultipleChoice,
+		EndsAt:         poll.EndsAt,
+		Closed:         closed,
+		Voters:         poll.VoterCount,
+		Voted:          poll.Choices != nil,
+		Choices:        choices,
+	}
+}
@@ -6,72 +6,106 @@ import (
 	"time"
 )
 
+const (
+	// ReplyEveryone lets every user reply to the post
+	ReplyEveryone = "everyone"
+	// ReplyFollowing only lets the users the author follows reply
+	ReplyFollowing = "following"
+	// ReplyMentioned only lets the users mentioned in the post reply
+	ReplyMentioned = "mentioned"
+)
+
 type PostResponse struct {
-	ID        string    `json:"id"`
-	Text      *string   `json:"text"`
-	Likes     uint      `json:"likes"`
-	Liked     bool      `json:"liked"`
-	Retweets  uint      `json:"retweets"`
-	Retweeted bool      `json:"retweeted"`
-	IsRetweet bool      `json:"isRetweet"`
-	File      *File     `json:"file"`
-	Author    Profile   `json:"author"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID          string        `json:"id"`
+	Text        *string       `json:"text"`
+	Entities    Entities      `json:"entities"`
+	Mentions    []Profile     `json:"mentions"`
+	Card        *CardResponse `json:"card"`
+	Poll        *PollResponse `json:"poll"`
+	Likes       uint          `json:"likes"`
+	Liked       bool          `json:"liked"`
+	Retweets    uint          `json:"retweets"`
+	Retweeted   bool          `json:"retweeted"`
+	Replies     uint          `json:"replies"`
+	Bookmarked  bool          `json:"bookmarked"`
+	Pinned      bool          `json:"pinned"`
+	ReplyTo     *string       `json:"replyTo"`
+	ReplyPolicy string        `json:"replyPolicy"`
+	CanReply    bool          `json:"canReply"`
+	Hidden      bool          `json:"hidden"`
+	IsRetweet   bool          `json:"isRetweet"`
+	RetweetedBy *Profile      `json:"retweetedBy"`
+	RetweetedAt *time.Time    `json:"retweetedAt"`
+	Ranking     *Ranking      `json:"ranking,omitempty"`
+	File        *File         `json:"file"`
+	Author      Profile       `json:"author"`
+	CreatedAt   time.Time     `json:"createdAt"`
+	EditedAt    *time.Time    `json:"editedAt"`
 }
 
-func (post *Post) NewPostResponse(id string) PostResponse {
-	return PostResponse{
-		ID:        post.ID,
-		Text:      post.Text,
-		Likes:     uint(len(post.Likes)),
-		Liked:     post.IsLiked(id),
-		Retweets:  uint(len(post.Retweets)),
-		Retweeted: post.IsRetweeted(id),
-		File:      post.File,
-		Author:    post.User.NewProfileResponse(id),
-		CreatedAt: post.CreatedAt,
+func (post *Post) NewPostResponse() PostResponse {
+	response := PostResponse{
+		ID:          post.ID,
+		Text:        post.Text,
+		Entities:    post.Entities,
+		Likes:       post.LikeCount,
+		Liked:       post.Liked,
+		Retweets:    post.RetweetCount,
+		Retweeted:   post.Retweeted,
+		Replies:     post.ReplyCount,
+		Bookmarked:  post.Bookmarked,
+		Pinned:      post.Pinned,
+		ReplyTo:     post.ReplyToID,
+		ReplyPolicy: post.ReplyPolicy,
+		CanReply:    post.CanReply,
+		Hidden:      post.Hidden,
+		File:        post.File,
+		Author:      post.User.NewProfileResponse(),
+		CreatedAt:   post.CreatedAt,
+		EditedAt:    post.EditedAt,
+		Ranking:     post.Ranking,
 	}
-}
 
-func (post *Post) NewFeedResponse(id string) PostResponse {
-	return PostResponse{
-		ID:        post.ID,
-		Text:      post.Text,
-		Likes:     uint(len(post.Likes)),
-		Liked:     post.IsLiked(id),
-		Retweets:  uint(len(post.Retweets)),
-		Retweeted: post.IsRetweeted(id),
-		IsRetweet: post.UserID != id && !post.User.IsFollowing(id),
-		File:      post.File,
-		Author:    post.User.NewProfileResponse(id),
-		CreatedAt: post.CreatedAt,
+	if response.Entities == nil {
+		response.Entities = Entities{}
 	}
-}
 
-func (post *Post) IsLiked(id string) bool {
-	if id == "" {
-		return false
+	response.Mentions = make([]Profile, 0, len(post.Mentions))
+	for _, user := range post.Mentions {
+		response.Mentions = append(response.Mentions, user.NewProfileResponse())
 	}
 
-	for _, v := range post.Likes {
-		if v.ID == id {
-			return true
-		}
+	if post.Poll != nil {
+		poll := post.Poll.NewPollResponse()
+		response.Poll = &poll
 	}
-	return false
-}
 
-func (post *Post) IsRetweeted(id string) bool {
-	if id == "" {
-		return false
+	// Cards only show up once their page got fetched
+	if post.Card != nil && post.Card.Status == CardReady {
+		card := post.Card.NewCardResponse()
+		response.Card = &card
 	}
 
-	for _, v := range post.Retweets {
-		if v.ID == id {
-			return true
-		}
+	if post.RetweetedBy != nil {
+		retweetedBy := post.RetweetedBy.NewProfileResponse()
+		response.IsRetweet = true
+		response.RetweetedBy = &retweetedBy
+		response.RetweetedAt = post.RetweetedAt
+	}
+
+	return response
+}
+
+// Cursor returns the position of the post in its list.
+// Retweets are sorted by the time they got retweeted, bookmarks by the time they got bookmarked.
+func (post *Post) Cursor() Cursor {
+	if post.RetweetedAt != nil {
+		return Cursor{Time: *post.RetweetedAt, ID: post.ID}
 	}
-	return false
+	if post.BookmarkedAt != nil {
+		return Cursor{Time: *post.BookmarkedAt, ID: post.ID}
+	}
+	return Cursor{Rank: post.SearchRank, Time: post.CreatedAt, ID: post.ID}
 }
 
 type Post struct {
@@ -79,38 +113,96 @@ type Post struct {
 	Text      *string
 	File      *File          `gorm:"constraint:OnDelete:CASCADE;"`
 	HashTags  pq.StringArray `gorm:"type:text[]"`
-	UserID    string         `gorm:"not null;constraint:OnDelete:CASCADE;"`
+	Entities  Entities       `gorm:"type:jsonb"`
+	UserID    string         `gorm:"not null;index:idx_posts_user_created,priority:1;constraint:OnDelete:CASCADE;"`
 	User      User           `gorm:"not null;constraint:OnDelete:CASCADE;"`
 	Likes     []User         `gorm:"many2many:post_likes;constraint:OnDelete:CASCADE;"`
 	Retweets  []User         `gorm:"many2many:retweets;constraint:OnDelete:CASCADE;"`
-	CreatedAt time.Time      `gorm:"index"`
+	Mentions  []User         `gorm:"many2many:post_mentions;constraint:OnDelete:CASCADE;"`
+	CardURL   *string
+	Card      *Card     `gorm:"foreignKey:CardURL;constraint:OnDelete:SET NULL;"`
+	CreatedAt time.Time `gorm:"index;index:idx_posts_user_created,priority:2"`
+	EditedAt  *time.Time
+	Versions  []PostVersion `gorm:"constraint:OnDelete:CASCADE;"`
+	Poll      *Poll         `gorm:"constraint:OnDelete:CASCADE;"`
+
+	// ReplyToID is the post this one replies to. Replies to deleted posts stay on their own.
+	ReplyToID *string `gorm:"index"`
+	ReplyTo   *Post   `gorm:"foreignKey:ReplyToID;constraint:OnDelete:SET NULL;"`
+	// ReplyPolicy decides who besides the author can reply to the post
+	ReplyPolicy string `gorm:"not null;default:everyone"`
+	// Hidden is set for replies the author of the replied to post folded away
+	Hidden bool `gorm:"not null;default:false"`
+
+	// Counters get updated together with the join tables and replies, so the users don't have to be loaded
+	LikeCount    uint `gorm:"not null;default:0"`
+	RetweetCount uint `gorm:"not null;default:0"`
+	ReplyCount   uint `gorm:"not null;default:0"`
+
+	// Liked, Retweeted, Bookmarked and CanReply describe the viewer of the post and are only set by LoadViewerState.
+	// It sets the viewer's choices of the poll as well.
+	Liked      bool `gorm:"-"`
+	Retweeted  bool `gorm:"-"`
+	Bookmarked bool `gorm:"-"`
+	CanReply   bool `gorm:"-"`
+
+	// RetweetedBy and RetweetedAt are set for timeline entries that are retweets
+	RetweetedBy *User      `gorm:"-"`
+	RetweetedAt *time.Time `gorm:"-"`
+
+	// Ranking is set for posts of the For You timeline
+	Ranking *Ranking `gorm:"-"`
+
+	// Pinned is set for the post pinned to the profile it gets listed on
+	Pinned bool `gorm:"-"`
+
+	// BookmarkedAt is set for the posts in the bookmarks of the viewer
+	BookmarkedAt *time.Time `gorm:"->;-:migration"`
+
+	// SearchRank is the relevance of the post for searches sorted by relevance
+	SearchRank *float64 `gorm:"->;-:migration"`
 }
 
 type PostService interface {
 	FindPostByID(id string) (*Post, error)
 	CreatePost(post *Post) (*Post, error)
+	EditPost(post *Post, text, altText *string) (*Post, error)
+	GetPostHistory(post *Post) ([]PostVersion, error)
 	DeletePost(post *Post) error
 	UploadFile(header *multipart.FileHeader) (*File, error)
 	ToggleLike(post *Post, uid string) error
 	ToggleRetweet(post *Post, uid string) error
-	GetUserFeed(userId, cursor string) (*[]Post, error)
-	ProfilePosts(id, cursor string) (*[]Post, error)
-	ProfileLikes(id, cursor string) (*[]Post, error)
-	ProfileMedia(id, cursor string) (*[]Post, error)
-	SearchPosts(tag, cursor string) (*[]Post, error)
+	TogglePin(post *Post, uid string) error
+	ToggleHidden(reply *Post, uid string) error
+	GetReplies(post *Post, hidden bool, page Page) (*[]Post, error)
+	Vote(post *Post, uid string, choices []int) error
+	LoadViewerState(viewerId string, posts []*Post) error
+	GetUserFeed(userId string, page Page) (*[]Post, error)
+	NewFeedPostIDs(userId string, since Cursor) ([]string, bool, error)
+	ProfilePosts(id string, page Page) (*[]Post, error)
+	ProfileLikes(id string, page Page) (*[]Post, error)
+	ProfileMedia(id string, page Page) (*[]Post, error)
+	GetMentions(userId string, page Page) (*[]Post, error)
+	SearchPosts(query string, sort string, page Page) (*[]Post, error)
 }
 
 type PostRepository interface {
 	FindByID(id string) (*Post, error)
+	FindByIDs(ids []string) (*[]Post, error)
 	Create(post *Post) (*Post, error)
+	Update(post *Post) error
+	FindVersions(postId string) ([]PostVersion, error)
 	Delete(post *Post) error
 	AddLike(post *Post, uid string) error
 	RemoveLike(post *Post, uid string) error
 	AddRetweet(post *Post, uid string) error
 	RemoveRetweet(post *Post, uid string) error
-	Feed(userId, cursor string) (*[]Post, error)
-	List(id, cursor string) (*[]Post, error)
-	Likes(id, cursor string) (*[]Post, error)
-	GetPostsForHashtag(tag, cursor string) (*[]Post, error)
-	Media(id, cursor string) (*[]Post, error)
+	AddVote(postId, userId string, choices []int) error
+	SetHidden(reply *Post, hidden bool) error
+	Replies(postId string, hidden bool, page Page) (*[]Post, error)
+	LoadViewerState(viewerId string, posts []*Post) error
+	Likes(id string, page Page) (*[]Post, error)
+	Search(query PostQuery, page Page) (*[]Post, error)
+	Media(id string, page Page) (*[]Post, error)
+	Mentions(userId string, page Page) (*[]Post, error)
 }
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// DefaultEditWindow is the time authors have to edit a post unless POST_EDIT_WINDOW is set
+const DefaultEditWindow = time.Hour
+
+// PostVersion is the content of a post before it got edited.
+// PublishedAt is the time the version got posted, ReplacedAt the time it got edited.
+type PostVersion struct {
+	ID          uint   `gorm:"primaryKey"`
+	PostID      string `gorm:"not null;index"`
+	Text        *string
+	AltText     *string
+	PublishedAt time.Time
+	ReplacedAt  *time.Time
+}
+
+type PostVersionResponse struct {
+	Text        *string    `json:"text"`
+	AltText     *string    `json:"altText"`
+	PublishedAt time.Time  `json:"publishedAt"`
+	ReplacedAt  *time.Time `json:"replacedAt"`
+}
+
+func (version *PostVersion) NewPostVersionResponse() PostVersionResponse {
+	return PostVersionResponse{
+		Text:        version.Text,
+		AltText:     version.AltText,
+		PublishedAt: version.PublishedAt,
+		ReplacedAt:  version.ReplacedAt,
+	}
+}
+
+// CurrentVersion returns the content of the post as it is now
+func (post *Post) CurrentVersion() PostVersion {
+	version := PostVersion{
+		PostID:      post.ID,
+		Text:        post.Text,
+		PublishedAt: post.CreatedAt,
+	}
+
+	if post.EditedAt != nil {
+		version.PublishedAt = *post.EditedAt
+	}
+
+	if post.File != nil {
+		version.AltText = post.File.AltText
+	}
+
+	return version
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"time"
+)
+
+const (
+	// ForYouSize is the number of posts that get ranked for one For You session
+	ForYouSize = 200
+	// ForYouSessionTTL is how long the ranking of a session can be paged through
+	ForYouSessionTTL = 30 * time.Minute
+	// PopularHashtags is the number of popular hashtags whose posts become candidates
+	PopularHashtags = 10
+)
+
+// Sources of For You candidates
+const (
+	SourceFollowing = "following"
+	SourceNetwork   = "network"
+	SourceHashtag   = "hashtag"
+)
+
+// RankingConfig holds the weights of the For You timeline
+type RankingConfig struct {
+	RecencyWeight  float64
+	VelocityWeight float64
+	AffinityWeight float64
+	// The boosts get added to the score depending on the source of the candidate
+	FollowingBoost float64
+	NetworkBoost   float64
+	HashtagBoost   float64
+	// HalfLife is the age at which the recency of a post halves
+	HalfLife time.Duration
+	// Window is the age up to which posts become candidates
+	Window time.Duration
+	// MaxPerAuthor is the number of posts of an author on one page, 0 means no limit
+	MaxPerAuthor int
+}
+
+// DefaultRankingConfig returns the weights used for settings that aren't configured
+func DefaultRankingConfig() RankingConfig {
+	return RankingConfig{
+		RecencyWeight:  1,
+		VelocityWeight: 1,
+		AffinityWeight: 0.5,
+		FollowingBoost: 1,
+		NetworkBoost:   0.5,
+		HashtagBoost:   0.25,
+		HalfLife:       6 * time.Hour,
+		Window:         72 * time.Hour,
+		MaxPerAuthor:   2,
+	}
+}
+
+// RankingCandidate is a post that may show up in the For You timeline
+type RankingCandidate struct {
+	PostID       string
+	AuthorID     string
+	Source       string
+	LikeCount    uint
+	RetweetCount uint
+	CreatedAt    time.Time
+}
+
+// Ranking explains why a post ranked where it did.
+// Demoted is the number of positions the post lost to the per author limit.
+type Ranking struct {
+	PostID   string  `json:"postId"`
+	AuthorID string  `json:"authorId"`
+	Rank     int     `json:"rank"`
+	Score    float64 `json:"score"`
+	Source   string  `json:"source"`
+	Recency  float64 `json:"recency"`
+	Velocity float64 `json:"velocity"`
+	Affinity float64 `json:"affinity"`
+	Boost    float64 `json:"boost"`
+	Demoted  int     `json:"demoted"`
+}
+
+// RankingCursor points into the ranked posts of a For You session
+type RankingCursor struct {
+	Session string `json:"s"`
+	Offset  int    `json:"o"`
+}
+
+// Encode returns the opaque form of the cursor that gets handed to clients
+func (c RankingCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeRankingCursor parses a cursor returned by Encode
+func DecodeRankingCursor(value string) (*RankingCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil {
+		return nil, apperrors.NewBadRequest("invalid cursor")
+	}
+
+	cursor := &RankingCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.Session == "" || cursor.Offset < 0 {
+		return nil, apperrors.NewBadRequest("invalid cursor")
+	}
+
+	return cursor, nil
+}
+
+// RankingService ranks the For You timeline
+type RankingService interface {
+	ForYou(userId string, cursor *RankingCursor) (*[]Post, *RankingCursor, error)
+}
+
+// RankingRepository finds the candidates of the For You timeline
+// and keeps the rankings of sessions
+type RankingRepository interface {
+	FindFollowingCandidates(userId string, since time.Time, limit int) ([]RankingCandidate, error)
+	FindNetworkCandidates(userId string, since time.Time, limit int) ([]RankingCandidate, error)
+	FindHashtagCandidates(userId string, since time.Time, limit int) ([]RankingCandidate, error)
+	FindAffinities(userId string, authorIds []string) (map[string]int, error)
+	SaveSession(userId, sessionId string, rankings []Ranking) error
+	FindSession(userId, sessionId string) ([]Ranking, error)
+}
@@ -2,8 +2,9 @@ package model
 
 import "time"
 
+// Retweet gets indexed by user and by post with its time, so timelines can read the newest retweets first
 type Retweet struct {
-	UserID    string    `gorm:"primaryKey;constraint:OnDelete:CASCADE;"`
-	PostId    string    `gorm:"primaryKey;constraint:OnDelete:CASCADE;"`
-	CreatedAt time.Time `gorm:"index;default:now()"`
+	UserID    string    `gorm:"primaryKey;index:idx_retweets_user_created,priority:1;constraint:OnDelete:CASCADE;"`
+	PostId    string    `gorm:"primaryKey;index:idx_retweets_post_created,priority:1;constraint:OnDelete:CASCADE;"`
+	CreatedAt time.Time `gorm:"index;index:idx_retweets_user_created,priority:2;index:idx_retweets_post_created,priority:2;default:now()"`
 }
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"github.com/sentrionic/mirage/model/apperrors"
+	"time"
+)
+
+// Orders of search results
+const (
+	SortRecent    = "recent"
+	SortRelevance = "relevance"
+)
+
+// ParseSearchSort validates the sort parameter of a search.
+// Results are sorted by recency by default.
+func ParseSearchSort(value string) (string, error) {
+	switch value {
+	case "", SortRecent:
+		return SortRecent, nil
+	case SortRelevance:
+		return SortRelevance, nil
+	default:
+		return "", apperrors.NewBadRequest("sort must be recent or relevance")
+	}
+}
+
+// PostQuery is a post search parsed into its text and filters
+type PostQuery struct {
+	// Text is passed on to the full-text search and may be empty
+	Text string
+	// From is the username of the author, or of the retweeting user with IsRetweet
+	From string
+	// To is the username of a user mentioned by the post
+	To       string
+	Since    *time.Time
+	Until    *time.Time
+	HasMedia bool
+	MinLikes uint
+	// IsRetweet only matches posts retweeted by the user in From, which it needs
+	IsRetweet bool
+	// ExcludeReplies drops posts that reply to another post
+	ExcludeReplies bool
+	Sort           string
+}
+
+// IsEmpty reports if the query neither has text nor filters
+func (q PostQuery) IsEmpty() bool {
+	return q.Text == "" && q.From == "" && q.To == "" && q.Since == nil && q.Until == nil &&
+		!q.HasMedia && q.MinLikes == 0 && !q.IsRetweet && !q.ExcludeReplies
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+const (
+	// TimelineSize is the number of entries kept in a cached home timeline
+	TimelineSize = 800
+	// TimelineTTL is how long a cached home timeline lives without being read
+	TimelineTTL = 7 * 24 * time.Hour
+	// CelebrityFollowers is the number of followers at which a user's posts
+	// stop being written to their followers' timelines and get merged in on read instead
+	CelebrityFollowers = 10000
+	// NewPostsLimit is the number of new timeline items that get counted when polling the feed
+	NewPostsLimit = 100
+)
+
+// TimelineEntry is a post in a home timeline.
+// RetweetedBy is the ID of the retweeting user and empty for original posts.
+// CreatedAt is the time the post or its retweet got created, in milliseconds.
+type TimelineEntry struct {
+	PostID      string
+	RetweetedBy string
+	CreatedAt   time.Time
+}
+
+// Cursor returns the position of the entry in the timeline
+func (entry TimelineEntry) Cursor() Cursor {
+	return Cursor{Time: entry.CreatedAt, ID: entry.PostID}
+}
+
+// TimelineRepository keeps the precomputed home timelines of users
+// and finds the entries to fill them with
+type TimelineRepository interface {
+	Add(userIds []string, entries ...TimelineEntry) error
+	Remove(userIds []string, postIds ...string) error
+	RemoveRetweet(userIds []string, postId, retweeterId string) error
+	RemoveAuthor(userId, authorId string, remaining []string) error
+	Replace(userId string, entries []TimelineEntry, complete bool) error
+	Range(userId string, page Page, limit int) ([]TimelineEntry, bool, error)
+	IsComplete(userId string) (bool, error)
+	Members(userId string) ([]string, error)
+	FindEntries(authorIds []string, page Page, limit int, exclude []string) ([]TimelineEntry, error)
+	FindFollowerIDs(userId string) (ids []string, celebrity bool, err error)
+	FindFolloweeIDs(userId string) (regular []string, celebrities []string, err error)
+}
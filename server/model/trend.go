@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+const (
+	// TrendBucketSize is the time span hashtag uses get counted in
+	TrendBucketSize = time.Hour
+	// TrendWindowBuckets is the number of recent buckets a trend is made of
+	TrendWindowBuckets = 3
+	// TrendBaselineBuckets is the number of buckets before the window that make up the usual use of a hashtag
+	TrendBaselineBuckets = 24
+	// TrendDecay weighs each bucket of the window against the next newer one
+	TrendDecay = 0.5
+	// TrendMinAuthors is the number of authors that have to use a hashtag in the window for it to trend
+	TrendMinAuthors = 3
+	// TrendLimit is the number of trends returned
+	TrendLimit = 10
+)
+
+// HashtagBucket holds the uses of hashtags within one bucket.
+// Authors only counts the first use by each author within the trend window.
+type HashtagBucket struct {
+	Authors map[string]uint
+	Posts   map[string]uint
+}
+
+// Trend is a hashtag that is used more than usual
+type Trend struct {
+	Hashtag string  `json:"hashtag"`
+	Posts   uint    `json:"posts"`
+	Authors uint    `json:"-"`
+	Score   float64 `json:"-"`
+}
+
+// TrendService finds trending hashtags
+type TrendService interface {
+	GetTrends() ([]Trend, error)
+}
+
+// TrendRepository counts the uses of hashtags over time
+type TrendRepository interface {
+	AddHashtags(authorId string, tags []string, at time.Time) error
+	FindBuckets(until time.Time, count int) ([]HashtagBucket, error)
+}
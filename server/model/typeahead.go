@@ -0,0 +1,73 @@
+package model
+
+const (
+	// TypeaheadLimit is the number of suggestions returned for a prefix
+	TypeaheadLimit = 10
+	// TypeaheadCandidates is the number of users looked at to personalise suggestions
+	TypeaheadCandidates = 50
+	// TypeaheadPrefixLength is the length of the longest prefix that gets indexed.
+	// Longer prefixes get looked up by their first TypeaheadPrefixLength characters.
+	TypeaheadPrefixLength = 20
+)
+
+// Types of typeahead suggestions
+const (
+	SuggestionUser    = "user"
+	SuggestionHashtag = "hashtag"
+)
+
+// HashtagCount is a hashtag and the number of posts that used it
+type HashtagCount struct {
+	Tag   string
+	Posts uint
+}
+
+// TypeaheadSuggestion is a user or a hashtag suggested for what the user typed so far
+type TypeaheadSuggestion struct {
+	Type    string
+	User    *User
+	Hashtag *HashtagCount
+}
+
+// TypeaheadResponse is the JSON form of a suggestion.
+// Only the field matching the type is set.
+type TypeaheadResponse struct {
+	Type    string   `json:"type"`
+	Profile *Profile `json:"profile,omitempty"`
+	Hashtag *string  `json:"hashtag,omitempty"`
+	Posts   *uint    `json:"posts,omitempty"`
+}
+
+// NewTypeaheadResponse converts the suggestion into its JSON form
+func (s TypeaheadSuggestion) NewTypeaheadResponse() TypeaheadResponse {
+	response := TypeaheadResponse{Type: s.Type}
+
+	if s.User != nil {
+		profile := s.User.NewProfileResponse()
+		response.Profile = &profile
+	}
+
+	if s.Hashtag != nil {
+		response.Hashtag = &s.Hashtag.Tag
+		response.Posts = &s.Hashtag.Posts
+	}
+
+	return response
+}
+
+// SearchService suggests users and hashtags while typing
+type SearchService interface {
+	Typeahead(viewerId, query string) ([]TypeaheadSuggestion, error)
+}
+
+// TypeaheadRepository indexes usernames and hashtags by their prefixes
+type TypeaheadRepository interface {
+	AddUser(user *User) error
+	RenameUser(user *User, previous string) error
+	AddFollowers(user *User, delta int) error
+	AddHashtags(tags []string) error
+	RemoveHashtags(tags []string) error
+	FindUserIDs(prefix string, limit int) ([]string, error)
+	FindHashtags(prefix string, limit int) ([]HashtagCount, error)
+	Backfill() error
+}
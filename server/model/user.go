@@ -30,60 +30,75 @@ func (user *User) NewAccountResponse() AccountResponse {
 }
 
 type Profile struct {
-	ID          string    `json:"id"`
-	Username    string    `json:"username"`
-	DisplayName string    `json:"displayName"`
-	Image       string    `json:"image"`
-	Banner      *string   `json:"banner"`
-	Bio         *string   `json:"bio"`
-	Followers   uint      `json:"followers"`
-	Followee    uint      `json:"followee"`
-	Following   bool      `json:"following"`
-	CreatedAt   time.Time `json:"createdAt"`
+	ID                string      `json:"id"`
+	Username          string      `json:"username"`
+	DisplayName       string      `json:"displayName"`
+	Image             string      `json:"image"`
+	ImagePlaceholder  Placeholder `json:"imagePlaceholder"`
+	Banner            *string     `json:"banner"`
+	BannerPlaceholder Placeholder `json:"bannerPlaceholder"`
+	Bio               *string     `json:"bio"`
+	Followers         uint        `json:"followers"`
+	Followee          uint        `json:"followee"`
+	Following         bool        `json:"following"`
+	CreatedAt         time.Time   `json:"createdAt"`
 }
 
-func (user *User) NewProfileResponse(id string) Profile {
+func (user *User) NewProfileResponse() Profile {
 	return Profile{
-		ID:          user.ID,
-		Username:    user.Username,
-		DisplayName: user.DisplayName,
-		Image:       user.Image,
-		Banner:      user.Banner,
-		Bio:         user.Bio,
-		Followers:   uint(len(user.Followers)),
-		Followee:    uint(len(user.Followee)),
-		Following:   user.IsFollowing(id),
-		CreatedAt:   user.CreatedAt,
+		ID:                user.ID,
+		Username:          user.Username,
+		DisplayName:       user.DisplayName,
+		Image:             user.Image,
+		ImagePlaceholder:  user.ImagePlaceholder,
+		Banner:            user.Banner,
+		BannerPlaceholder: user.BannerPlaceholder,
+		Bio:               user.Bio,
+		Followers:         user.FollowerCount,
+		Followee:          user.FolloweeCount,
+		Following:         user.Following,
+		CreatedAt:         user.CreatedAt,
 	}
 }
 
-func (user *User) IsFollowing(id string) bool {
-	if id == "" {
-		return false
-	}
+type User struct {
+	ID                string      `gorm:"primaryKey"`
+	Username          string      `gorm:"not null;index;uniqueIndex"`
+	DisplayName       string      `gorm:"not null;index"`
+	Email             string      `gorm:"not null;uniqueIndex"`
+	Password          string      `gorm:"not null" json:"-"`
+	Image             string      `gorm:"not null"`
+	ImagePlaceholder  Placeholder `gorm:"embedded;embeddedPrefix:image_"`
+	ImageMediaID      *string     `json:"-"`
+	Banner            *string
+	BannerPlaceholder Placeholder `gorm:"embedded;embeddedPrefix:banner_"`
+	BannerMediaID     *string     `json:"-"`
+	Bio               *string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Posts             []Post
 
-	for _, v := range user.Followers {
-		if v.ID == id {
-			return true
-		}
-	}
-	return false
+	// PinnedPostID is one of the user's posts shown first on their profile.
+	// Deleting the post clears it.
+	PinnedPostID *string `json:"-"`
+
+	Followers []*User `gorm:"many2many:followers" json:"-"`
+	Followee  []*User `gorm:"many2many:followee" json:"-"`
+
+	// Counters get updated together with the join tables, so the users don't have to be loaded
+	FollowerCount uint `gorm:"not null;default:0" json:"-"`
+	FolloweeCount uint `gorm:"not null;default:0" json:"-"`
+
+	// Following reports if the viewer follows the user and is only set by LoadViewerState
+	Following bool `gorm:"-" json:"-"`
+
+	// SearchRank is the relevance of the user for a profile search
+	SearchRank float64 `gorm:"->;-:migration" json:"-"`
 }
 
-type User struct {
-	ID          string `gorm:"primaryKey"`
-	Username    string `gorm:"not null;index;uniqueIndex"`
-	DisplayName string `gorm:"not null;index"`
-	Email       string `gorm:"not null;uniqueIndex"`
-	Password    string `gorm:"not null" json:"-"`
-	Image       string `gorm:"not null"`
-	Banner      *string
-	Bio         *string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Posts       []Post
-	Followers   []*User `gorm:"many2many:followers" json:"-"`
-	Followee    []*User `gorm:"many2many:followee" json:"-"`
+// SearchCursor returns the position of the user in profile search results
+func (user *User) SearchCursor() ProfileCursor {
+	return ProfileCursor{Rank: user.SearchRank, Followers: user.FollowerCount, ID: user.ID}
 }
 
 type UserService interface {
@@ -92,20 +107,25 @@ type UserService interface {
 	Register(user *User) (*User, error)
 	Login(email, password string) (*User, error)
 	Update(user *User) error
-	ChangeAvatar(header *multipart.FileHeader, directory string) (string, error)
-	ChangeBanner(header *multipart.FileHeader, directory string) (string, error)
-	DeleteImage(key string) error
+	ChangeAvatar(user *User, header *multipart.FileHeader) error
+	ChangeBanner(user *User, header *multipart.FileHeader) error
+	ReleaseMedia(id string) error
 	ChangeFollow(user *User, current string) error
-	Search(term string) (*[]User, error)
+	Search(term, viewerId string, cursor *ProfileCursor) (*[]User, error)
+	LoadViewerState(viewerId string, users []*User) error
 }
 
 type UserRepository interface {
 	FindByID(uid string) (*User, error)
 	FindByEmail(email string) (*User, error)
 	FindByUsername(username string) (*User, error)
+	FindByIDs(ids []string) (*[]User, error)
+	FindByUsernames(usernames []string) (*[]User, error)
 	Create(user *User) (*User, error)
 	Update(user *User) error
 	AddFollow(userId, currentId string) error
 	RemoveFollow(userId, currentId string) error
-	SearchProfiles(term string) (*[]User, error)
+	SetPinnedPost(userId string, postId *string) error
+	SearchProfiles(term, viewerId string, cursor *ProfileCursor) (*[]User, error)
+	LoadViewerState(viewerId string, users []*User) error
 }
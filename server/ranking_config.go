@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"github.com/sentrionic/mirage/model"
+	"os"
+	"strconv"
+	"time"
+)
+
+// readRankingConfig reads the FOR_YOU_* weights of the For You timeline.
+// Unset weights keep their defaults.
+func readRankingConfig() (model.RankingConfig, error) {
+	config := model.DefaultRankingConfig()
+
+	floats := map[string]*float64{
+		"FOR_YOU_RECENCY_WEIGHT":  &config.RecencyWeight,
+		"FOR_YOU_VELOCITY_WEIGHT": &config.VelocityWeight,
+		"FOR_YOU_AFFINITY_WEIGHT": &config.AffinityWeight,
+		"FOR_YOU_FOLLOWING_BOOST": &config.FollowingBoost,
+		"FOR_YOU_NETWORK_BOOST":   &config.NetworkBoost,
+		"FOR_YOU_HASHTAG_BOOST":   &config.HashtagBoost,
+	}
+
+	for key, target := range floats {
+		if value := os.Getenv(key); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return config, fmt.Errorf("could not parse %s as float: %w", key, err)
+			}
+			*target = parsed
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"FOR_YOU_HALF_LIFE": &config.HalfLife,
+		"FOR_YOU_WINDOW":    &config.Window,
+	}
+
+	for key, target := range durations {
+		if value := os.Getenv(key); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed <= 0 {
+				return config, fmt.Errorf("could not parse %s as a positive duration: %v", key, value)
+			}
+			*target = parsed
+		}
+	}
+
+	if value := os.Getenv("FOR_YOU_MAX_PER_AUTHOR"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return config, fmt.Errorf("could not parse FOR_YOU_MAX_PER_AUTHOR as int: %w", err)
+		}
+		config.MaxPerAuthor = parsed
+	}
+
+	return config, nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"errors"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"gorm.io/gorm"
+	"log"
+)
+
+// bookmarkRepository is data/repository implementation
+// of service layer BookmarkRepository
+type bookmarkRepository struct {
+	DB *gorm.DB
+}
+
+// NewBookmarkRepository is a factory for initializing Bookmark Repositories
+func NewBookmarkRepository(db *gorm.DB) model.BookmarkRepository {
+	return &bookmarkRepository{
+		DB: db,
+	}
+}
+
+// FindBookmark returns the bookmark of the post in the user's bookmarks.
+// It returns nil if the user didn't bookmark the post.
+func (r *bookmarkRepository) FindBookmark(userId, postId string) (*model.Bookmark, error) {
+	var bookmarks []model.Bookmark
+
+	if err := r.DB.Where("user_id = ? AND post_id = ?", userId, postId).Limit(1).Find(&bookmarks).Error; err != nil {
+		log.Printf("Could not find bookmark of post: %v for user: %v. Reason: %v\n", postId, userId, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	if len(bookmarks) == 0 {
+		return nil, nil
+	}
+
+	return &bookmarks[0], nil
+}
+
+// AddBookmark saves the post to the bookmarks of the user, optionally in one of their folders.
+// Bookmarking a post twice doesn't change anything.
+func (r *bookmarkRepository) AddBookmark(post *model.Post, uid string, folderId *string) error {
+	if err := r.DB.Exec(
+		"INSERT INTO bookmarks (user_id, post_id, folder_id, created_at) VALUES (?, ?, ?, now()) ON CONFLICT DO NOTHING",
+		uid, post.ID, folderId,
+	).Error; err != nil {
+		log.Printf("Could not bookmark post: %v for user: %v. Reason: %v\n", post.ID, uid, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// MoveBookmark moves the post to another folder of the user's bookmarks,
+// or out of its folder if none is given
+func (r *bookmarkRepository) MoveBookmark(post *model.Post, uid string, folderId *string) error {
+	if err := r.DB.Exec("UPDATE bookmarks SET folder_id = ? WHERE user_id = ? AND post_id = ?", folderId, uid, post.ID).Error; err != nil {
+		log.Printf("Could not move bookmark of post: %v for user: %v. Reason: %v\n", post.ID, uid, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// RemoveBookmark removes the post from the bookmarks of the user
+func (r *bookmarkRepository) RemoveBookmark(post *model.Post, uid string) error {
+	if err := r.DB.Exec("DELETE FROM bookmarks WHERE user_id = ? AND post_id = ?", uid, post.ID).Error; err != nil {
+		log.Printf("Could not remove bookmark of post: %v for user: %v. Reason: %v\n", post.ID, uid, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// Bookmarks returns the posts the user bookmarked, the ones bookmarked last first.
+// Only the bookmarks in the folder are returned if one is given.
+func (r *bookmarkRepository) Bookmarks(userId string, folderId *string, page model.Page) (*[]model.Post, error) {
+	var posts []model.Post
+
+	query := r.DB.
+		Preload("User").
+		Preload("File").
+		Preload("File.Variants").
+		Preload("Mentions").
+		Preload("Card").
+		Preload("Poll").
+		Preload("Poll.Options", orderPollOptions).
+		Select("\"posts\".*, b.created_at AS bookmarked_at").
+		Joins("JOIN bookmarks b on \"posts\".id = b.post_id").
+		Where("b.user_id = ?", userId)
+
+	if folderId != nil {
+		query = query.Where("b.folder_id = ?", *folderId)
+	}
+
+	if err := paginate(query, page, "b.created_at", "\"posts\".id").Find(&posts).Error; err != nil {
+		log.Printf("Could not find bookmarks of user: %v. Reason: %v\n", userId, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	if page.After != nil {
+		reverse(posts)
+	}
+
+	return &posts, nil
+}
+
+// FindFolders returns the bookmark folders of the user sorted by name
+func (r *bookmarkRepository) FindFolders(userId string) ([]model.BookmarkFolder, error) {
+	var folders []model.BookmarkFolder
+
+	if err := r.DB.
+		Where("user_id = ?", userId).
+		Order("LOWER(name), id").
+		Find(&folders).Error; err != nil {
+		log.Printf("Could not find bookmark folders of user: %v. Reason: %v\n", userId, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	return folders, nil
+}
+
+// FindFolderByID returns the bookmark folder for the given ID
+func (r *bookmarkRepository) FindFolderByID(id string) (*model.BookmarkFolder, error) {
+	folder := &model.BookmarkFolder{}
+
+	if err := r.DB.Where("id = ?", id).First(folder).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return folder, apperrors.NewNotFound("folder", id)
+		}
+		log.Printf("Could not find bookmark folder: %v. Reason: %v\n", id, err)
+		return folder, apperrors.NewInternal()
+	}
+
+	return folder, nil
+}
+
+// CreateFolder inserts the folder. Names are unique per user.
+func (r *bookmarkRepository) CreateFolder(folder *model.BookmarkFolder) error {
+	if err := r.DB.Omit("User").Create(folder).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return apperrors.NewConflict("name")
+		}
+		log.Printf("Could not create bookmark folder for user: %v. Reason: %v\n", folder.UserID, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// DeleteFolder removes the folder. Its bookmarks stay without a folder.
+func (r *bookmarkRepository) DeleteFolder(folder *model.BookmarkFolder) error {
+	if err := r.DB.Delete(folder).Error; err != nil {
+		log.Printf("Could not delete bookmark folder: %v. Reason: %v\n", folder.ID, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sentrionic/mirage/model"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// maxCardRedirects is the number of redirects followed for a page or image
+const maxCardRedirects = 3
+
+// httpCardFetcher downloads the resources of cards from the internet.
+// The addresses get checked after resolving the host, so a
+// host can't point to a private network by changing its DNS records.
+type httpCardFetcher struct {
+	Client *http.Client
+}
+
+// NewCardFetcher is a factory for initializing the CardFetcher.
+// Each request must finish within the timeout.
+func NewCardFetcher(timeout time.Duration) model.CardFetcher {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: allowPublicAddress,
+	}
+
+	transport := &http.Transport{
+		Proxy:                  nil,
+		DialContext:            dialer.DialContext,
+		TLSHandshakeTimeout:    timeout,
+		ResponseHeaderTimeout:  timeout,
+		MaxResponseHeaderBytes: 64 << 10,
+		MaxIdleConns:           10,
+		IdleConnTimeout:        30 * time.Second,
+	}
+
+	return &httpCardFetcher{
+		Client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > maxCardRedirects {
+					return errors.New("too many redirects")
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return fmt.Errorf("unsupported scheme: %s", req.URL.Scheme)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Fetch requests the URL and returns at most maxBytes of its body.
+// Responses that declare a larger body are rejected right away.
+func (f *httpCardFetcher) Fetch(url string, maxBytes int64) (*model.FetchedResource, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), f.Client.Timeout)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		cancel()
+		return nil, fmt.Errorf("unsupported scheme: %s", req.URL.Scheme)
+	}
+
+	req.Header.Set("User-Agent", "MirageBot/1.0 (+link previews)")
+	req.Header.Set("Accept", "text/html,image/*;q=0.9")
+
+	res, err := f.Client.Do(req)
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		_ = res.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("unexpected status: %d", res.StatusCode)
+	}
+
+	if res.ContentLength > maxBytes {
+		_ = res.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("body too large: %d bytes", res.ContentLength)
+	}
+
+	return &model.FetchedResource{
+		URL:         res.Request.URL.String(),
+		ContentType: res.Header.Get("Content-Type"),
+		Body:        &limitedBody{Reader: io.LimitReader(res.Body, maxBytes), body: res.Body, cancel: cancel},
+	}, nil
+}
+
+// limitedBody cancels the request once the body got closed
+type limitedBody struct {
+	io.Reader
+	body   io.Closer
+	cancel context.CancelFunc
+}
+
+func (b *limitedBody) Close() error {
+	defer b.cancel()
+	return b.body.Close()
+}
+
+// blockedNetworks are reserved ranges that net.IP has no check for
+var blockedNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+	"2001:db8::/32",
+)
+
+// allowPublicAddress rejects connections to addresses that aren't public
+// and to ports other than the ones of http and https
+func allowPublicAddress(_, address string, _ syscall.RawConn) error {
+	host, port, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return err
+	}
+
+	if port != "80" && port != "443" {
+		return fmt.Errorf("port not allowed: %s", port)
+	}
+
+	ip := net.ParseIP(host)
+
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("address not allowed: %s", host)
+	}
+
+	return nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
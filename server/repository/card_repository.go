@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// cardRepository is data/repository implementation
+// of service layer CardRepository
+type cardRepository struct {
+	DB *gorm.DB
+}
+
+// NewCardRepository is a factory for initializing Card Repositories
+func NewCardRepository(db *gorm.DB) model.CardRepository {
+	return &cardRepository{
+		DB: db,
+	}
+}
+
+// Add makes sure a card for the URL exists without claiming it,
+// so posts can link the card before it gets claimed.
+func (r *cardRepository) Add(url string) error {
+	if err := r.DB.Exec(`
+		INSERT INTO cards (url, status, claimed_at) VALUES (?, ?, ?)
+		ON CONFLICT (url) DO NOTHING
+	`, url, model.CardPending, time.Time{}).Error; err != nil {
+		log.Printf("Could not add card: %v. Reason: %v\n", url, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// Claim makes sure a card for the URL exists and returns true if the caller should fetch it.
+// That is the case for new cards and cards that haven't been claimed within the max age,
+// so concurrent posts of the same link only fetch it once.
+func (r *cardRepository) Claim(url string) (bool, error) {
+	result := r.DB.Exec(`
+		INSERT INTO cards (url, status, claimed_at) VALUES (?, ?, now())
+		ON CONFLICT (url) DO UPDATE SET claimed_at = now() WHERE cards.claimed_at < ?
+	`, url, model.CardPending, time.Now().Add(-model.CardMaxAge))
+
+	if result.Error != nil {
+		log.Printf("Could not claim card: %v. Reason: %v\n", url, result.Error)
+		return false, apperrors.NewInternal()
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Save updates the fetched card
+func (r *cardRepository) Save(card *model.Card) error {
+	if err := r.DB.
+		Model(card).
+		Select("status", "title", "description", "site_name", "image", "image_blur_hash", "image_dominant_color", "fetched_at").
+		Updates(card).Error; err != nil {
+		log.Printf("Could not save card: %v. Reason: %v\n", card.URL, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
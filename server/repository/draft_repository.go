@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"errors"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// draftRepository is data/repository implementation
+// of service layer DraftRepository
+type draftRepository struct {
+	DB *gorm.DB
+}
+
+// NewDraftRepository is a factory for initializing Draft Repositories
+func NewDraftRepository(db *gorm.DB) model.DraftRepository {
+	return &draftRepository{
+		DB: db,
+	}
+}
+
+// editableStatuses are the statuses of drafts the scheduler doesn't touch
+var editableStatuses = []string{model.DraftSaved, model.DraftScheduled, model.DraftFailed}
+
+// FindByID returns the draft for the given ID
+func (r *draftRepository) FindByID(id string) (*model.Draft, error) {
+	draft := &model.Draft{}
+
+	if err := r.DB.Where("id = ?", id).First(draft).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return draft, apperrors.NewNotFound("draft", id)
+		}
+		log.Printf("Could not find draft: %v. Reason: %v\n", id, err)
+		return draft, apperrors.NewInternal()
+	}
+
+	return draft, nil
+}
+
+// FindByUser returns the drafts of the user, newest first
+func (r *draftRepository) FindByUser(userId string, page model.Page) (*[]model.Draft, error) {
+	var drafts []model.Draft
+
+	query := r.DB.Where("user_id = ?", userId)
+
+	if err := paginate(query, page, "created_at", "id").Find(&drafts).Error; err != nil {
+		log.Printf("Could not find drafts of user: %v. Reason: %v\n", userId, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	if page.After != nil {
+		reverse(drafts)
+	}
+
+	return &drafts, nil
+}
+
+// FindDue returns the scheduled drafts up to the given time, the ones due first
+func (r *draftRepository) FindDue(until time.Time, limit int) ([]model.Draft, error) {
+	var drafts []model.Draft
+
+	if err := r.DB.
+		Where("status = ? AND scheduled_at <= ?", model.DraftScheduled, until).
+		Order("scheduled_at, id").
+		Limit(limit).
+		Find(&drafts).Error; err != nil {
+		log.Printf("Could not find due drafts. Reason: %v\n", err)
+		return nil, apperrors.NewInternal()
+	}
+
+	return drafts, nil
+}
+
+// Create inserts the draft in the DB
+func (r *draftRepository) Create(draft *model.Draft) error {
+	if err := r.DB.Omit("User").Create(draft).Error; err != nil {
+		log.Printf("Could not create a draft for user: %v. Reason: %v\n", draft.UserID, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// Update saves the text, schedule and status of the draft and clears its error.
+// Drafts the scheduler claimed in the meantime don't get changed.
+func (r *draftRepository) Update(draft *model.Draft) error {
+	result := r.DB.
+		Model(draft).
+		Where("status IN ?", editableStatuses).
+		Select("text", "scheduled_at", "status", "error", "updated_at").
+		Updates(draft)
+
+	if result.Error != nil {
+		log.Printf("Could not update draft: %v. Reason: %v\n", draft.ID, result.Error)
+		return apperrors.NewInternal()
+	}
+
+	if result.RowsAffected == 0 {
+		return apperrors.NewForbidden("the draft is being published")
+	}
+
+	return nil
+}
+
+// Delete removes the draft unless the scheduler is publishing it right now
+func (r *draftRepository) Delete(draft *model.Draft) error {
+	result := r.DB.
+		Where("id = ? AND status <> ?", draft.ID, model.DraftPublishing).
+		Delete(&model.Draft{})
+
+	if result.Error != nil {
+		log.Printf("Could not delete draft: %v. Reason: %v\n", draft.ID, result.Error)
+		return apperrors.NewInternal()
+	}
+
+	if result.RowsAffected == 0 {
+		return apperrors.NewForbidden("the draft is being published")
+	}
+
+	return nil
+}
+
+// Claim marks the due draft as publishing and returns true if the caller should publish it.
+// Only one caller can claim a draft, so it gets published at most once.
+func (r *draftRepository) Claim(id string) (bool, error) {
+	result := r.DB.
+		Model(&model.Draft{}).
+		Where("id = ? AND status = ? AND scheduled_at <= now()", id, model.DraftScheduled).
+		Updates(map[string]interface{}{"status": model.DraftPublishing, "error": nil})
+
+	if result.Error != nil {
+		log.Printf("Could not claim draft: %v. Reason: %v\n", id, result.Error)
+		return false, apperrors.NewInternal()
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// MarkPublished links the draft to its post.
+// Drafts that expired while they got published still get their post.
+func (r *draftRepository) MarkPublished(id, postId string) error {
+	if err := r.DB.
+		Model(&model.Draft{}).
+		Where("id = ? AND status IN ?", id, []string{model.DraftPublishing, model.DraftFailed}).
+		Updates(map[string]interface{}{"status": model.DraftPublished, "post_id": postId, "error": nil}).
+		Error; err != nil {
+		log.Printf("Could not mark draft: %v as published. Reason: %v\n", id, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// MarkFailed keeps the reason the draft couldn't be published
+func (r *draftRepository) MarkFailed(id, reason string) error {
+	if err := r.DB.
+		Model(&model.Draft{}).
+		Where("id = ? AND status = ?", id, model.DraftPublishing).
+		Updates(map[string]interface{}{"status": model.DraftFailed, "error": reason}).
+		Error; err != nil {
+		log.Printf("Could not mark draft: %v as failed. Reason: %v\n", id, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// ExpirePublishing marks drafts that got claimed before the given time and never finished as failed.
+// Their post might exist already, so they don't get published again on their own.
+func (r *draftRepository) ExpirePublishing(before time.Time) (int, error) {
+	result := r.DB.
+		Model(&model.Draft{}).
+		Where("status = ? AND updated_at < ?", model.DraftPublishing, before).
+		Updates(map[string]interface{}{
+			"status": model.DraftFailed,
+			"error":  "publishing got interrupted, check your posts before scheduling it again",
+		})
+
+	if result.Error != nil {
+		log.Printf("Could not expire publishing drafts. Reason: %v\n", result.Error)
+		return 0, apperrors.NewInternal()
+	}
+
+	return int(result.RowsAffected), nil
+}
@@ -7,14 +7,18 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/buckket/go-blurhash"
+	"github.com/chai2010/webp"
 	"github.com/disintegration/imaging"
 	"github.com/sentrionic/mirage/model"
-	"github.com/sentrionic/mirage/service"
+	"github.com/sentrionic/mirage/model/apperrors"
 	"image"
-	_ "image/gif"
+	"image/color"
+	"image/gif"
 	"image/jpeg"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
 	"mime/multipart"
 )
 
@@ -34,13 +38,26 @@ func NewFileRepository(session *session.Session, bucketName string) model.FileRe
 
 // UploadAvatar uploads the given image to the initialized Bucket.
 // The image gets resized before being uploaded.
-// All images turn into jpeg images.
+// Animated gifs stay gifs, all other images turn into jpeg images.
+// It returns the uploaded file and its placeholder.
+func (s *s3FileRepository) UploadAvatar(header *multipart.FileHeader, directory, slug string) (model.FileVariant, model.Placeholder, error) {
+	return s.uploadProfileImage(header, directory, slug, 400)
+}
+
+// UploadBanner uploads the given image to the initialized Bucket.
+// The image gets resized before being uploaded.
+// Animated gifs stay gifs, all other images turn into jpeg images.
+// It returns the uploaded file and its placeholder.
+func (s *s3FileRepository) UploadBanner(header *multipart.FileHeader, directory, slug string) (model.FileVariant, model.Placeholder, error) {
+	return s.uploadProfileImage(header, directory, slug, 1500)
+}
+
+// UploadFile uploads the given file to the initialized Bucket.
 // It returns the url of the uploaded file.
-func (s *s3FileRepository) UploadAvatar(header *multipart.FileHeader, directory string) (string, error) {
+func (s *s3FileRepository) UploadFile(header *multipart.FileHeader, directory, filename, mimetype string) (string, error) {
 	uploader := s3manager.NewUploader(s.S3Session)
 
-	id, _ := service.GenerateId()
-	key := fmt.Sprintf("files/%s/%s.jpeg", directory, id)
+	key := fmt.Sprintf("files/%s/%s", directory, filename)
 
 	file, err := header.Open()
 
@@ -48,56 +65,323 @@ func (s *s3FileRepository) UploadAvatar(header *multipart.FileHeader, directory
 		return "", err
 	}
 
-	src, _, err := image.Decode(file)
+	up, err := uploader.Upload(&s3manager.UploadInput{
+		Body:        file,
+		Bucket:      aws.String(s.BucketName),
+		ContentType: aws.String(mimetype),
+		Key:         aws.String(key),
+	})
 
 	if err != nil {
 		return "", err
 	}
 
-	img := imaging.Resize(src, 400, 0, imaging.Lanczos)
+	if err := file.Close(); err != nil {
+		return "", err
+	}
 
-	buf := new(bytes.Buffer)
-	err = jpeg.Encode(buf, img, &jpeg.Options{Quality: 75})
+	return up.Location, nil
+}
+
+// imageSize describes the bounds of a generated image variant.
+// Cropped variants are filled to the exact bounds, all others
+// are scaled down to fit them while keeping their aspect ratio.
+type imageSize struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+}
+
+var postImageSizes = []imageSize{
+	{Name: model.ThumbSize, Width: 150, Height: 150, Crop: true},
+	{Name: model.MediumSize, Width: 1200, Height: 1200},
+	{Name: model.OriginalSize, Width: 4096, Height: 4096},
+}
+
+// postGifSize limits the size of animated gifs, as every frame is stored in full
+var postGifSize = imageSize{Name: model.OriginalSize, Width: 800, Height: 800}
+
+// UploadImage uploads the given image to the initialized Bucket.
+// The image gets decoded and rotated according to its EXIF orientation,
+// which strips all metadata, before a thumb, medium and original sized
+// version is uploaded as both jpeg and webp.
+// Animated gifs keep their animation and get a poster frame instead.
+// It returns the uploaded variants and the placeholder of the image.
+func (s *s3FileRepository) UploadImage(header *multipart.FileHeader, directory, slug string) ([]model.FileVariant, model.Placeholder, error) {
+	file, err := header.Open()
 
 	if err != nil {
-		return "", err
+		return nil, model.Placeholder{}, err
 	}
 
-	up, err := uploader.Upload(&s3manager.UploadInput{
-		Body:        buf,
-		Bucket:      aws.String(s.BucketName),
-		ContentType: aws.String("image/jpeg"),
-		Key:         aws.String(key),
-	})
+	if err := checkImageSize(file); err != nil {
+		return nil, model.Placeholder{}, err
+	}
+
+	animated, err := decodeAnimatedGif(file)
 
 	if err != nil {
-		return "", err
+		return nil, model.Placeholder{}, err
+	}
+
+	if animated != nil {
+		if err := file.Close(); err != nil {
+			return nil, model.Placeholder{}, err
+		}
+
+		return s.uploadAnimatedImage(animated, directory, slug)
+	}
+
+	src, err := imaging.Decode(file, imaging.AutoOrientation(true))
+
+	if err != nil {
+		return nil, model.Placeholder{}, apperrors.NewBadRequest("could not decode image")
 	}
 
 	if err := file.Close(); err != nil {
-		return "", err
+		return nil, model.Placeholder{}, err
 	}
 
-	return up.Location, nil
+	placeholder, err := newPlaceholder(src)
+
+	if err != nil {
+		return nil, model.Placeholder{}, err
+	}
+
+	variants := make([]model.FileVariant, 0)
+
+	for _, size := range postImageSizes {
+		img := resizeImage(src, size)
+
+		sized, err := s.uploadVariants(img, directory, slug, size.Name)
+
+		if err != nil {
+			return nil, model.Placeholder{}, err
+		}
+
+		variants = append(variants, sized...)
+	}
+
+	return variants, placeholder, nil
 }
 
-// UploadFile uploads the given file to the initialized Bucket.
-// It returns the url of the uploaded file.
-func (s *s3FileRepository) UploadFile(header *multipart.FileHeader, directory, filename, mimetype string) (string, error) {
-	uploader := s3manager.NewUploader(s.S3Session)
+// cardImageSize fits the image of a link preview
+var cardImageSize = imageSize{Name: model.OriginalSize, Width: 1200, Height: 630}
 
-	key := fmt.Sprintf("files/%s/%s", directory, filename)
+// UploadCardImage uploads the image of a link preview to the initialized Bucket.
+// The image comes from another site, so it always gets decoded, resized and
+// re-encoded as a jpeg image. Animated gifs only keep their first frame.
+// It returns the uploaded file and its placeholder.
+func (s *s3FileRepository) UploadCardImage(data []byte, directory, slug string) (model.FileVariant, model.Placeholder, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+
+	if err != nil {
+		return model.FileVariant{}, model.Placeholder{}, apperrors.NewBadRequest("could not decode image")
+	}
+
+	if config.Width*config.Height > maxImagePixels {
+		return model.FileVariant{}, model.Placeholder{}, apperrors.NewBadRequest("image too large")
+	}
+
+	src, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+
+	if err != nil {
+		return model.FileVariant{}, model.Placeholder{}, apperrors.NewBadRequest("could not decode image")
+	}
+
+	img := resizeImage(src, cardImageSize)
+
+	placeholder, err := newPlaceholder(img)
+
+	if err != nil {
+		return model.FileVariant{}, model.Placeholder{}, err
+	}
+
+	buf, err := encodeImage(img, model.JPEG)
+
+	if err != nil {
+		return model.FileVariant{}, model.Placeholder{}, err
+	}
+
+	key := fmt.Sprintf("files/%s/%s.%s", directory, slug, model.JPEG)
+	url, err := s.upload(key, "image/"+model.JPEG, buf)
+
+	if err != nil {
+		return model.FileVariant{}, model.Placeholder{}, err
+	}
+
+	return model.FileVariant{
+		Size:   model.OriginalSize,
+		Format: model.JPEG,
+		Key:    key,
+		Url:    url,
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+	}, placeholder, nil
+}
+
+// uploadAnimatedImage resizes the frames of the gif to fit the post gif size
+// and uploads it together with its first frame as a jpeg and webp poster.
+func (s *s3FileRepository) uploadAnimatedImage(g *gif.GIF, directory, slug string) ([]model.FileVariant, model.Placeholder, error) {
+	resized, poster := resizeGif(g, func(img image.Image) image.Image {
+		return resizeImage(img, postGifSize)
+	})
+
+	placeholder, err := newPlaceholder(poster)
+
+	if err != nil {
+		return nil, model.Placeholder{}, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := gif.EncodeAll(buf, resized); err != nil {
+		return nil, model.Placeholder{}, err
+	}
+
+	key := fmt.Sprintf("files/%s/%s_%s.%s", directory, slug, postGifSize.Name, model.GIF)
+	url, err := s.upload(key, "image/gif", buf)
+
+	if err != nil {
+		return nil, model.Placeholder{}, err
+	}
+
+	variants := []model.FileVariant{{
+		Size:   postGifSize.Name,
+		Format: model.GIF,
+		Key:    key,
+		Url:    url,
+		Width:  poster.Bounds().Dx(),
+		Height: poster.Bounds().Dy(),
+	}}
 
+	posters, err := s.uploadVariants(poster, directory, slug, model.PosterSize)
+
+	if err != nil {
+		return nil, model.Placeholder{}, err
+	}
+
+	return append(variants, posters...), placeholder, nil
+}
+
+// uploadVariants uploads the image as both jpeg and webp.
+func (s *s3FileRepository) uploadVariants(img image.Image, directory, slug, size string) ([]model.FileVariant, error) {
+	variants := make([]model.FileVariant, 0)
+
+	for _, format := range []string{model.JPEG, model.WEBP} {
+		buf, err := encodeImage(img, format)
+
+		if err != nil {
+			return nil, err
+		}
+
+		key := fmt.Sprintf("files/%s/%s_%s.%s", directory, slug, size, format)
+		url, err := s.upload(key, "image/"+format, buf)
+
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, model.FileVariant{
+			Size:   size,
+			Format: format,
+			Key:    key,
+			Url:    url,
+			Width:  img.Bounds().Dx(),
+			Height: img.Bounds().Dy(),
+		})
+	}
+
+	return variants, nil
+}
+
+// uploadProfileImage resizes the image to the given width and uploads it.
+// Animated gifs get every frame resized, all other images turn into jpeg images.
+// It returns the uploaded file and its placeholder.
+func (s *s3FileRepository) uploadProfileImage(header *multipart.FileHeader, directory, slug string, width int) (model.FileVariant, model.Placeholder, error) {
 	file, err := header.Open()
 
 	if err != nil {
-		return "", err
+		return model.FileVariant{}, model.Placeholder{}, err
+	}
+
+	if err := checkImageSize(file); err != nil {
+		return model.FileVariant{}, model.Placeholder{}, err
+	}
+
+	animated, err := decodeAnimatedGif(file)
+
+	if err != nil {
+		return model.FileVariant{}, model.Placeholder{}, err
+	}
+
+	var img image.Image
+	var format string
+	buf := new(bytes.Buffer)
+
+	if animated != nil {
+		resized, poster := resizeGif(animated, func(src image.Image) image.Image {
+			return imaging.Resize(src, width, 0, imaging.Lanczos)
+		})
+
+		if err := gif.EncodeAll(buf, resized); err != nil {
+			return model.FileVariant{}, model.Placeholder{}, err
+		}
+
+		img = poster
+		format = model.GIF
+	} else {
+		src, _, err := image.Decode(file)
+
+		if err != nil {
+			return model.FileVariant{}, model.Placeholder{}, err
+		}
+
+		img = imaging.Resize(src, width, 0, imaging.Lanczos)
+
+		if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: 75}); err != nil {
+			return model.FileVariant{}, model.Placeholder{}, err
+		}
+
+		format = model.JPEG
+	}
+
+	placeholder, err := newPlaceholder(img)
+
+	if err != nil {
+		return model.FileVariant{}, model.Placeholder{}, err
 	}
 
+	key := fmt.Sprintf("files/%s/%s.%s", directory, slug, format)
+	url, err := s.upload(key, "image/"+format, buf)
+
+	if err != nil {
+		return model.FileVariant{}, model.Placeholder{}, err
+	}
+
+	if err := file.Close(); err != nil {
+		return model.FileVariant{}, model.Placeholder{}, err
+	}
+
+	return model.FileVariant{
+		Size:   model.OriginalSize,
+		Format: format,
+		Key:    key,
+		Url:    url,
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+	}, placeholder, nil
+}
+
+// upload puts the body under the given key in the Bucket.
+// It returns the url of the uploaded object.
+func (s *s3FileRepository) upload(key, contentType string, body io.Reader) (string, error) {
+	uploader := s3manager.NewUploader(s.S3Session)
+
 	up, err := uploader.Upload(&s3manager.UploadInput{
-		Body:        file,
+		Body:        body,
 		Bucket:      aws.String(s.BucketName),
-		ContentType: aws.String(mimetype),
+		ContentType: aws.String(contentType),
 		Key:         aws.String(key),
 	})
 
@@ -105,10 +389,6 @@ func (s *s3FileRepository) UploadFile(header *multipart.FileHeader, directory, f
 		return "", err
 	}
 
-	if err := file.Close(); err != nil {
-		return "", err
-	}
-
 	return up.Location, nil
 }
 
@@ -123,47 +403,145 @@ func (s *s3FileRepository) DeleteImage(key string) error {
 	return err
 }
 
-func (s *s3FileRepository) UploadBanner(header *multipart.FileHeader, directory string) (string, error) {
-	uploader := s3manager.NewUploader(s.S3Session)
+// ListObjects returns all objects in the bucket whose key starts with the given prefix
+func (s *s3FileRepository) ListObjects(prefix string) ([]model.StoredObject, error) {
+	srv := s3.New(s.S3Session)
+	objects := make([]model.StoredObject, 0)
+
+	err := srv.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(s.BucketName),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			objects = append(objects, model.StoredObject{
+				Key:          aws.StringValue(object.Key),
+				LastModified: aws.TimeValue(object.LastModified),
+			})
+		}
+		return true
+	})
 
-	id, _ := service.GenerateId()
-	key := fmt.Sprintf("files/%s/%s.jpeg", directory, id)
+	return objects, err
+}
 
-	file, err := header.Open()
+// maxImagePixels guards against small files that decode to huge images
+const maxImagePixels = 40_000_000
+
+// checkImageSize reads the dimensions of the image before it gets decoded
+// and rejects images with more than maxImagePixels pixels.
+func checkImageSize(file multipart.File) error {
+	config, _, err := image.DecodeConfig(file)
 
 	if err != nil {
-		return "", err
+		return apperrors.NewBadRequest("could not decode image")
 	}
 
-	src, _, err := image.Decode(file)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
 
-	if err != nil {
-		return "", err
+	if config.Width*config.Height > maxImagePixels {
+		return apperrors.NewBadRequest("image too large")
+	}
+
+	return nil
+}
+
+// resizeImage scales the image down to the given size.
+// Images that already fit are returned unchanged.
+func resizeImage(src image.Image, size imageSize) image.Image {
+	if size.Crop {
+		return imaging.Fill(src, size.Width, size.Height, imaging.Center, imaging.Lanczos)
 	}
 
-	img := imaging.Resize(src, 1500, 0, imaging.Lanczos)
+	return imaging.Fit(src, size.Width, size.Height, imaging.Lanczos)
+}
 
+// encodeImage encodes the image in the given format.
+// Jpeg does not support transparency, so the image gets flattened first.
+func encodeImage(img image.Image, format string) (*bytes.Buffer, error) {
 	buf := new(bytes.Buffer)
-	err = jpeg.Encode(buf, img, &jpeg.Options{Quality: 75})
 
-	if err != nil {
-		return "", err
+	switch format {
+	case model.JPEG:
+		if err := jpeg.Encode(buf, flatten(img), &jpeg.Options{Quality: 75}); err != nil {
+			return nil, err
+		}
+	case model.WEBP:
+		if err := webp.Encode(buf, img, &webp.Options{Quality: 75}); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported image format: %s", format)
 	}
 
-	up, err := uploader.Upload(&s3manager.UploadInput{
-		Body:        buf,
-		Bucket:      aws.String(s.BucketName),
-		ContentType: aws.String("image/jpeg"),
-		Key:         aws.String(key),
-	})
+	return buf, nil
+}
+
+// flatten places the image on a white background
+// to get rid of any transparency.
+func flatten(img image.Image) image.Image {
+	bg := imaging.New(img.Bounds().Dx(), img.Bounds().Dy(), color.White)
+	return imaging.Overlay(bg, img, image.Pt(0, 0), 1.0)
+}
+
+// newPlaceholder computes the blurhash and dominant color of the image.
+// Both only describe the rough colors of the image,
+// so they get calculated on a small version of it.
+func newPlaceholder(img image.Image) (model.Placeholder, error) {
+	small := flatten(imaging.Fit(img, 64, 64, imaging.Box))
+
+	hash, err := blurhash.Encode(4, 3, small)
 
 	if err != nil {
-		return "", err
+		return model.Placeholder{}, err
 	}
 
-	if err := file.Close(); err != nil {
-		return "", err
+	return model.Placeholder{
+		BlurHash:      hash,
+		DominantColor: dominantColor(small),
+	}, nil
+}
+
+// dominantColor returns the most common color of the image as a hex string.
+// Similar colors are grouped into buckets and the average color
+// of the largest bucket is returned.
+func dominantColor(img image.Image) string {
+	type bucket struct {
+		R, G, B, Count int
 	}
 
-	return up.Location, nil
+	buckets := make(map[int]*bucket)
+	var largest *bucket
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+
+			// Only keep the upper 4 bits of each channel, so similar colors share a bucket
+			key := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4)
+
+			b, ok := buckets[key]
+			if !ok {
+				b = &bucket{}
+				buckets[key] = b
+			}
+
+			b.R += int(c.R)
+			b.G += int(c.G)
+			b.B += int(c.B)
+			b.Count++
+
+			if largest == nil || b.Count > largest.Count {
+				largest = b
+			}
+		}
+	}
+
+	if largest == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", largest.R/largest.Count, largest.G/largest.Count, largest.B/largest.Count)
 }
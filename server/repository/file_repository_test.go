@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// memoryFile serves the bytes of an uploaded file from memory
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (f memoryFile) Close() error {
+	return nil
+}
+
+// pngHeader returns the start of a png image with the given dimensions, which is all DecodeConfig reads
+func pngHeader(width, height uint32) []byte {
+	chunk := make([]byte, 17)
+	copy(chunk, "IHDR")
+	binary.BigEndian.PutUint32(chunk[4:], width)
+	binary.BigEndian.PutUint32(chunk[8:], height)
+	chunk[12] = 8 // bit depth
+	chunk[13] = 2 // truecolor
+
+	buf := bytes.NewBufferString("\x89PNG\r\n\x1a\n")
+	_ = binary.Write(buf, binary.BigEndian, uint32(13))
+	buf.Write(chunk)
+	_ = binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func TestCheckImageSize(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		assert.NoError(t, png.Encode(buf, image.NewGray(image.Rect(0, 0, 4, 4))))
+		file := memoryFile{bytes.NewReader(buf.Bytes())}
+
+		err := checkImageSize(file)
+
+		assert.NoError(t, err)
+
+		// The file gets read from the start again afterwards
+		_, err = png.Decode(file)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Too many pixels", func(t *testing.T) {
+		file := memoryFile{bytes.NewReader(pngHeader(20_000, 20_000))}
+
+		err := checkImageSize(file)
+
+		assert.Equal(t, apperrors.NewBadRequest("image too large"), err)
+	})
+
+	t.Run("Not an image", func(t *testing.T) {
+		file := memoryFile{bytes.NewReader([]byte("not an image"))}
+
+		err := checkImageSize(file)
+
+		assert.Equal(t, apperrors.NewBadRequest("could not decode image"), err)
+	})
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"mime/multipart"
+)
+
+const (
+	maxGifFrames    = 300
+	maxGifDuration  = 60 * 100 // in 100ths of a second
+	maxGifDimension = 2048
+)
+
+// decodeAnimatedGif decodes the file if it is a gif with more than one frame.
+// It returns nil for all other images. The file gets reset afterwards,
+// so it can be decoded again.
+func decodeAnimatedGif(file multipart.File) (*gif.GIF, error) {
+	config, format, err := image.DecodeConfig(file)
+
+	if err != nil {
+		return nil, apperrors.NewBadRequest("could not decode image")
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if format != "gif" {
+		return nil, nil
+	}
+
+	if config.Width > maxGifDimension || config.Height > maxGifDimension {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("gifs can be at most %dx%d pixels", maxGifDimension, maxGifDimension))
+	}
+
+	// The frames get counted first, so gifs over the limits aren't decoded at all
+	frames, duration, err := scanGif(file, maxGifFrames+1)
+
+	if err != nil {
+		return nil, apperrors.NewBadRequest("could not decode image")
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if frames < 2 {
+		return nil, nil
+	}
+
+	if frames > maxGifFrames {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("gifs can have at most %d frames", maxGifFrames))
+	}
+
+	if duration > maxGifDuration {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("gifs can be at most %d seconds long", maxGifDuration/100))
+	}
+
+	g, err := gif.DecodeAll(file)
+
+	if err != nil {
+		return nil, apperrors.NewBadRequest("could not decode image")
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// Blocks of a gif file
+const (
+	gifExtension        = 0x21
+	gifImageDescriptor  = 0x2C
+	gifTrailer          = 0x3B
+	gifGraphicControl   = 0xF9
+	gifColorTableFlag   = 0x80
+	gifColorTableBits   = 0x07
+	gifScreenDescriptor = 7
+	gifFrameDescriptor  = 9
+)
+
+// scanGif counts the frames of the gif and adds up their delays by walking its blocks,
+// without decompressing any of the frames. It stops after limit frames.
+func scanGif(r io.Reader, limit int) (frames, duration int, err error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, 6+gifScreenDescriptor)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return 0, 0, err
+	}
+
+	if err := skipColorTable(br, header[10]); err != nil {
+		return 0, 0, err
+	}
+
+	delay := 0
+	for frames < limit {
+		block, err := br.ReadByte()
+
+		if err != nil {
+			return 0, 0, err
+		}
+
+		switch block {
+		case gifExtension:
+			label, err := br.ReadByte()
+
+			if err != nil {
+				return 0, 0, err
+			}
+
+			if label == gifGraphicControl {
+				control := make([]byte, 5)
+				if _, err := io.ReadFull(br, control); err != nil {
+					return 0, 0, err
+				}
+				// Like the decoder, every following frame keeps the delay until the next control block
+				delay = int(control[2]) | int(control[3])<<8
+			}
+
+			if err := skipSubBlocks(br); err != nil {
+				return 0, 0, err
+			}
+		case gifImageDescriptor:
+			descriptor := make([]byte, gifFrameDescriptor)
+			if _, err := io.ReadFull(br, descriptor); err != nil {
+				return 0, 0, err
+			}
+
+			if err := skipColorTable(br, descriptor[8]); err != nil {
+				return 0, 0, err
+			}
+
+			// LZW minimum code size
+			if _, err := br.ReadByte(); err != nil {
+				return 0, 0, err
+			}
+
+			if err := skipSubBlocks(br); err != nil {
+				return 0, 0, err
+			}
+
+			frames++
+			duration += delay
+		case gifTrailer:
+			return frames, duration, nil
+		default:
+			return 0, 0, fmt.Errorf("unknown gif block: %#x", block)
+		}
+	}
+
+	return frames, duration, nil
+}
+
+// skipColorTable skips the color table that the packed fields of a descriptor announce
+func skipColorTable(br *bufio.Reader, fields byte) error {
+	if fields&gifColorTableFlag == 0 {
+		return nil
+	}
+
+	_, err := br.Discard(3 * (1 << (1 + fields&gifColorTableBits)))
+	return err
+}
+
+// skipSubBlocks skips data sub-blocks up to and including the terminating empty block
+func skipSubBlocks(br *bufio.Reader) error {
+	for {
+		size, err := br.ReadByte()
+
+		if err != nil || size == 0 {
+			return err
+		}
+
+		if _, err := br.Discard(int(size)); err != nil {
+			return err
+		}
+	}
+}
+
+// resizeGif applies the resize function to every frame of the gif.
+// Frames may only cover part of the canvas, so each one gets drawn
+// onto the full canvas according to the disposal method of the previous
+// frame before being resized.
+// It returns the resized gif and its first frame to be used as a poster.
+func resizeGif(g *gif.GIF, resize func(image.Image) image.Image) (*gif.GIF, image.Image) {
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() {
+		for _, frame := range g.Image {
+			bounds = bounds.Union(frame.Bounds())
+		}
+	}
+
+	canvas := image.NewNRGBA(bounds)
+	var poster image.Image
+
+	out := &gif.GIF{
+		Image:     make([]*image.Paletted, 0, len(g.Image)),
+		Delay:     make([]int, 0, len(g.Image)),
+		Disposal:  make([]byte, 0, len(g.Image)),
+		LoopCount: g.LoopCount,
+	}
+
+	for i, frame := range g.Image {
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+
+		var previous *image.NRGBA
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewNRGBA(bounds)
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		resized := resize(canvas)
+		if poster == nil {
+			poster = resized
+		}
+
+		palette := frame.Palette
+		if len(palette) == 0 {
+			palette = g.Image[0].Palette
+		}
+
+		paletted := image.NewPaletted(resized.Bounds(), palette)
+		draw.FloydSteinberg.Draw(paletted, resized.Bounds(), resized, resized.Bounds().Min)
+
+		var delay int
+		if i < len(g.Delay) {
+			delay = g.Delay[i]
+		}
+
+		// Every frame now covers the whole canvas, so it has to be cleared before the next one
+		out.Image = append(out.Image, paletted)
+		out.Delay = append(out.Delay, delay)
+		out.Disposal = append(out.Disposal, gif.DisposalBackground)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	return out, poster
+}
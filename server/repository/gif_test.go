@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"testing"
+)
+
+// encodeGif returns a gif with the given number of tiny frames, each shown for delay 100ths of a second
+func encodeGif(t *testing.T, frames, delay int) []byte {
+	g := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, 2, 2), palette.Plan9)
+		frame.SetColorIndex(i%2, 0, uint8(i))
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, delay)
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, gif.EncodeAll(buf, g))
+	return buf.Bytes()
+}
+
+func TestScanGif(t *testing.T) {
+	t.Run("Counts frames and delays like the decoder", func(t *testing.T) {
+		data := encodeGif(t, 12, 7)
+		decoded, err := gif.DecodeAll(bytes.NewReader(data))
+		assert.NoError(t, err)
+
+		frames, duration, err := scanGif(bytes.NewReader(data), maxGifFrames+1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, len(decoded.Image), frames)
+		assert.Equal(t, 12*7, duration)
+	})
+
+	t.Run("Stops at the limit", func(t *testing.T) {
+		frames, _, err := scanGif(bytes.NewReader(encodeGif(t, 20, 1)), 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, frames)
+	})
+
+	t.Run("Truncated gif", func(t *testing.T) {
+		data := encodeGif(t, 3, 1)
+
+		_, _, err := scanGif(bytes.NewReader(data[:len(data)-10]), maxGifFrames+1)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDecodeAnimatedGif(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		g, err := decodeAnimatedGif(memoryFile{bytes.NewReader(encodeGif(t, 3, 10))})
+
+		assert.NoError(t, err)
+		assert.Len(t, g.Image, 3)
+	})
+
+	t.Run("Single frame", func(t *testing.T) {
+		g, err := decodeAnimatedGif(memoryFile{bytes.NewReader(encodeGif(t, 1, 0))})
+
+		assert.NoError(t, err)
+		assert.Nil(t, g)
+	})
+
+	t.Run("Too many frames", func(t *testing.T) {
+		_, err := decodeAnimatedGif(memoryFile{bytes.NewReader(encodeGif(t, maxGifFrames+1, 1))})
+
+		assert.Equal(t, apperrors.NewBadRequest(fmt.Sprintf("gifs can have at most %d frames", maxGifFrames)), err)
+	})
+
+	t.Run("Too long", func(t *testing.T) {
+		_, err := decodeAnimatedGif(memoryFile{bytes.NewReader(encodeGif(t, 2, maxGifDuration))})
+
+		assert.Equal(t, apperrors.NewBadRequest(fmt.Sprintf("gifs can be at most %d seconds long", maxGifDuration/100)), err)
+	})
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"time"
+)
+
+// leaseRepository is data/repository implementation
+// of service layer LeaseRepository.
+// Each lease is a key holding its holder that expires unless the holder renews it.
+type leaseRepository struct {
+	RedisClient *redis.Client
+}
+
+// NewLeaseRepository is a factory for initializing Lease Repositories
+func NewLeaseRepository(rdb *redis.Client) model.LeaseRepository {
+	return &leaseRepository{
+		RedisClient: rdb,
+	}
+}
+
+// acquireLeaseScript renews the lease in KEYS[1] for ARGV[2] milliseconds if ARGV[1] holds it
+// and otherwise takes it over if nobody does
+var acquireLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0
+`)
+
+// Acquire returns true if the holder has the lease for the next ttl
+func (r *leaseRepository) Acquire(name, holder string, ttl time.Duration) (bool, error) {
+	acquired, err := acquireLeaseScript.Run(context.Background(), r.RedisClient, []string{leaseKey(name)}, holder, ttl.Milliseconds()).Int()
+
+	if err != nil {
+		log.Printf("Could not acquire lease: %v. Reason: %v\n", name, err)
+		return false, apperrors.NewInternal()
+	}
+
+	return acquired == 1, nil
+}
+
+func leaseKey(name string) string {
+	return "lease:" + name
+}
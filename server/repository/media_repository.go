@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"github.com/lib/pq"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+// mediaRepository is data/repository implementation
+// of service layer MediaRepository
+type mediaRepository struct {
+	DB *gorm.DB
+}
+
+// NewMediaRepository is a factory for initializing Media Repositories
+func NewMediaRepository(db *gorm.DB) model.MediaRepository {
+	return &mediaRepository{
+		DB: db,
+	}
+}
+
+// Acquire adds a reference to the media for the given ID.
+// It returns nil if the media does not exist yet.
+func (r *mediaRepository) Acquire(id string) (*model.Media, error) {
+	var media []model.Media
+
+	if err := r.DB.
+		Raw("UPDATE media SET refs = refs + 1, updated_at = now() WHERE id = ? AND refs > 0 RETURNING *", id).
+		Scan(&media).Error; err != nil {
+		log.Printf("Could not acquire media: %v. Reason: %v\n", id, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	if len(media) == 0 {
+		return nil, nil
+	}
+
+	return &media[0], nil
+}
+
+// Create inserts the media with a single reference.
+// If the same content got uploaded concurrently the
+// existing media gets another reference instead.
+func (r *mediaRepository) Create(media *model.Media) error {
+	media.Refs = 1
+
+	if err := r.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"refs":       gorm.Expr("media.refs + 1"),
+			"updated_at": gorm.Expr("now()"),
+		}),
+	}).Create(media).Error; err != nil {
+		log.Printf("Could not create media: %v. Reason: %v\n", media.ID, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// Release removes a reference from the media for the given ID.
+// Once the last reference is gone the media gets deleted and deleteObjects
+// removes its objects from the bucket. The row stays locked until then, so the
+// same content can't be acquired or uploaded again while its objects get deleted.
+// Objects that couldn't be deleted are left for the media GC.
+func (r *mediaRepository) Release(id string, deleteObjects func(keys []string) error) error {
+	var deleteErr error
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var media []model.Media
+
+		if err := tx.Raw("SELECT * FROM media WHERE id = ? AND refs > 0 FOR UPDATE", id).Scan(&media).Error; err != nil {
+			return err
+		}
+
+		if len(media) == 0 {
+			return nil
+		}
+
+		if media[0].Refs > 1 {
+			return tx.Exec("UPDATE media SET refs = refs - 1, updated_at = now() WHERE id = ?", id).Error
+		}
+
+		if err := tx.Exec("DELETE FROM media WHERE id = ?", id).Error; err != nil {
+			return err
+		}
+
+		deleteErr = deleteObjects(media[0].Keys)
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Could not release media: %v. Reason: %v\n", id, err)
+		return apperrors.NewInternal()
+	}
+
+	return deleteErr
+}
+
+// AddKeys adds the keys of objects that got uploaded again to the media for the given ID
+func (r *mediaRepository) AddKeys(id string, keys []string) error {
+	if err := r.DB.Exec(
+		"UPDATE media SET keys = ARRAY(SELECT DISTINCT unnest(coalesce(keys, '{}') || ?::text[])), updated_at = now() WHERE id = ?",
+		pq.StringArray(keys), id,
+	).Error; err != nil {
+		log.Printf("Could not add keys to media: %v. Reason: %v\n", id, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// mediaUses counts the files and users using the media m
+const mediaUses = `(
+	(SELECT count(*) FROM files f WHERE f.media_id = m.id) +
+	(SELECT count(*) FROM users u WHERE u.image_media_id = m.id) +
+	(SELECT count(*) FROM users u WHERE u.banner_media_id = m.id)
+)`
+
+// Reconcile corrects the references of the media that hasn't been acquired or released since the given time.
+// Uploads that never became a post or profile image keep a reference nothing accounts for,
+// so media nothing uses gets deleted, which leaves its objects to the GC.
+// It returns the number of reconciled and deleted media.
+func (r *mediaRepository) Reconcile(before time.Time) (int, int, error) {
+	deleted := r.DB.Exec("DELETE FROM media m WHERE m.updated_at < ? AND "+mediaUses+" = 0", before)
+
+	if deleted.Error != nil {
+		log.Printf("Could not delete unused media. Reason: %v\n", deleted.Error)
+		return 0, 0, apperrors.NewInternal()
+	}
+
+	reconciled := r.DB.Exec("UPDATE media m SET refs = "+mediaUses+" WHERE m.updated_at < ? AND m.refs <> "+mediaUses, before)
+
+	if reconciled.Error != nil {
+		log.Printf("Could not reconcile media references. Reason: %v\n", reconciled.Error)
+		return 0, 0, apperrors.NewInternal()
+	}
+
+	return int(reconciled.RowsAffected), int(deleted.RowsAffected), nil
+}
+
+// FindVariants returns the variants of a file using the media for the given ID
+func (r *mediaRepository) FindVariants(id string) ([]model.FileVariant, error) {
+	var variants []model.FileVariant
+
+	if err := r.DB.
+		Where("file_id = (SELECT f.id FROM files f WHERE f.media_id = ? LIMIT 1)", id).
+		Find(&variants).Error; err != nil {
+		log.Printf("Could not find variants for media: %v. Reason: %v\n", id, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	return variants, nil
+}
+
+// FindReferences returns the keys and urls of all objects
+// in the bucket that are still used by a file, media, user or card
+func (r *mediaRepository) FindReferences() ([]string, error) {
+	var refs []string
+
+	if err := r.DB.Raw(`
+		SELECT key FROM file_variants
+		UNION SELECT unnest(keys) FROM media
+		UNION SELECT url FROM files WHERE url IS NOT NULL
+		UNION SELECT image FROM users WHERE image IS NOT NULL
+		UNION SELECT banner FROM users WHERE banner IS NOT NULL
+		UNION SELECT image FROM cards WHERE image IS NOT NULL
+	`).Scan(&refs).Error; err != nil {
+		log.Printf("Could not find media references. Reason: %v\n", err)
+		return nil, apperrors.NewInternal()
+	}
+
+	return refs, nil
+}
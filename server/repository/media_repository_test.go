@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMediaRepository_Reconcile(t *testing.T) {
+	t.Run("Counts the files and users using the media", func(t *testing.T) {
+		db, statements := dryRunDB(t)
+		r := NewMediaRepository(db)
+
+		_, _, err := r.Reconcile(time.Now().Add(-time.Hour))
+
+		assert.NoError(t, err)
+		assert.Len(t, *statements, 2)
+
+		for _, statement := range *statements {
+			assert.Contains(t, statement, "m.updated_at < $1")
+			assert.Contains(t, statement, "f.media_id = m.id")
+			assert.Contains(t, statement, "u.image_media_id = m.id")
+			assert.Contains(t, statement, "u.banner_media_id = m.id")
+		}
+
+		// Unused media gets deleted before the others get reconciled
+		assert.Contains(t, (*statements)[0], "DELETE FROM media m")
+		assert.Contains(t, (*statements)[1], "UPDATE media m SET refs =")
+	})
+}
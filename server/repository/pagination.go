@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/sentrionic/mirage/model"
+	"gorm.io/gorm"
+)
+
+// paginate restricts the query to the page of a list sorted newest first by the time and ID columns.
+// Pages of newer items get fetched oldest first, so they have to be reversed afterwards.
+func paginate(query *gorm.DB, page model.Page, timeColumn, idColumn string) *gorm.DB {
+	direction := "DESC"
+
+	if page.Before != nil {
+		query = query.Where(fmt.Sprintf("(%s, %s) < (?, ?)", timeColumn, idColumn), page.Before.Time, page.Before.ID)
+	}
+
+	if page.After != nil {
+		query = query.Where(fmt.Sprintf("(%s, %s) > (?, ?)", timeColumn, idColumn), page.After.Time, page.After.ID)
+		direction = "ASC"
+	}
+
+	return query.
+		Order(fmt.Sprintf("%s %s, %s %s", timeColumn, direction, idColumn, direction)).
+		Limit(model.LIMIT + 1)
+}
+
+// paginateByRank restricts the query to the page of a list sorted by the rank expression first
+// and newest first after that. Cursors of the page must have a rank.
+func paginateByRank(query *gorm.DB, page model.Page, rankExpression, timeColumn, idColumn string) *gorm.DB {
+	direction := "DESC"
+
+	if page.Before != nil {
+		query = query.Where(fmt.Sprintf("(%s, %s, %s) < (?::numeric, ?, ?)", rankExpression, timeColumn, idColumn), *page.Before.Rank, page.Before.Time, page.Before.ID)
+	}
+
+	if page.After != nil {
+		query = query.Where(fmt.Sprintf("(%s, %s, %s) > (?::numeric, ?, ?)", rankExpression, timeColumn, idColumn), *page.After.Rank, page.After.Time, page.After.ID)
+		direction = "ASC"
+	}
+
+	return query.
+		Order(fmt.Sprintf("%[1]s %[4]s, %[2]s %[4]s, %[3]s %[4]s", rankExpression, timeColumn, idColumn, direction)).
+		Limit(model.LIMIT + 1)
+}
+
+// reverse reverses the items in place
+func reverse[T any](items []T) {
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+}
@@ -3,11 +3,12 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"github.com/lib/pq"
 	"github.com/sentrionic/mirage/model"
 	"github.com/sentrionic/mirage/model/apperrors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"log"
-	"strings"
 )
 
 // postRepository is data/repository implementation
@@ -29,11 +30,13 @@ func (r *postRepository) FindByID(id string) (*model.Post, error) {
 
 	// we need to actually check errors as it could be something other than not found
 	if err := r.DB.
-		Preload("Likes").
-		Preload("Retweets").
+		Preload("User").
 		Preload("File").
-		Preload("User.Followers").
-		Preload("User.Followers").
+		Preload("File.Variants").
+		Preload("Mentions").
+		Preload("Card").
+		Preload("Poll").
+		Preload("Poll.Options", orderPollOptions).
 		Where("id = ?", id).
 		First(&post).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -45,191 +48,544 @@ func (r *postRepository) FindByID(id string) (*model.Post, error) {
 	return post, nil
 }
 
-// Create inserts the post in the DB
+// FindByIDs returns the posts for the given IDs in no particular order.
+// IDs of posts that don't exist anymore are skipped.
+func (r *postRepository) FindByIDs(ids []string) (*[]model.Post, error) {
+	var posts []model.Post
+
+	if len(ids) == 0 {
+		return &posts, nil
+	}
+
+	if err := r.DB.
+		Preload("User").
+		Preload("File").
+		Preload("File.Variants").
+		Preload("Mentions").
+		Preload("Card").
+		Preload("Poll").
+		Preload("Poll.Options", orderPollOptions).
+		Where("id IN ?", ids).
+		Find(&posts).Error; err != nil {
+		log.Printf("Could not find posts. Reason: %v\n", err)
+		return nil, apperrors.NewInternal()
+	}
+
+	return &posts, nil
+}
+
+// Create inserts the post in the DB and counts it as a reply of the post it replies to
 func (r *postRepository) Create(post *model.Post) (*model.Post, error) {
-	if result := r.DB.Create(&post); result.Error != nil {
-		log.Printf("Could not create a post for author: %v. Reason: %v\n", post.UserID, result.Error)
+	if err := r.DB.Transaction(func(tx *gorm.DB) error {
+		// The mentioned users and the card already exist, only the references to them get inserted
+		if err := tx.Omit("Mentions.*", "Card").Create(&post).Error; err != nil {
+			return err
+		}
+
+		return countReply(tx, post, 1)
+	}); err != nil {
+		log.Printf("Could not create a post for author: %v. Reason: %v\n", post.UserID, err)
 		return nil, apperrors.NewInternal()
 	}
 
 	return post, nil
 }
 
+// Update keeps the stored version of the post in its history and saves the edited text,
+// entities and alt text. The post gets locked first, so concurrent edits each keep the version they replaced.
+func (r *postRepository) Update(post *model.Post) error {
+	if err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var current model.Post
+
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("File").
+			Where("id = ?", post.ID).
+			First(&current).Error; err != nil {
+			return err
+		}
+
+		version := current.CurrentVersion()
+		version.ReplacedAt = post.EditedAt
+
+		if err := tx.Create(&version).Error; err != nil {
+			return err
+		}
+
+		if err := tx.
+			Model(&model.Post{}).
+			Where("id = ?", post.ID).
+			Updates(map[string]interface{}{
+				"text":      post.Text,
+				"entities":  post.Entities,
+				"hash_tags": post.HashTags,
+				"card_url":  post.CardURL,
+				"edited_at": post.EditedAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		if post.File != nil {
+			if err := tx.
+				Model(&model.File{}).
+				Where("post_id = ?", post.ID).
+				Update("alt_text", post.File.AltText).Error; err != nil {
+				return err
+			}
+		}
+
+		// The mentioned users already exist, so only the references get replaced
+		if err := tx.Exec("DELETE FROM post_mentions WHERE post_id = ?", post.ID).Error; err != nil {
+			return err
+		}
+
+		for _, user := range post.Mentions {
+			if err := tx.Exec(
+				"INSERT INTO post_mentions (post_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+				post.ID, user.ID,
+			).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		log.Printf("Could not update post: %v. Reason: %v\n", post.ID, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// FindVersions returns the previous versions of the post, newest first
+func (r *postRepository) FindVersions(postId string) ([]model.PostVersion, error) {
+	versions := make([]model.PostVersion, 0)
+
+	if err := r.DB.
+		Where("post_id = ?", postId).
+		Order("replaced_at DESC, id DESC").
+		Find(&versions).Error; err != nil {
+		log.Printf("Could not find versions of post: %v. Reason: %v\n", postId, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	return versions, nil
+}
+
+// Delete removes the post and clears the pin of its author if it is the pinned post.
+// Replies stop counting towards the post they replied to.
 func (r *postRepository) Delete(post *model.Post) error {
-	return r.DB.Delete(&post).Error
+	if err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&post)
+
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if err := countReply(tx, post, -1); err != nil {
+			return err
+		}
+
+		return tx.Exec("UPDATE users SET pinned_post_id = NULL WHERE pinned_post_id = ?", post.ID).Error
+	}); err != nil {
+		log.Printf("Could not delete post: %v. Reason: %v\n", post.ID, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// countReply changes the reply counter of the post the reply replies to
+func countReply(tx *gorm.DB, reply *model.Post, delta int) error {
+	if reply.ReplyToID == nil {
+		return nil
+	}
+
+	return tx.
+		Model(&model.Post{}).
+		Where("id = ?", *reply.ReplyToID).
+		Update("reply_count", gorm.Expr("reply_count + ?", delta)).
+		Error
 }
 
+// AddLike adds the like of the user and updates the post's counter.
+// Liking a post twice doesn't change anything.
 func (r *postRepository) AddLike(post *model.Post, uid string) error {
-	err := r.DB.Table("post_likes").
-		Create(map[string]interface{}{
-			"user_id": uid,
-			"post_id": post.ID,
-		}).Error
-	return err
+	return r.toggle("INSERT INTO post_likes (user_id, post_id) VALUES (?, ?) ON CONFLICT DO NOTHING", "like_count", 1, post.ID, uid)
 }
 
+// RemoveLike removes the like of the user and updates the post's counter
 func (r *postRepository) RemoveLike(post *model.Post, uid string) error {
-	err := r.DB.
-		Exec("DELETE FROM post_likes WHERE user_id = ? AND post_id = ?", uid, post.ID).
-		Error
-	return err
+	return r.toggle("DELETE FROM post_likes WHERE user_id = ? AND post_id = ?", "like_count", -1, post.ID, uid)
 }
 
+// AddRetweet adds the retweet of the user and updates the post's counter.
+// Retweeting a post twice doesn't change anything.
 func (r *postRepository) AddRetweet(post *model.Post, uid string) error {
-	err := r.DB.Table("retweets").
-		Create(map[string]interface{}{
-			"user_id": uid,
-			"post_id": post.ID,
-		}).Error
-	return err
+	return r.toggle("INSERT INTO retweets (user_id, post_id) VALUES (?, ?) ON CONFLICT DO NOTHING", "retweet_count", 1, post.ID, uid)
 }
 
+// RemoveRetweet removes the retweet of the user and updates the post's counter
 func (r *postRepository) RemoveRetweet(post *model.Post, uid string) error {
-	err := r.DB.
-		Exec("DELETE FROM retweets WHERE user_id = ? AND post_id = ?", uid, post.ID).
-		Error
-	return err
+	return r.toggle("DELETE FROM retweets WHERE user_id = ? AND post_id = ?", "retweet_count", -1, post.ID, uid)
+}
+
+// toggle runs the statement for the user and post and only updates
+// the counter column of the post if it changed a row
+func (r *postRepository) toggle(statement, counter string, delta int, postId, uid string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(statement, uid, postId)
+
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		return tx.
+			Model(&model.Post{}).
+			Where("id = ?", postId).
+			Update(counter, gorm.Expr(counter+" + ?", delta)).
+			Error
+	})
 }
 
-func (r *postRepository) Feed(userId, cursor string) (*[]model.Post, error) {
+// AddVote stores the vote of the user and counts it for the chosen options.
+// The vote gets inserted first, so a second vote of the same user conflicts
+// before the poll's row gets locked for its counters.
+func (r *postRepository) AddVote(postId, userId string, choices []int) error {
+	positions := make(pq.Int64Array, 0, len(choices))
+	for _, choice := range choices {
+		positions = append(positions, int64(choice))
+	}
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(
+			"INSERT INTO poll_votes (post_id, user_id, choices, created_at) VALUES (?, ?, ?, now()) ON CONFLICT DO NOTHING",
+			postId, userId, positions,
+		)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return apperrors.NewConflict("vote")
+		}
+
+		// Polls that closed in the meantime don't take the vote
+		result = tx.
+			Model(&model.Poll{}).
+			Where("post_id = ? AND ends_at > now()", postId).
+			Update("voter_count", gorm.Expr("voter_count + 1"))
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return apperrors.NewForbidden("the poll is closed")
+		}
+
+		return tx.
+			Model(&model.PollOption{}).
+			Where("post_id = ? AND position IN ?", postId, []int64(positions)).
+			Update("vote_count", gorm.Expr("vote_count + 1")).
+			Error
+	})
+
+	var e *apperrors.Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	if err != nil {
+		log.Printf("Could not add vote of user: %v to poll: %v. Reason: %v\n", userId, postId, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// LoadViewerState sets if the viewer liked, retweeted or bookmarked each of the posts,
+// if they follow its author, what they voted for in its poll and if its reply policy lets them reply.
+// Authors can always reply to their posts.
+func (r *postRepository) LoadViewerState(viewerId string, posts []*model.Post) error {
+	if viewerId == "" || len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+
+	var rows []struct {
+		ID         string
+		Liked      bool
+		Retweeted  bool
+		Following  bool
+		Bookmarked bool
+		CanReply   bool
+		Choices    pq.Int64Array
+	}
+
+	if err := r.DB.Raw(`
+		SELECT p.id,
+			EXISTS(SELECT 1 FROM post_likes l WHERE l.post_id = p.id AND l.user_id = @viewer) AS liked,
+			EXISTS(SELECT 1 FROM retweets r WHERE r.post_id = p.id AND r.user_id = @viewer) AS retweeted,
+			EXISTS(SELECT 1 FROM followers f WHERE f.user_id = p.user_id AND f.follower_id = @viewer) AS following,
+			EXISTS(SELECT 1 FROM bookmarks b WHERE b.post_id = p.id AND b.user_id = @viewer) AS bookmarked,
+			(p.user_id = @viewer OR p.reply_policy = @everyone
+				OR (p.reply_policy = @following AND EXISTS(SELECT 1 FROM followers f WHERE f.user_id = @viewer AND f.follower_id = p.user_id))
+				OR (p.reply_policy = @mentioned AND EXISTS(SELECT 1 FROM post_mentions m WHERE m.post_id = p.id AND m.user_id = @viewer))
+			) AS can_reply,
+			(SELECT v.choices FROM poll_votes v WHERE v.post_id = p.id AND v.user_id = @viewer) AS choices
+		FROM posts p
+		WHERE p.id IN @ids
+	`,
+		sql.Named("viewer", viewerId),
+		sql.Named("ids", ids),
+		sql.Named("everyone", model.ReplyEveryone),
+		sql.Named("following", model.ReplyFollowing),
+		sql.Named("mentioned", model.ReplyMentioned),
+	).Scan(&rows).Error; err != nil {
+		log.Printf("Could not load post state for viewer: %v. Reason: %v\n", viewerId, err)
+		return apperrors.NewInternal()
+	}
+
+	for _, row := range rows {
+		for _, post := range posts {
+			if post.ID == row.ID {
+				post.Liked = row.Liked
+				post.Retweeted = row.Retweeted
+				post.User.Following = row.Following
+				post.Bookmarked = row.Bookmarked
+				post.CanReply = row.CanReply
+				setPollViewerState(post, viewerId, row.Choices)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *postRepository) Likes(id string, page model.Page) (*[]model.Post, error) {
 	var posts []model.Post
+
 	query := r.DB.
-		Preload("Likes").
-		Preload("Retweets").
+		Preload("User").
 		Preload("File").
-		Preload("User.Followers").
-		Preload("User.Followers").
-		Joins("LEFT JOIN followers on \"posts\".user_id = followers.user_id").
-		Joins("LEFT JOIN retweets r on \"posts\".id = r.post_id").
-		Where(`
-			("posts".user_id = @id
-			OR followers.follower_id = @id
-			OR r.user_id = @id
-			OR r.user_id IN (
-				SELECT id
-				from "users"
-				join followee f on "users".id = f.followee_id
-				WHERE f.user_id = @id
-			))
-		`, sql.Named("id", userId))
-
-	if cursor != "" {
-		cursor = strings.Replace(cursor, " ", "+", 1)
-		query.
-			Where("\"posts\".created_at::timestamptz < ?", cursor)
-	}
-
-	query.
-		Order("(CASE when r.created_at notnull THEN r.created_at ELSE \"posts\".created_at end) DESC").
-		Limit(model.LIMIT + 1).
-		Find(&posts)
-
-	return &posts, query.Error
-}
-
-func (r *postRepository) List(id, cursor string) (*[]model.Post, error) {
+		Preload("File.Variants").
+		Preload("Mentions").
+		Preload("Card").
+		Preload("Poll").
+		Preload("Poll.Options", orderPollOptions).
+		Joins("LEFT JOIN post_likes pl on \"posts\".id = pl.post_id").
+		Where("pl.user_id = ?", id)
+
+	if err := paginate(query, page, "\"posts\".created_at", "\"posts\".id").Find(&posts).Error; err != nil {
+		return nil, err
+	}
+
+	if page.After != nil {
+		reverse(posts)
+	}
+
+	return &posts, nil
+}
+
+// Search finds the posts matching the text of the query using web search syntax
+// and all of its filters.
+// Hashtags match as words, as the text search parser drops the #.
+// Sorted by relevance, the rank is rounded, so it compares exactly in cursors.
+func (r *postRepository) Search(q model.PostQuery, page model.Page) (*[]model.Post, error) {
 	var posts []model.Post
 
 	query := r.DB.
-		Preload("Likes").
-		Preload("Retweets").
+		Preload("User").
 		Preload("File").
-		Preload("User.Followers").
-		Preload("User.Followers").
-		Joins("LEFT JOIN retweets r on \"posts\".id = r.post_id").
-		Where("(\"posts\".user_id = @id OR r.user_id IN (SELECT id from \"users\" join followee f on \"users\".id = f.followee_id WHERE f.user_id = @id))", sql.Named("id", id))
+		Preload("File.Variants").
+		Preload("Mentions").
+		Preload("Card").
+		Preload("Poll").
+		Preload("Poll.Options", orderPollOptions)
+
+	if q.Text != "" {
+		query = query.
+			Joins("CROSS JOIN websearch_to_tsquery('english', ?) AS query", q.Text).
+			Where("\"posts\".search_vector @@ query")
+	}
+
+	if q.IsRetweet {
+		query = query.Where(`EXISTS (
+			SELECT 1 FROM retweets rt JOIN users ru ON ru.id = rt.user_id
+			WHERE rt.post_id = "posts".id AND lower(ru.username) = lower(?)
+		)`, q.From)
+	} else if q.From != "" {
+		query = query.Where("\"posts\".user_id IN (SELECT id FROM users WHERE lower(username) = lower(?))", q.From)
+	}
+
+	if q.To != "" {
+		query = query.Where(`EXISTS (
+			SELECT 1 FROM post_mentions pm JOIN users mu ON mu.id = pm.user_id
+			WHERE pm.post_id = "posts".id AND lower(mu.username) = lower(?)
+		)`, q.To)
+	}
+
+	if q.Since != nil {
+		query = query.Where("\"posts\".created_at >= ?", *q.Since)
+	}
+
+	if q.Until != nil {
+		query = query.Where("\"posts\".created_at < ?", *q.Until)
+	}
+
+	if q.HasMedia {
+		query = query.Where("EXISTS (SELECT 1 FROM files f WHERE f.post_id = \"posts\".id)")
+	}
 
-	if cursor != "" {
-		cursor = strings.Replace(cursor, " ", "+", 1)
-		query.
-			Where("\"posts\".created_at::timestamptz < ?", cursor)
+	if q.MinLikes > 0 {
+		query = query.Where("\"posts\".like_count >= ?", q.MinLikes)
 	}
 
-	query.
-		Order("(CASE when r.created_at notnull THEN r.created_at ELSE \"posts\".created_at end) DESC").
-		Limit(model.LIMIT + 1).
-		Find(&posts)
+	if q.ExcludeReplies {
+		query = query.Where("\"posts\".reply_to_id IS NULL")
+	}
+
+	if q.Sort == model.SortRelevance && q.Text != "" {
+		rank := "round(ts_rank(\"posts\".search_vector, query)::numeric, 6)"
+		query = paginateByRank(query.Select("\"posts\".*, "+rank+" AS search_rank"), page, rank, "\"posts\".created_at", "\"posts\".id")
+	} else {
+		query = paginate(query, page, "\"posts\".created_at", "\"posts\".id")
+	}
 
-	return &posts, query.Error
+	if err := query.Find(&posts).Error; err != nil {
+		log.Printf("Could not search posts for query: %+v. Reason: %v\n", q, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	if page.After != nil {
+		reverse(posts)
+	}
+
+	return &posts, nil
 }
 
-func (r *postRepository) Likes(id, cursor string) (*[]model.Post, error) {
+func (r *postRepository) Media(id string, page model.Page) (*[]model.Post, error) {
 	var posts []model.Post
 
 	query := r.DB.
-		Preload("Likes").
-		Preload("Retweets").
+		Preload("User").
 		Preload("File").
-		Preload("User.Followers").
-		Preload("User.Followers").
-		Joins("LEFT JOIN post_likes pl on \"posts\".id = pl.post_id").
-		Where("pl.user_id = ?", id)
+		Preload("File.Variants").
+		Preload("Mentions").
+		Preload("Card").
+		Preload("Poll").
+		Preload("Poll.Options", orderPollOptions).
+		Joins("LEFT JOIN files f on \"posts\".id = f.post_id").
+		Where("\"posts\".user_id = ? AND f IS NOT NULL", id)
 
-	if cursor != "" {
-		cursor = strings.Replace(cursor, " ", "+", 1)
-		query.
-			Where("created_at::timestamptz < ?", cursor)
+	if err := paginate(query, page, "\"posts\".created_at", "\"posts\".id").Find(&posts).Error; err != nil {
+		return nil, err
 	}
 
-	query.
-		Order("created_at DESC").
-		Limit(model.LIMIT + 1).
-		Find(&posts)
+	if page.After != nil {
+		reverse(posts)
+	}
 
-	return &posts, query.Error
+	return &posts, nil
 }
 
-func (r *postRepository) GetPostsForHashtag(term, cursor string) (*[]model.Post, error) {
-	posts := &[]model.Post{}
-
-	if !strings.HasPrefix(term, "#") {
-		term = "#" + term
-	}
+// Mentions returns the posts mentioning the user, newest first
+func (r *postRepository) Mentions(userId string, page model.Page) (*[]model.Post, error) {
+	var posts []model.Post
 
 	query := r.DB.
-		Preload("Likes").
-		Preload("Retweets").
+		Preload("User").
 		Preload("File").
-		Preload("User.Followers").
-		Preload("User.Followers").
-		Joins("LEFT JOIN users u ON u.id = \"posts\".user_id").
-		Where("@term ILIKE ANY (\"posts\".hash_tags)", sql.Named("term", strings.ToLower(term)))
+		Preload("File.Variants").
+		Preload("Mentions").
+		Preload("Card").
+		Preload("Poll").
+		Preload("Poll.Options", orderPollOptions).
+		Joins("JOIN post_mentions pm on \"posts\".id = pm.post_id").
+		Where("pm.user_id = ?", userId)
+
+	if err := paginate(query, page, "\"posts\".created_at", "\"posts\".id").Find(&posts).Error; err != nil {
+		log.Printf("Could not find mentions of user: %v. Reason: %v\n", userId, err)
+		return nil, apperrors.NewInternal()
+	}
 
-	if cursor != "" {
-		cursor = strings.Replace(cursor, " ", "+", 1)
-		query.Where("created_at::timestamptz < ?", cursor)
+	if page.After != nil {
+		reverse(posts)
 	}
 
-	query.
-		Order("created_at DESC").
-		Limit(model.LIMIT + 1).
-		Find(&posts)
+	return &posts, nil
+}
+
+// SetHidden folds the reply away in the thread of the post it replies to or shows it again
+func (r *postRepository) SetHidden(reply *model.Post, hidden bool) error {
+	result := r.DB.
+		Model(&model.Post{}).
+		Where("id = ? AND reply_to_id IS NOT NULL", reply.ID).
+		Update("hidden", hidden)
 
-	return posts, query.Error
+	if result.Error != nil {
+		log.Printf("Could not change hidden status of reply: %v. Reason: %v\n", reply.ID, result.Error)
+		return apperrors.NewInternal()
+	}
+
+	if result.RowsAffected == 0 {
+		return apperrors.NewNotFound("reply", reply.ID)
+	}
+
+	return nil
 }
 
-func (r *postRepository) Media(id, cursor string) (*[]model.Post, error) {
+// Replies returns either the visible or the hidden replies to the post, newest first
+func (r *postRepository) Replies(postId string, hidden bool, page model.Page) (*[]model.Post, error) {
 	var posts []model.Post
 
 	query := r.DB.
-		Preload("Likes").
-		Preload("Retweets").
+		Preload("User").
 		Preload("File").
-		Preload("User.Followers").
-		Preload("User.Followers").
-		Joins("LEFT JOIN files f on \"posts\".id = f.post_id").
-		Where("\"posts\".user_id = ? AND f IS NOT NULL", id)
+		Preload("File.Variants").
+		Preload("Mentions").
+		Preload("Card").
+		Preload("Poll").
+		Preload("Poll.Options", orderPollOptions).
+		Where("\"posts\".reply_to_id = ? AND \"posts\".hidden = ?", postId, hidden)
+
+	if err := paginate(query, page, "\"posts\".created_at", "\"posts\".id").Find(&posts).Error; err != nil {
+		log.Printf("Could not find replies to post: %v. Reason: %v\n", postId, err)
+		return nil, apperrors.NewInternal()
+	}
 
-	if cursor != "" {
-		cursor = strings.Replace(cursor, " ", "+", 1)
-		query.
-			Where("created_at::timestamptz < ?", cursor)
+	if page.After != nil {
+		reverse(posts)
 	}
 
-	query.
-		Order("created_at DESC").
-		Limit(model.LIMIT + 1).
-		Find(&posts)
+	return &posts, nil
+}
 
-	return &posts, query.Error
+// orderPollOptions preloads the options of polls in the order they got created
+func orderPollOptions(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+// setPollViewerState sets the viewer's choices of the post's poll.
+// Authors always see the results of their polls.
+func setPollViewerState(post *model.Post, viewerId string, choices pq.Int64Array) {
+	if post.Poll == nil {
+		return
+	}
+
+	post.Poll.ShowResults = post.UserID == viewerId
+	post.Poll.Choices = nil
+
+	if choices != nil {
+		post.Poll.Choices = make([]int, 0, len(choices))
+		for _, choice := range choices {
+			post.Poll.Choices = append(post.Poll.Choices, int(choice))
+		}
+	}
 }
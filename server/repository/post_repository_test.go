@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCountReply(t *testing.T) {
+	t.Run("Counts the reply on the replied to post", func(t *testing.T) {
+		db, statements := dryRunDB(t)
+
+		reply := fixture.GetMockPost()
+		parent := fixture.RandID()
+		reply.ReplyToID = &parent
+
+		err := countReply(db, reply, -1)
+
+		assert.NoError(t, err)
+		assert.Len(t, *statements, 1)
+
+		statement := (*statements)[0]
+		assert.Contains(t, statement, `"reply_count"=reply_count + $1`)
+		assert.Contains(t, statement, `WHERE id = $2`)
+	})
+
+	t.Run("Posts that aren't replies count nothing", func(t *testing.T) {
+		db, statements := dryRunDB(t)
+
+		err := countReply(db, fixture.GetMockPost(), 1)
+
+		assert.NoError(t, err)
+		assert.Empty(t, *statements)
+	})
+}
+
+func TestPostRepository_Search(t *testing.T) {
+	t.Run("Matches mentions by their user", func(t *testing.T) {
+		db, statements := dryRunDB(t)
+		r := NewPostRepository(db)
+
+		_, err := r.Search(model.PostQuery{To: "bob", Sort: model.SortRecent}, model.Page{})
+
+		assert.NoError(t, err)
+		assert.Len(t, *statements, 1)
+
+		statement := (*statements)[0]
+		assert.Contains(t, statement, "FROM post_mentions pm JOIN users mu ON mu.id = pm.user_id")
+		assert.NotContains(t, statement, "~*")
+	})
+
+	t.Run("Matches retweets of the user", func(t *testing.T) {
+		db, statements := dryRunDB(t)
+		r := NewPostRepository(db)
+
+		_, err := r.Search(model.PostQuery{From: "alice", IsRetweet: true, Sort: model.SortRecent}, model.Page{})
+
+		assert.NoError(t, err)
+		assert.Len(t, *statements, 1)
+
+		statement := (*statements)[0]
+		assert.Contains(t, statement, "FROM retweets rt JOIN users ru ON ru.id = rt.user_id")
+		assert.NotContains(t, statement, `"posts".user_id IN`)
+	})
+}
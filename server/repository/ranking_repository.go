@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// rankingRepository is data/repository implementation
+// of service layer RankingRepository.
+// Candidates come from Postgres, the rankings of sessions get cached in Redis.
+type rankingRepository struct {
+	DB          *gorm.DB
+	RedisClient *redis.Client
+}
+
+// NewRankingRepository is a factory for initializing Ranking Repositories
+func NewRankingRepository(db *gorm.DB, rdb *redis.Client) model.RankingRepository {
+	return &rankingRepository{
+		DB:          db,
+		RedisClient: rdb,
+	}
+}
+
+// FindFollowingCandidates returns the newest posts of users the given user follows
+func (r *rankingRepository) FindFollowingCandidates(userId string, since time.Time, limit int) ([]model.RankingCandidate, error) {
+	return r.findCandidates(model.SourceFollowing, `
+		SELECT p.id AS post_id, p.user_id AS author_id, p.like_count, p.retweet_count, p.created_at
+		FROM posts p
+		JOIN followee f ON f.followee_id = p.user_id
+		WHERE f.user_id = @user AND p.created_at > @since
+		ORDER BY p.created_at DESC
+		LIMIT @limit
+	`, userId, since, limit)
+}
+
+// FindNetworkCandidates returns the most engaged with posts of users
+// followed by the users the given user follows
+func (r *rankingRepository) FindNetworkCandidates(userId string, since time.Time, limit int) ([]model.RankingCandidate, error) {
+	return r.findCandidates(model.SourceNetwork, `
+		SELECT p.id AS post_id, p.user_id AS author_id, p.like_count, p.retweet_count, p.created_at
+		FROM posts p
+		WHERE p.created_at > @since
+		AND p.user_id <> @user
+		AND p.user_id IN (
+			SELECT second.followee_id FROM followee first
+			JOIN followee second ON second.user_id = first.followee_id
+			WHERE first.user_id = @user
+		)
+		AND p.user_id NOT IN (SELECT followee_id FROM followee WHERE user_id = @user)
+		ORDER BY p.like_count + p.retweet_count DESC, p.created_at DESC
+		LIMIT @limit
+	`, userId, since, limit)
+}
+
+// FindHashtagCandidates returns the most engaged with posts using
+// the hashtags that were used by the most authors since the given time
+func (r *rankingRepository) FindHashtagCandidates(userId string, since time.Time, limit int) ([]model.RankingCandidate, error) {
+	return r.findCandidates(model.SourceHashtag, `
+		WITH popular AS (
+			SELECT lower(tag) AS tag FROM posts, unnest(hash_tags) AS tag
+			WHERE created_at > @since
+			GROUP BY lower(tag)
+			ORDER BY count(DISTINCT user_id) DESC
+			LIMIT @tags
+		)
+		SELECT p.id AS post_id, p.user_id AS author_id, p.like_count, p.retweet_count, p.created_at
+		FROM posts p
+		WHERE p.created_at > @since
+		AND p.user_id <> @user
+		AND EXISTS (SELECT 1 FROM unnest(p.hash_tags) AS tag WHERE lower(tag) IN (SELECT tag FROM popular))
+		ORDER BY p.like_count + p.retweet_count DESC, p.created_at DESC
+		LIMIT @limit
+	`, userId, since, limit)
+}
+
+func (r *rankingRepository) findCandidates(source, query, userId string, since time.Time, limit int) ([]model.RankingCandidate, error) {
+	candidates := make([]model.RankingCandidate, 0)
+
+	if err := r.DB.Raw(query, map[string]interface{}{
+		"user":  userId,
+		"since": since,
+		"limit": limit,
+		"tags":  model.PopularHashtags,
+	}).Scan(&candidates).Error; err != nil {
+		log.Printf("Could not find %s candidates for user: %v. Reason: %v\n", source, userId, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	for i := range candidates {
+		candidates[i].Source = source
+	}
+
+	return candidates, nil
+}
+
+// FindAffinities returns how often the given user liked or retweeted
+// posts of each of the given authors
+func (r *rankingRepository) FindAffinities(userId string, authorIds []string) (map[string]int, error) {
+	affinities := make(map[string]int)
+
+	if len(authorIds) == 0 {
+		return affinities, nil
+	}
+
+	var rows []struct {
+		AuthorID     string
+		Interactions int
+	}
+
+	if err := r.DB.Raw(`
+		SELECT p.user_id AS author_id, count(*) AS interactions FROM (
+			SELECT post_id FROM post_likes WHERE user_id = @user
+			UNION ALL
+			SELECT post_id FROM retweets WHERE user_id = @user
+		) interactions
+		JOIN posts p ON p.id = interactions.post_id
+		WHERE p.user_id IN @authors
+		GROUP BY p.user_id
+	`, map[string]interface{}{
+		"user":    userId,
+		"authors": authorIds,
+	}).Scan(&rows).Error; err != nil {
+		log.Printf("Could not find affinities for user: %v. Reason: %v\n", userId, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	for _, row := range rows {
+		affinities[row.AuthorID] = row.Interactions
+	}
+
+	return affinities, nil
+}
+
+// SaveSession stores the rankings of a session for model.ForYouSessionTTL
+func (r *rankingRepository) SaveSession(userId, sessionId string, rankings []model.Ranking) error {
+	data, err := json.Marshal(rankings)
+
+	if err != nil {
+		log.Printf("Could not encode rankings for session: %v. Reason: %v\n", sessionId, err)
+		return apperrors.NewInternal()
+	}
+
+	if err := r.RedisClient.Set(context.Background(), sessionKey(userId, sessionId), data, model.ForYouSessionTTL).Err(); err != nil {
+		log.Printf("Could not save rankings for session: %v. Reason: %v\n", sessionId, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// FindSession returns the rankings of a session of the given user.
+// Expired sessions are not found.
+func (r *rankingRepository) FindSession(userId, sessionId string) ([]model.Ranking, error) {
+	data, err := r.RedisClient.Get(context.Background(), sessionKey(userId, sessionId)).Bytes()
+
+	if errors.Is(err, redis.Nil) {
+		return nil, apperrors.NewNotFound("session", sessionId)
+	}
+
+	if err != nil {
+		log.Printf("Could not get rankings for session: %v. Reason: %v\n", sessionId, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	var rankings []model.Ranking
+	if err := json.Unmarshal(data, &rankings); err != nil {
+		log.Printf("Could not decode rankings for session: %v. Reason: %v\n", sessionId, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	return rankings, nil
+}
+
+func sessionKey(userId, sessionId string) string {
+	return "foryou:" + userId + ":" + sessionId
+}
@@ -0,0 +1,570 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// timelineRepository is data/repository implementation
+// of service layer TimelineRepository.
+// Timelines are stored in Redis as sorted sets of post IDs
+// scored by the unix milliseconds of the post or retweet.
+// Each post is only stored once, at its newest position, and a hash
+// next to the set holds the retweeting user of entries that are retweets.
+// Timelines that hold all of their user's entries contain the timelineEnd member scored 0,
+// so empty timelines exist as well. Trimming a timeline removes it first.
+type timelineRepository struct {
+	DB          *gorm.DB
+	RedisClient *redis.Client
+}
+
+// NewTimelineRepository is a factory for initializing Timeline Repositories
+func NewTimelineRepository(db *gorm.DB, rdb *redis.Client) model.TimelineRepository {
+	return &timelineRepository{
+		DB:          db,
+		RedisClient: rdb,
+	}
+}
+
+// addScript only adds entries to timelines that already exist,
+// as a partial timeline would never get rebuilt from the database.
+// Posts already in the timeline only move up, never down, and take over
+// the retweeting user of the newer entry.
+// The timeline gets trimmed to the size in ARGV[1] afterwards.
+var addScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+for i = 2, #ARGV, 3 do
+	local current = redis.call("ZSCORE", KEYS[1], ARGV[i + 1])
+	if not current or tonumber(current) < tonumber(ARGV[i]) then
+		redis.call("ZADD", KEYS[1], ARGV[i], ARGV[i + 1])
+		if ARGV[i + 2] == "" then
+			redis.call("HDEL", KEYS[2], ARGV[i + 1])
+		else
+			redis.call("HSET", KEYS[2], ARGV[i + 1], ARGV[i + 2])
+		end
+	end
+end
+local trimmed = redis.call("ZRANGE", KEYS[1], 0, -tonumber(ARGV[1]) - 1)
+if #trimmed > 0 then
+	redis.call("ZREM", KEYS[1], unpack(trimmed))
+	redis.call("HDEL", KEYS[2], unpack(trimmed))
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl > 0 then
+	redis.call("PEXPIRE", KEYS[2], ttl)
+end
+return 1
+`)
+
+// removeRetweetScript removes the post in ARGV[1] only if
+// the entry is the retweet of the user in ARGV[2]
+var removeRetweetScript = redis.NewScript(`
+if redis.call("HGET", KEYS[2], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+redis.call("ZREM", KEYS[1], ARGV[1])
+redis.call("HDEL", KEYS[2], ARGV[1])
+return 1
+`)
+
+// Add writes the entries to the existing timelines of the given users
+func (r *timelineRepository) Add(userIds []string, entries ...model.TimelineEntry) error {
+	if len(userIds) == 0 || len(entries) == 0 {
+		return nil
+	}
+
+	args := []interface{}{model.TimelineSize}
+	for _, entry := range entries {
+		args = append(args, score(entry.CreatedAt), entry.PostID, entry.RetweetedBy)
+	}
+
+	ctx := context.Background()
+	_, err := r.RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range userIds {
+			addScript.Eval(ctx, pipe, []string{timelineKey(id), retweetsKey(id)}, args...)
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Could not add entries to timelines. Reason: %v\n", err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// Remove removes the posts from the timelines of the given users
+func (r *timelineRepository) Remove(userIds []string, postIds ...string) error {
+	if len(userIds) == 0 || len(postIds) == 0 {
+		return nil
+	}
+
+	members := make([]interface{}, 0, len(postIds))
+	fields := make([]string, 0, len(postIds))
+	for _, id := range postIds {
+		members = append(members, id)
+		fields = append(fields, id)
+	}
+
+	ctx := context.Background()
+	_, err := r.RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range userIds {
+			pipe.ZRem(ctx, timelineKey(id), members...)
+			pipe.HDel(ctx, retweetsKey(id), fields...)
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Could not remove posts from timelines. Reason: %v\n", err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// RemoveRetweet removes the post from the timelines of the given users
+// where it is shown as retweeted by the given retweeter
+func (r *timelineRepository) RemoveRetweet(userIds []string, postId, retweeterId string) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	_, err := r.RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range userIds {
+			removeRetweetScript.Eval(ctx, pipe, []string{timelineKey(id), retweetsKey(id)}, postId, retweeterId)
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Could not remove retweet from timelines: %v. Reason: %v\n", postId, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// RemoveAuthor removes the posts and retweets of the author from the timeline of the user.
+// Posts the remaining authors posted or retweeted as well move back to their newest entry among them.
+func (r *timelineRepository) RemoveAuthor(userId, authorId string, remaining []string) error {
+	key := timelineKey(userId)
+	retweets := retweetsKey(userId)
+
+	members, err := r.Members(userId)
+
+	if err != nil || len(members) == 0 {
+		return err
+	}
+
+	ctx := context.Background()
+	retweeters, err := r.RedisClient.HMGet(ctx, retweets, members...).Result()
+
+	if err != nil {
+		log.Printf("Could not read timeline retweets for user: %v. Reason: %v\n", userId, err)
+		return apperrors.NewInternal()
+	}
+
+	// Retweets by other users stay, original posts depend on their author
+	removed := make([]string, 0)
+	originals := make([]string, 0)
+	for i, retweeter := range retweeters {
+		id, ok := retweeter.(string)
+		switch {
+		case !ok:
+			originals = append(originals, members[i])
+		case id == authorId:
+			removed = append(removed, members[i])
+		}
+	}
+
+	if len(originals) > 0 {
+		var authored []string
+
+		if err := r.DB.
+			Raw("SELECT id FROM posts WHERE id IN ? AND user_id = ?", originals, authorId).
+			Scan(&authored).Error; err != nil {
+			log.Printf("Could not find posts of author: %v. Reason: %v\n", authorId, err)
+			return apperrors.NewInternal()
+		}
+
+		removed = append(removed, authored...)
+	}
+
+	if len(removed) == 0 {
+		return nil
+	}
+
+	entries := make([]model.TimelineEntry, 0)
+	if len(remaining) > 0 {
+		if err := r.DB.Raw(`
+			SELECT DISTINCT ON (post_id) post_id, retweeted_by, created_at FROM (
+				SELECT p.id AS post_id, '' AS retweeted_by, date_trunc('milliseconds', p.created_at) AS created_at
+				FROM posts p WHERE p.id IN @posts AND p.user_id IN @ids
+				UNION ALL
+				SELECT r.post_id, r.user_id AS retweeted_by, date_trunc('milliseconds', r.created_at) AS created_at
+				FROM retweets r WHERE r.post_id IN @posts AND r.user_id IN @ids
+			) entries
+			ORDER BY post_id, created_at DESC, retweeted_by DESC
+		`, map[string]interface{}{"posts": removed, "ids": remaining}).Scan(&entries).Error; err != nil {
+			log.Printf("Could not find remaining timeline entries for user: %v. Reason: %v\n", userId, err)
+			return apperrors.NewInternal()
+		}
+	}
+
+	stale := make([]interface{}, 0, len(removed))
+	for _, id := range removed {
+		stale = append(stale, id)
+	}
+
+	_, err = r.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, key, stale...)
+		pipe.HDel(ctx, retweets, removed...)
+		for _, entry := range entries {
+			pipe.ZAdd(ctx, key, &redis.Z{Score: score(entry.CreatedAt), Member: entry.PostID})
+			if entry.RetweetedBy != "" {
+				pipe.HSet(ctx, retweets, entry.PostID, entry.RetweetedBy)
+				pipe.Expire(ctx, retweets, model.TimelineTTL)
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Could not remove author from timeline for user: %v. Reason: %v\n", userId, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// Replace overwrites the timeline of the given user with the entries.
+// Complete timelines hold all entries of the user, even if there are none.
+func (r *timelineRepository) Replace(userId string, entries []model.TimelineEntry, complete bool) error {
+	key := timelineKey(userId)
+	retweets := retweetsKey(userId)
+
+	members := make([]*redis.Z, 0, len(entries)+1)
+	if complete {
+		members = append(members, &redis.Z{Score: 0, Member: timelineEnd})
+	}
+	retweeters := make(map[string]interface{})
+	for _, entry := range entries {
+		members = append(members, &redis.Z{Score: score(entry.CreatedAt), Member: entry.PostID})
+		if entry.RetweetedBy != "" {
+			retweeters[entry.PostID] = entry.RetweetedBy
+		}
+	}
+
+	ctx := context.Background()
+	_, err := r.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key, retweets)
+		if len(members) > 0 {
+			pipe.ZAdd(ctx, key, members...)
+			pipe.Expire(ctx, key, model.TimelineTTL)
+		}
+		if len(retweeters) > 0 {
+			pipe.HSet(ctx, retweets, retweeters)
+			pipe.Expire(ctx, retweets, model.TimelineTTL)
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Could not replace timeline for user: %v. Reason: %v\n", userId, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// Range returns up to limit entries of the user's timeline next to the cursor of the page.
+// The second return value reports if the timeline exists at all.
+func (r *timelineRepository) Range(userId string, page model.Page, limit int) ([]model.TimelineEntry, bool, error) {
+	key := timelineKey(userId)
+	retweets := retweetsKey(userId)
+
+	ctx := context.Background()
+	pipe := r.RedisClient.Pipeline()
+	exists := pipe.Exists(ctx, key)
+
+	// Entries with the same score as the cursor get fetched separately and compared by their ID
+	var ties, ranged *redis.ZSliceCmd
+	switch {
+	case page.Before != nil:
+		at := fmt.Sprint(page.Before.Time.UnixMilli())
+		ties = pipe.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{Min: at, Max: at})
+		ranged = pipe.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{Min: "-inf", Max: "(" + at, Count: int64(limit)})
+	case page.After != nil:
+		at := fmt.Sprint(page.After.Time.UnixMilli())
+		ties = pipe.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{Min: at, Max: at})
+		ranged = pipe.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{Min: "(" + at, Max: "+inf", Count: int64(limit)})
+	default:
+		ranged = pipe.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{Min: "-inf", Max: "+inf", Count: int64(limit)})
+	}
+	pipe.Expire(ctx, key, model.TimelineTTL)
+	pipe.Expire(ctx, retweets, model.TimelineTTL)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Could not read timeline for user: %v. Reason: %v\n", userId, err)
+		return nil, false, apperrors.NewInternal()
+	}
+
+	members := make([]redis.Z, 0)
+	if ties != nil {
+		for _, z := range ties.Val() {
+			id := z.Member.(string)
+			if (page.Before != nil && id < page.Before.ID) || (page.After != nil && id > page.After.ID) {
+				members = append(members, z)
+			}
+		}
+	}
+	for _, z := range ranged.Val() {
+		if z.Member.(string) != timelineEnd {
+			members = append(members, z)
+		}
+	}
+
+	if len(members) > limit {
+		members = members[:limit]
+	}
+	if page.After != nil {
+		reverse(members)
+	}
+
+	entries := make([]model.TimelineEntry, 0, len(members))
+	ids := make([]string, 0, len(members))
+	for _, z := range members {
+		entries = append(entries, model.TimelineEntry{
+			PostID:    z.Member.(string),
+			CreatedAt: time.UnixMilli(int64(z.Score)),
+		})
+		ids = append(ids, z.Member.(string))
+	}
+
+	if len(ids) == 0 {
+		return entries, exists.Val() == 1, nil
+	}
+
+	retweeters, err := r.RedisClient.HMGet(ctx, retweets, ids...).Result()
+
+	if err != nil {
+		log.Printf("Could not read timeline retweets for user: %v. Reason: %v\n", userId, err)
+		return nil, false, apperrors.NewInternal()
+	}
+
+	for i, retweeter := range retweeters {
+		if id, ok := retweeter.(string); ok {
+			entries[i].RetweetedBy = id
+		}
+	}
+
+	return entries, exists.Val() == 1, nil
+}
+
+// IsComplete reports if the user's timeline still holds all of its older entries.
+// Timelines that got trimmed to their size miss the oldest ones.
+func (r *timelineRepository) IsComplete(userId string) (bool, error) {
+	err := r.RedisClient.ZScore(context.Background(), timelineKey(userId), timelineEnd).Err()
+
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+
+	if err != nil {
+		log.Printf("Could not read timeline end for user: %v. Reason: %v\n", userId, err)
+		return false, apperrors.NewInternal()
+	}
+
+	return true, nil
+}
+
+// Members returns the IDs of all posts in the user's timeline
+func (r *timelineRepository) Members(userId string) ([]string, error) {
+	members, err := r.RedisClient.ZRangeByScore(context.Background(), timelineKey(userId), &redis.ZRangeBy{Min: "(0", Max: "+inf"}).Result()
+
+	if err != nil {
+		log.Printf("Could not read timeline members for user: %v. Reason: %v\n", userId, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	return members, nil
+}
+
+// FindEntries returns the posts and retweets of the given authors next to the cursor of the page.
+// A post posted or retweeted several times only shows up once, at its newest entry. Posts in exclude get skipped.
+// Times get truncated to milliseconds to match the scores of cached timelines.
+func (r *timelineRepository) FindEntries(authorIds []string, page model.Page, limit int, exclude []string) ([]model.TimelineEntry, error) {
+	entries := make([]model.TimelineEntry, 0)
+
+	if len(authorIds) == 0 || limit <= 0 {
+		return entries, nil
+	}
+
+	args := map[string]interface{}{
+		"ids":    authorIds,
+		"limit":  limit,
+		"offset": limit - 1,
+	}
+
+	direction := "DESC"
+	if page.Before != nil {
+		args["time"] = page.Before.Time.Truncate(time.Millisecond)
+		args["until"] = page.Before.Time.Truncate(time.Millisecond).Add(time.Millisecond)
+		args["id"] = page.Before.ID
+	}
+	if page.After != nil {
+		args["time"] = page.After.Time.Truncate(time.Millisecond)
+		args["id"] = page.After.ID
+		direction = "ASC"
+	}
+	if len(exclude) > 0 {
+		args["exclude"] = exclude
+	}
+
+	// Each branch only keeps the newest entry of a post, so they don't have to be collapsed
+	posts := entryConditions(page, exclude, "p.created_at", "p.id",
+		"p.user_id IN @ids AND NOT EXISTS(SELECT 1 FROM retweets n WHERE n.post_id = p.id AND n.user_id IN @ids)")
+	retweets := entryConditions(page, exclude, "r.created_at", "r.post_id", `r.user_id IN @ids AND NOT EXISTS(
+		SELECT 1 FROM retweets n WHERE n.post_id = r.post_id AND n.user_id IN @ids AND (n.created_at, n.user_id) > (r.created_at, r.user_id)
+	)`)
+
+	if err := r.DB.Raw(fmt.Sprintf(`
+		SELECT post_id, retweeted_by, created_at FROM (
+			SELECT p.id AS post_id, '' AS retweeted_by, date_trunc('milliseconds', p.created_at) AS created_at
+			FROM posts p WHERE %[1]s AND %[2]s
+			UNION ALL
+			SELECT r.post_id, r.user_id AS retweeted_by, date_trunc('milliseconds', r.created_at) AS created_at
+			FROM retweets r WHERE %[3]s AND %[4]s
+		) entries
+		ORDER BY created_at %[5]s, post_id %[5]s
+		LIMIT @limit
+	`,
+		posts, entryWindow(page, "p.created_at", "posts p", posts, direction),
+		retweets, entryWindow(page, "r.created_at", "retweets r", retweets, direction),
+		direction,
+	), args).Scan(&entries).Error; err != nil {
+		log.Printf("Could not find timeline entries. Reason: %v\n", err)
+		return nil, apperrors.NewInternal()
+	}
+
+	if page.After != nil {
+		reverse(entries)
+	}
+
+	return entries, nil
+}
+
+// entryConditions adds the cursor of the page and the excluded posts to the conditions of a branch.
+// The range on the raw time lets the branch use its index, the truncated time decides.
+func entryConditions(page model.Page, exclude []string, timeCol, idCol, conditions string) string {
+	if page.Before != nil {
+		conditions += fmt.Sprintf(" AND %[1]s < @until AND (date_trunc('milliseconds', %[1]s), %[2]s) < (@time, @id)", timeCol, idCol)
+	}
+	if page.After != nil {
+		conditions += fmt.Sprintf(" AND %[1]s >= @time AND (date_trunc('milliseconds', %[1]s), %[2]s) > (@time, @id)", timeCol, idCol)
+	}
+	if len(exclude) > 0 {
+		conditions += fmt.Sprintf(" AND %s NOT IN @exclude", idCol)
+	}
+	return conditions
+}
+
+// entryWindow bounds a branch by the millisecond of its limit-th entry in page order.
+// Entries get sorted by their truncated time, so the whole millisecond has to be read.
+func entryWindow(page model.Page, timeCol, table, conditions, direction string) string {
+	boundary := fmt.Sprintf(
+		"(SELECT date_trunc('milliseconds', %[1]s) FROM %[2]s WHERE %[3]s ORDER BY %[1]s %[4]s OFFSET @offset LIMIT 1)",
+		timeCol, table, conditions, direction,
+	)
+
+	if page.After != nil {
+		return fmt.Sprintf("%s < coalesce(%s + interval '1 millisecond', 'infinity')", timeCol, boundary)
+	}
+	return fmt.Sprintf("%s >= coalesce(%s, '-infinity')", timeCol, boundary)
+}
+
+// FindFollowerIDs returns the IDs of all users following the given user.
+// The followers of celebrities don't get loaded, as their posts don't get written to timelines.
+func (r *timelineRepository) FindFollowerIDs(userId string) ([]string, bool, error) {
+	var followers int
+
+	if err := r.DB.
+		Raw("SELECT follower_count FROM users WHERE id = ?", userId).
+		Scan(&followers).Error; err != nil {
+		log.Printf("Could not find follower count for user: %v. Reason: %v\n", userId, err)
+		return nil, false, apperrors.NewInternal()
+	}
+
+	if followers >= model.CelebrityFollowers {
+		return nil, true, nil
+	}
+
+	var ids []string
+
+	if err := r.DB.
+		Raw("SELECT follower_id FROM followers WHERE user_id = ?", userId).
+		Scan(&ids).Error; err != nil {
+		log.Printf("Could not find followers for user: %v. Reason: %v\n", userId, err)
+		return nil, false, apperrors.NewInternal()
+	}
+
+	return ids, false, nil
+}
+
+// FindFolloweeIDs returns the IDs of all users the given user follows.
+// Celebrities are returned separately, as their posts don't get written to timelines.
+func (r *timelineRepository) FindFolloweeIDs(userId string) ([]string, []string, error) {
+	var rows []struct {
+		ID        string
+		Followers int
+	}
+
+	if err := r.DB.Raw(`
+		SELECT u.id, u.follower_count AS followers
+		FROM followee f
+		JOIN users u ON u.id = f.followee_id
+		WHERE f.user_id = ?
+	`, userId).Scan(&rows).Error; err != nil {
+		log.Printf("Could not find followees for user: %v. Reason: %v\n", userId, err)
+		return nil, nil, apperrors.NewInternal()
+	}
+
+	regular := make([]string, 0)
+	celebrities := make([]string, 0)
+	for _, row := range rows {
+		if row.Followers >= model.CelebrityFollowers {
+			celebrities = append(celebrities, row.ID)
+		} else {
+			regular = append(regular, row.ID)
+		}
+	}
+
+	return regular, celebrities, nil
+}
+
+// timelineEnd is the member marking complete timelines
+const timelineEnd = "end"
+
+func timelineKey(userId string) string {
+	return "timeline:" + userId
+}
+
+func retweetsKey(userId string) string {
+	return "timeline:" + userId + ":retweets"
+}
+
+func score(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"strconv"
+	"time"
+)
+
+// trendRepository is data/repository implementation
+// of service layer TrendRepository.
+// Each bucket has two sorted sets in Redis, counting the posts and the authors of each hashtag.
+// An author only counts once per hashtag within the trend window, which a key with a TTL keeps track of.
+type trendRepository struct {
+	RedisClient *redis.Client
+}
+
+// NewTrendRepository is a factory for initializing Trend Repositories
+func NewTrendRepository(rdb *redis.Client) model.TrendRepository {
+	return &trendRepository{
+		RedisClient: rdb,
+	}
+}
+
+// addTrendScript counts a post with the hashtag in ARGV[1] and,
+// if the author hasn't used it within ARGV[2] milliseconds, the author too.
+// Bucket keys expire after ARGV[3] milliseconds.
+var addTrendScript = redis.NewScript(`
+redis.call("ZINCRBY", KEYS[3], 1, ARGV[1])
+redis.call("PEXPIRE", KEYS[3], ARGV[3])
+if redis.call("SET", KEYS[1], "1", "NX", "PX", ARGV[2]) then
+	redis.call("ZINCRBY", KEYS[2], 1, ARGV[1])
+	redis.call("PEXPIRE", KEYS[2], ARGV[3])
+end
+return 1
+`)
+
+// AddHashtags counts the hashtags of a post by the author in the bucket of the given time
+func (r *trendRepository) AddHashtags(authorId string, tags []string, at time.Time) error {
+	ctx := context.Background()
+	bucket := trendBucket(at)
+	window := (model.TrendWindowBuckets * model.TrendBucketSize).Milliseconds()
+	ttl := ((model.TrendWindowBuckets + model.TrendBaselineBuckets + 1) * model.TrendBucketSize).Milliseconds()
+
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = normalizeHashtag(tag)
+
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+
+		keys := []string{trendAuthorKey(tag, authorId), trendAuthorsKey(bucket), trendPostsKey(bucket)}
+		if err := addTrendScript.Run(ctx, r.RedisClient, keys, tag, window, ttl).Err(); err != nil {
+			log.Printf("Could not count hashtag: %v. Reason: %v\n", tag, err)
+			return apperrors.NewInternal()
+		}
+	}
+
+	return nil
+}
+
+// FindBuckets returns the given number of buckets up to the one containing until, newest first
+func (r *trendRepository) FindBuckets(until time.Time, count int) ([]model.HashtagBucket, error) {
+	ctx := context.Background()
+	newest := trendBucket(until)
+
+	pipe := r.RedisClient.Pipeline()
+	authors := make([]*redis.ZSliceCmd, count)
+	posts := make([]*redis.ZSliceCmd, count)
+	for i := 0; i < count; i++ {
+		authors[i] = pipe.ZRangeWithScores(ctx, trendAuthorsKey(newest-int64(i)), 0, -1)
+		posts[i] = pipe.ZRangeWithScores(ctx, trendPostsKey(newest-int64(i)), 0, -1)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		log.Printf("Could not get hashtag buckets. Reason: %v\n", err)
+		return nil, apperrors.NewInternal()
+	}
+
+	buckets := make([]model.HashtagBucket, count)
+	for i := 0; i < count; i++ {
+		buckets[i] = model.HashtagBucket{
+			Authors: counts(authors[i].Val()),
+			Posts:   counts(posts[i].Val()),
+		}
+	}
+
+	return buckets, nil
+}
+
+func counts(members []redis.Z) map[string]uint {
+	result := make(map[string]uint, len(members))
+	for _, member := range members {
+		result[member.Member.(string)] = uint(member.Score)
+	}
+	return result
+}
+
+// trendBucket returns the number of the bucket containing the time
+func trendBucket(t time.Time) int64 {
+	return t.UnixMilli() / model.TrendBucketSize.Milliseconds()
+}
+
+func trendAuthorsKey(bucket int64) string {
+	return "trends:" + strconv.FormatInt(bucket, 10) + ":authors"
+}
+
+func trendPostsKey(bucket int64) string {
+	return "trends:" + strconv.FormatInt(bucket, 10) + ":posts"
+}
+
+func trendAuthorKey(tag, authorId string) string {
+	return "trends:seen:" + tag + ":" + authorId
+}
@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"gorm.io/gorm"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// typeaheadBackfilledKey is set once all existing users and hashtags got indexed,
+	// and while an instance is still indexing them
+	typeaheadBackfilledKey = "typeahead:backfilled"
+	// typeaheadBackfillLease is how long an instance gets to backfill before another one may retry
+	typeaheadBackfillLease = time.Hour
+	// typeaheadBackfillPage is the number of users or hashtags that get indexed at once
+	typeaheadBackfillPage = 1000
+)
+
+// typeaheadRepository is data/repository implementation
+// of service layer TypeaheadRepository.
+// Every prefix of a username or hashtag has a sorted set in Redis.
+// Users are scored by their followers, hashtags by the posts using them.
+type typeaheadRepository struct {
+	DB          *gorm.DB
+	RedisClient *redis.Client
+}
+
+// NewTypeaheadRepository is a factory for initializing Typeahead Repositories
+func NewTypeaheadRepository(db *gorm.DB, rdb *redis.Client) model.TypeaheadRepository {
+	return &typeaheadRepository{
+		DB:          db,
+		RedisClient: rdb,
+	}
+}
+
+// AddUser indexes the username of the user
+func (r *typeaheadRepository) AddUser(user *model.User) error {
+	pipe := r.RedisClient.Pipeline()
+	addUser(pipe, user)
+
+	if _, err := pipe.Exec(context.Background()); err != nil {
+		log.Printf("Could not index username of user: %v. Reason: %v\n", user.ID, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// RenameUser moves the user from the prefixes of the previous username to the ones of the current one
+func (r *typeaheadRepository) RenameUser(user *model.User, previous string) error {
+	ctx := context.Background()
+	_, err := r.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, prefix := range prefixes(strings.ToLower(previous)) {
+			pipe.ZRem(ctx, userPrefixKey(prefix), user.ID)
+		}
+		addUser(pipe, user)
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Could not reindex username of user: %v. Reason: %v\n", user.ID, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// AddFollowers changes the score of the user by the number of gained or lost followers
+func (r *typeaheadRepository) AddFollowers(user *model.User, delta int) error {
+	ctx := context.Background()
+	pipe := r.RedisClient.Pipeline()
+	for _, prefix := range prefixes(strings.ToLower(user.Username)) {
+		pipe.ZIncrBy(ctx, userPrefixKey(prefix), float64(delta), user.ID)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Could not update typeahead score of user: %v. Reason: %v\n", user.ID, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// AddHashtags counts a use of each of the hashtags
+func (r *typeaheadRepository) AddHashtags(tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	pipe := r.RedisClient.Pipeline()
+	for _, tag := range tags {
+		tag = normalizeHashtag(tag)
+		for _, prefix := range prefixes(tag) {
+			pipe.ZIncrBy(ctx, hashtagPrefixKey(prefix), 1, tag)
+		}
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Could not index hashtags: %v. Reason: %v\n", tags, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// RemoveHashtags takes back a use of each of the hashtags.
+// Hashtags that aren't used anymore get dropped from the suggestions.
+func (r *typeaheadRepository) RemoveHashtags(tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	pipe := r.RedisClient.Pipeline()
+	for _, tag := range tags {
+		tag = normalizeHashtag(tag)
+		for _, prefix := range prefixes(tag) {
+			pipe.ZIncrBy(ctx, hashtagPrefixKey(prefix), -1, tag)
+			pipe.ZRemRangeByScore(ctx, hashtagPrefixKey(prefix), "-inf", "0")
+		}
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Could not unindex hashtags: %v. Reason: %v\n", tags, err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// FindUserIDs returns the IDs of the users with the most followers
+// whose username starts with the prefix
+func (r *typeaheadRepository) FindUserIDs(prefix string, limit int) ([]string, error) {
+	ids, err := r.RedisClient.ZRevRange(context.Background(), userPrefixKey(truncatePrefix(prefix)), 0, int64(limit-1)).Result()
+
+	if err != nil {
+		log.Printf("Could not find users for prefix: %v. Reason: %v\n", prefix, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	return ids, nil
+}
+
+// FindHashtags returns the most used hashtags starting with the prefix
+func (r *typeaheadRepository) FindHashtags(prefix string, limit int) ([]model.HashtagCount, error) {
+	members, err := r.RedisClient.ZRevRangeWithScores(context.Background(), hashtagPrefixKey(truncatePrefix(prefix)), 0, int64(limit-1)).Result()
+
+	if err != nil {
+		log.Printf("Could not find hashtags for prefix: %v. Reason: %v\n", prefix, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	hashtags := make([]model.HashtagCount, 0, len(members))
+	for _, member := range members {
+		hashtags = append(hashtags, model.HashtagCount{Tag: member.Member.(string), Posts: uint(member.Score)})
+	}
+
+	return hashtags, nil
+}
+
+// Backfill indexes all existing users and hashtags, unless that already happened
+// or another instance is doing it. Scores get set to the counts in the database,
+// so running it again doesn't count anything twice. Each page gets written right
+// after reading it, to keep the window for overwriting live updates small.
+func (r *typeaheadRepository) Backfill() error {
+	ctx := context.Background()
+
+	claimed, err := r.RedisClient.SetNX(ctx, typeaheadBackfilledKey, "running", typeaheadBackfillLease).Result()
+
+	if err != nil {
+		log.Printf("Could not check typeahead backfill. Reason: %v\n", err)
+		return apperrors.NewInternal()
+	}
+
+	if !claimed {
+		return nil
+	}
+
+	if err := r.backfillUsers(); err != nil {
+		r.RedisClient.Del(ctx, typeaheadBackfilledKey)
+		return err
+	}
+
+	if err := r.backfillHashtags(); err != nil {
+		r.RedisClient.Del(ctx, typeaheadBackfilledKey)
+		return err
+	}
+
+	if err := r.RedisClient.Set(ctx, typeaheadBackfilledKey, "done", 0).Err(); err != nil {
+		log.Printf("Could not finish typeahead backfill. Reason: %v\n", err)
+		return apperrors.NewInternal()
+	}
+
+	return nil
+}
+
+// backfillUsers indexes the usernames of all users, a page at a time
+func (r *typeaheadRepository) backfillUsers() error {
+	ctx := context.Background()
+	after := ""
+
+	for {
+		var users []model.User
+		if err := r.DB.
+			Raw("SELECT id, username, follower_count FROM users WHERE id > ? ORDER BY id LIMIT ?", after, typeaheadBackfillPage).
+			Scan(&users).Error; err != nil {
+			log.Printf("Could not load users for typeahead. Reason: %v\n", err)
+			return apperrors.NewInternal()
+		}
+
+		if len(users) == 0 {
+			return nil
+		}
+
+		pipe := r.RedisClient.Pipeline()
+		for i := range users {
+			addUser(pipe, &users[i])
+		}
+
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Printf("Could not backfill users for typeahead. Reason: %v\n", err)
+			return apperrors.NewInternal()
+		}
+
+		if len(users) < typeaheadBackfillPage {
+			return nil
+		}
+		after = users[len(users)-1].ID
+	}
+}
+
+// backfillHashtags indexes all hashtags with the number of posts using them, a page at a time
+func (r *typeaheadRepository) backfillHashtags() error {
+	ctx := context.Background()
+	after := ""
+
+	for {
+		var hashtags []model.HashtagCount
+		if err := r.DB.Raw(`
+			SELECT tag, count(*) AS posts
+			FROM (SELECT lower(ltrim(tag, '#')) AS tag FROM posts, unnest(hash_tags) AS tag) tags
+			WHERE tag > ?
+			GROUP BY tag
+			ORDER BY tag
+			LIMIT ?
+		`, after, typeaheadBackfillPage).Scan(&hashtags).Error; err != nil {
+			log.Printf("Could not load hashtags for typeahead. Reason: %v\n", err)
+			return apperrors.NewInternal()
+		}
+
+		if len(hashtags) == 0 {
+			return nil
+		}
+
+		pipe := r.RedisClient.Pipeline()
+		for _, hashtag := range hashtags {
+			for _, prefix := range prefixes(hashtag.Tag) {
+				pipe.ZAdd(ctx, hashtagPrefixKey(prefix), &redis.Z{Score: float64(hashtag.Posts), Member: hashtag.Tag})
+			}
+		}
+
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Printf("Could not backfill hashtags for typeahead. Reason: %v\n", err)
+			return apperrors.NewInternal()
+		}
+
+		if len(hashtags) < typeaheadBackfillPage {
+			return nil
+		}
+		after = hashtags[len(hashtags)-1].Tag
+	}
+}
+
+func addUser(pipe redis.Pipeliner, user *model.User) {
+	for _, prefix := range prefixes(strings.ToLower(user.Username)) {
+		pipe.ZAdd(context.Background(), userPrefixKey(prefix), &redis.Z{Score: float64(user.FollowerCount), Member: user.ID})
+	}
+}
+
+// prefixes returns the prefixes of the value up to model.TypeaheadPrefixLength characters
+func prefixes(value string) []string {
+	runes := []rune(value)
+	result := make([]string, 0, len(runes))
+
+	for i := 1; i <= len(runes) && i <= model.TypeaheadPrefixLength; i++ {
+		result = append(result, string(runes[:i]))
+	}
+
+	return result
+}
+
+func truncatePrefix(prefix string) string {
+	if runes := []rune(prefix); len(runes) > model.TypeaheadPrefixLength {
+		return string(runes[:model.TypeaheadPrefixLength])
+	}
+	return prefix
+}
+
+// normalizeHashtag returns the hashtag in lower case without the #
+func normalizeHashtag(tag string) string {
+	return strings.ToLower(strings.TrimLeft(tag, "#"))
+}
+
+func userPrefixKey(prefix string) string {
+	return "typeahead:users:" + prefix
+}
+
+func hashtagPrefixKey(prefix string) string {
+	return "typeahead:hashtags:" + prefix
+}
@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/sentrionic/mirage/model"
 	"github.com/sentrionic/mirage/model/apperrors"
 	"gorm.io/gorm"
@@ -10,6 +12,13 @@ import (
 	"strings"
 )
 
+const (
+	// prefixSearchBoost puts users whose names start with the term before all others
+	prefixSearchBoost = 2
+	// followingSearchBoost gets added to the rank of users the viewer follows
+	followingSearchBoost = 0.2
+)
+
 // userRepository is data/repository implementation
 // of service layer UserRepository
 type userRepository struct {
@@ -57,8 +66,6 @@ func (r *userRepository) FindByUsername(username string) (*model.User, error) {
 
 	// we need to actually check errors as it could be something other than not found
 	if err := r.DB.
-		Preload("Followers").
-		Preload("Followee").
 		Where("LOWER(username) = ?", strings.ToLower(username)).
 		First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -70,6 +77,44 @@ func (r *userRepository) FindByUsername(username string) (*model.User, error) {
 	return user, nil
 }
 
+// FindByIDs returns the users for the given IDs in no particular order
+func (r *userRepository) FindByIDs(ids []string) (*[]model.User, error) {
+	users := make([]model.User, 0)
+
+	if len(ids) == 0 {
+		return &users, nil
+	}
+
+	if err := r.DB.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		log.Printf("Could not find users. Reason: %v\n", err)
+		return nil, apperrors.NewInternal()
+	}
+
+	return &users, nil
+}
+
+// FindByUsernames returns the users for the given usernames in no particular order.
+// Usernames are matched case-insensitively.
+func (r *userRepository) FindByUsernames(usernames []string) (*[]model.User, error) {
+	users := make([]model.User, 0)
+
+	if len(usernames) == 0 {
+		return &users, nil
+	}
+
+	lowered := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		lowered = append(lowered, strings.ToLower(username))
+	}
+
+	if err := r.DB.Where("LOWER(username) IN ?", lowered).Find(&users).Error; err != nil {
+		log.Printf("Could not find users by username. Reason: %v\n", err)
+		return nil, apperrors.NewInternal()
+	}
+
+	return &users, nil
+}
+
 // Create inserts the user in the DB
 func (r *userRepository) Create(user *model.User) (*model.User, error) {
 	if result := r.DB.Create(&user); result.Error != nil {
@@ -91,9 +136,18 @@ func (r *userRepository) Create(user *model.User) (*model.User, error) {
 	return user, nil
 }
 
-// Update updates the user in the DB
+// editableUserColumns are the columns profile edits change.
+// The counters and the pinned post get updated on their own, so a stale user can't overwrite them.
+var editableUserColumns = []string{
+	"username", "display_name", "email", "bio",
+	"image", "image_blur_hash", "image_dominant_color", "image_media_id",
+	"banner", "banner_blur_hash", "banner_dominant_color", "banner_media_id",
+	"updated_at",
+}
+
+// Update saves the editable columns of the user in the DB
 func (r *userRepository) Update(user *model.User) error {
-	if result := r.DB.Save(&user); result.Error != nil {
+	if result := r.DB.Model(user).Select(editableUserColumns).Updates(user); result.Error != nil {
 		// check unique constraint
 		if isDuplicateKeyError(result.Error) {
 			if strings.Contains(result.Error.Error(), "email") {
@@ -112,35 +166,156 @@ func (r *userRepository) Update(user *model.User) error {
 	return nil
 }
 
+// AddFollow makes currentId follow userId and updates the counters of both users.
+// Following a user twice doesn't change anything.
 func (r *userRepository) AddFollow(userId, currentId string) error {
-	err := r.DB.Table("followers").Create(map[string]interface{}{
-		"user_id":     userId,
-		"follower_id": currentId,
-	}).Table("followee").Create(map[string]interface{}{
-		"followee_id": userId,
-		"user_id":     currentId,
-	}).Error
-	return err
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec("INSERT INTO followers (user_id, follower_id) VALUES (?, ?) ON CONFLICT DO NOTHING", userId, currentId)
+
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if err := tx.Exec("INSERT INTO followee (followee_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING", userId, currentId).Error; err != nil {
+			return err
+		}
+
+		return updateFollowCounts(tx, userId, currentId, 1)
+	})
 }
 
+// RemoveFollow makes currentId unfollow userId and updates the counters of both users
 func (r *userRepository) RemoveFollow(userId, currentId string) error {
-	err := r.DB.
-		Exec("DELETE FROM followers WHERE user_id = ? AND follower_id = ?", userId, currentId).
-		Exec("DELETE FROM followee WHERE followee_id = ? AND user_id = ?", userId, currentId).
-		Error
-	return err
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec("DELETE FROM followers WHERE user_id = ? AND follower_id = ?", userId, currentId)
+
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if err := tx.Exec("DELETE FROM followee WHERE followee_id = ? AND user_id = ?", userId, currentId).Error; err != nil {
+			return err
+		}
+
+		return updateFollowCounts(tx, userId, currentId, -1)
+	})
+}
+
+// SetPinnedPost pins the post to the profile of the user or clears the pin if postId is nil.
+// The post has to belong to the user. Deleting it concurrently waits for the pin, so the pin gets cleared either way.
+func (r *userRepository) SetPinnedPost(userId string, postId *string) error {
+	var result *gorm.DB
+
+	if postId == nil {
+		result = r.DB.Exec("UPDATE users SET pinned_post_id = NULL WHERE id = ?", userId)
+	} else {
+		result = r.DB.Exec(`
+			UPDATE users SET pinned_post_id = @post
+			WHERE id = @user AND EXISTS(SELECT 1 FROM posts WHERE id = @post AND user_id = @user FOR KEY SHARE)
+		`, sql.Named("post", *postId), sql.Named("user", userId))
+	}
+
+	if result.Error != nil {
+		log.Printf("Could not set pinned post of user: %v. Reason: %v\n", userId, result.Error)
+		return apperrors.NewInternal()
+	}
+
+	if postId != nil && result.RowsAffected == 0 {
+		return apperrors.NewNotFound("post", *postId)
+	}
+
+	return nil
 }
 
-func (r *userRepository) SearchProfiles(term string) (*[]model.User, error) {
-	users := &[]model.User{}
+// LoadViewerState sets if the viewer follows each of the users
+func (r *userRepository) LoadViewerState(viewerId string, users []*model.User) error {
+	if viewerId == "" || len(users) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
 
-	err := r.DB.
-		Preload("Followers").
-		Preload("Followee").
-		Where("username ILIKE ?", "%"+strings.ToLower(term)+"%").
-		Find(&users).Error
+	var following []string
+	if err := r.DB.
+		Raw("SELECT user_id FROM followers WHERE follower_id = ? AND user_id IN ?", viewerId, ids).
+		Scan(&following).Error; err != nil {
+		log.Printf("Could not load follow state for viewer: %v. Reason: %v\n", viewerId, err)
+		return apperrors.NewInternal()
+	}
+
+	followed := make(map[string]bool)
+	for _, id := range following {
+		followed[id] = true
+	}
+
+	for _, user := range users {
+		user.Following = followed[user.ID]
+	}
+
+	return nil
+}
+
+func updateFollowCounts(tx *gorm.DB, userId, currentId string, delta int) error {
+	if err := tx.Exec("UPDATE users SET follower_count = follower_count + ? WHERE id = ?", delta, userId).Error; err != nil {
+		return err
+	}
+
+	return tx.Exec("UPDATE users SET followee_count = followee_count + ? WHERE id = ?", delta, currentId).Error
+}
+
+// SearchProfiles finds the users whose username or display name contain or resemble the term.
+// Prefix matches come first, then users get ranked by trigram similarity,
+// with a boost for users the viewer follows, and by followers last.
+// The rank is rounded, so it compares exactly in cursors.
+func (r *userRepository) SearchProfiles(term, viewerId string, cursor *model.ProfileCursor) (*[]model.User, error) {
+	users := make([]model.User, 0)
+
+	args := map[string]interface{}{
+		"term":           term,
+		"prefix":         escapeLike(term) + "%",
+		"contains":       "%" + escapeLike(term) + "%",
+		"viewer":         viewerId,
+		"prefixBoost":    prefixSearchBoost,
+		"followingBoost": followingSearchBoost,
+		"limit":          model.LIMIT + 1,
+	}
+
+	conditions := "TRUE"
+	if cursor != nil {
+		conditions = "(search_rank, follower_count, id) < (@rank::numeric, @followers, @id)"
+		args["rank"] = cursor.Rank
+		args["followers"] = cursor.Followers
+		args["id"] = cursor.ID
+	}
+
+	if err := r.DB.Raw(fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT u.*, round((
+				CASE WHEN u.username ILIKE @prefix OR u.display_name ILIKE @prefix THEN @prefixBoost ELSE 0 END
+				+ greatest(similarity(u.username, @term), similarity(u.display_name, @term))
+				+ CASE WHEN EXISTS(SELECT 1 FROM followers f WHERE f.user_id = u.id AND f.follower_id = @viewer) THEN @followingBoost ELSE 0 END
+			)::numeric, 6) AS search_rank
+			FROM users u
+			WHERE u.username ILIKE @contains OR u.display_name ILIKE @contains
+			OR u.username %% @term OR u.display_name %% @term
+		) ranked
+		WHERE %s
+		ORDER BY search_rank DESC, follower_count DESC, id DESC
+		LIMIT @limit
+	`, conditions), args).Scan(&users).Error; err != nil {
+		log.Printf("Could not search profiles for term: %v. Reason: %v\n", term, err)
+		return nil, apperrors.NewInternal()
+	}
+
+	return &users, nil
+}
 
-	return users, err
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
 }
 
 // isDuplicateKeyError checks if the provided error is a PostgreSQL duplicate key error
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"testing"
+)
+
+// dryRunDB returns a DB that only builds statements and the list the statements get collected in
+func dryRunDB(t *testing.T) (*gorm.DB, *[]string) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 logger.Discard,
+	})
+	assert.NoError(t, err)
+
+	statements := make([]string, 0)
+	collect := func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	}
+	assert.NoError(t, db.Callback().Update().After("gorm:update").Register("test:collect", collect))
+	assert.NoError(t, db.Callback().Raw().After("gorm:raw").Register("test:collect", collect))
+	assert.NoError(t, db.Callback().Query().After("gorm:query").Register("test:collect", collect))
+
+	return db, &statements
+}
+
+func TestUserRepository_Update(t *testing.T) {
+	t.Run("Keeps the counters and the pinned post", func(t *testing.T) {
+		db, statements := dryRunDB(t)
+		r := NewUserRepository(db)
+
+		user := fixture.GetMockUser()
+		pinned := fixture.RandID()
+		user.PinnedPostID = &pinned
+		user.FollowerCount = 3
+		user.FolloweeCount = 5
+
+		err := r.Update(user)
+
+		assert.NoError(t, err)
+		assert.Len(t, *statements, 1)
+
+		statement := (*statements)[0]
+		assert.Contains(t, statement, `"display_name"=`)
+		assert.Contains(t, statement, `"image_blur_hash"=`)
+		assert.NotContains(t, statement, "follower_count")
+		assert.NotContains(t, statement, "followee_count")
+		assert.NotContains(t, statement, "pinned_post_id")
+	})
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sentrionic/mirage/model"
+	"log"
+	"os"
+	"time"
+)
+
+// readSchedulerInterval reads SCHEDULER_INTERVAL, the time between two runs of the scheduler
+func readSchedulerInterval() (time.Duration, error) {
+	value := os.Getenv("SCHEDULER_INTERVAL")
+
+	if value == "" {
+		return model.DefaultSchedulerInterval, nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("could not parse SCHEDULER_INTERVAL as a positive duration: %v", value)
+	}
+
+	return interval, nil
+}
+
+// startScheduler publishes due drafts once every interval
+func startScheduler(draftService model.DraftService, interval time.Duration) {
+	runWithLease("scheduler", interval, func(holder string, lease time.Duration) {
+		report, err := draftService.PublishScheduled(holder, lease)
+
+		if err != nil {
+			log.Printf("Failed to publish scheduled posts: %v\n", err)
+			return
+		}
+
+		if report.Expired+report.Published+report.Failed > 0 {
+			b, _ := json.Marshal(report)
+			log.Printf("Published scheduled posts: %s\n", b)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+)
+
+type bookmarkService struct {
+	BookmarkRepository model.BookmarkRepository
+}
+
+// BSConfig will hold repositories that will eventually be injected into this
+// this service layer
+type BSConfig struct {
+	BookmarkRepository model.BookmarkRepository
+}
+
+// NewBookmarkService is a factory function for
+// initializing a BookmarkService with its repository layer dependencies
+func NewBookmarkService(c *BSConfig) model.BookmarkService {
+	return &bookmarkService{
+		BookmarkRepository: c.BookmarkRepository,
+	}
+}
+
+// ToggleBookmark removes the post from the user's bookmarks if they bookmarked it
+// and otherwise adds it, to the folder if one is given.
+// A bookmarked post gets moved instead if it is given another folder than its own.
+func (s *bookmarkService) ToggleBookmark(post *model.Post, uid string, folderId *string) error {
+	bookmark, err := s.BookmarkRepository.FindBookmark(uid, post.ID)
+
+	if err != nil {
+		return err
+	}
+
+	moved := folderId != nil && bookmark != nil && (bookmark.FolderID == nil || *bookmark.FolderID != *folderId)
+
+	if bookmark != nil && !moved {
+		return s.BookmarkRepository.RemoveBookmark(post, uid)
+	}
+
+	if folderId != nil {
+		if _, err := s.FindFolder(uid, *folderId); err != nil {
+			return err
+		}
+	}
+
+	if moved {
+		return s.BookmarkRepository.MoveBookmark(post, uid, folderId)
+	}
+
+	return s.BookmarkRepository.AddBookmark(post, uid, folderId)
+}
+
+// GetBookmarks returns the posts the user bookmarked, optionally only the ones in the folder
+func (s *bookmarkService) GetBookmarks(uid string, folderId *string, page model.Page) (*[]model.Post, error) {
+	if folderId != nil {
+		if _, err := s.FindFolder(uid, *folderId); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.BookmarkRepository.Bookmarks(uid, folderId, page)
+}
+
+// GetFolders returns the bookmark folders of the user
+func (s *bookmarkService) GetFolders(uid string) ([]model.BookmarkFolder, error) {
+	return s.BookmarkRepository.FindFolders(uid)
+}
+
+// FindFolder returns the folder if it belongs to the user.
+// Folders of other users don't exist as far as the user is concerned.
+func (s *bookmarkService) FindFolder(uid, id string) (*model.BookmarkFolder, error) {
+	folder, err := s.BookmarkRepository.FindFolderByID(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if folder.UserID != uid {
+		return nil, apperrors.NewNotFound("folder", id)
+	}
+
+	return folder, nil
+}
+
+// CreateFolder adds a bookmark folder for the user
+func (s *bookmarkService) CreateFolder(folder *model.BookmarkFolder) (*model.BookmarkFolder, error) {
+	id, err := GenerateId()
+
+	if err != nil {
+		log.Printf("Unable to create bookmark folder for user: %v\n", folder.UserID)
+		return nil, apperrors.NewInternal()
+	}
+	folder.ID = id
+
+	if err := s.BookmarkRepository.CreateFolder(folder); err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+// DeleteFolder removes the folder and keeps its bookmarks
+func (s *bookmarkService) DeleteFolder(folder *model.BookmarkFolder) error {
+	return s.BookmarkRepository.DeleteFolder(folder)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestBookmarkService_ToggleBookmark(t *testing.T) {
+	t.Run("Adds the bookmark to the folder", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		uid := fixture.RandID()
+		folder := &model.BookmarkFolder{ID: fixture.RandID(), UserID: uid, Name: "Recipes"}
+
+		mockBookmarkRepository := new(mocks.BookmarkRepository)
+		bs := NewBookmarkService(&BSConfig{
+			BookmarkRepository: mockBookmarkRepository,
+		})
+		mockBookmarkRepository.On("FindBookmark", uid, mockPost.ID).Return(nil, nil)
+		mockBookmarkRepository.On("FindFolderByID", folder.ID).Return(folder, nil)
+		mockBookmarkRepository.On("AddBookmark", mockPost, uid, &folder.ID).Return(nil)
+
+		err := bs.ToggleBookmark(mockPost, uid, &folder.ID)
+
+		assert.NoError(t, err)
+		mockBookmarkRepository.AssertExpectations(t)
+	})
+
+	t.Run("Removes an existing bookmark", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		uid := fixture.RandID()
+
+		mockBookmarkRepository := new(mocks.BookmarkRepository)
+		bs := NewBookmarkService(&BSConfig{
+			BookmarkRepository: mockBookmarkRepository,
+		})
+		mockBookmarkRepository.On("FindBookmark", uid, mockPost.ID).Return(&model.Bookmark{UserID: uid, PostID: mockPost.ID}, nil)
+		mockBookmarkRepository.On("RemoveBookmark", mockPost, uid).Return(nil)
+
+		err := bs.ToggleBookmark(mockPost, uid, nil)
+
+		assert.NoError(t, err)
+		mockBookmarkRepository.AssertExpectations(t)
+		mockBookmarkRepository.AssertNotCalled(t, "AddBookmark", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Removes a bookmark from its own folder", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		uid := fixture.RandID()
+		folderId := fixture.RandID()
+
+		mockBookmarkRepository := new(mocks.BookmarkRepository)
+		bs := NewBookmarkService(&BSConfig{
+			BookmarkRepository: mockBookmarkRepository,
+		})
+		mockBookmarkRepository.
+			On("FindBookmark", uid, mockPost.ID).
+			Return(&model.Bookmark{UserID: uid, PostID: mockPost.ID, FolderID: &folderId}, nil)
+		mockBookmarkRepository.On("RemoveBookmark", mockPost, uid).Return(nil)
+
+		err := bs.ToggleBookmark(mockPost, uid, &folderId)
+
+		assert.NoError(t, err)
+		mockBookmarkRepository.AssertExpectations(t)
+		mockBookmarkRepository.AssertNotCalled(t, "MoveBookmark", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Moves a bookmark to another folder", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		uid := fixture.RandID()
+		previous := fixture.RandID()
+		folder := &model.BookmarkFolder{ID: fixture.RandID(), UserID: uid, Name: "Recipes"}
+
+		mockBookmarkRepository := new(mocks.BookmarkRepository)
+		bs := NewBookmarkService(&BSConfig{
+			BookmarkRepository: mockBookmarkRepository,
+		})
+		mockBookmarkRepository.
+			On("FindBookmark", uid, mockPost.ID).
+			Return(&model.Bookmark{UserID: uid, PostID: mockPost.ID, FolderID: &previous}, nil)
+		mockBookmarkRepository.On("FindFolderByID", folder.ID).Return(folder, nil)
+		mockBookmarkRepository.On("MoveBookmark", mockPost, uid, &folder.ID).Return(nil)
+
+		err := bs.ToggleBookmark(mockPost, uid, &folder.ID)
+
+		assert.NoError(t, err)
+		mockBookmarkRepository.AssertExpectations(t)
+		mockBookmarkRepository.AssertNotCalled(t, "RemoveBookmark", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Folder of another user", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		uid := fixture.RandID()
+		folder := &model.BookmarkFolder{ID: fixture.RandID(), UserID: fixture.RandID(), Name: "Recipes"}
+
+		mockBookmarkRepository := new(mocks.BookmarkRepository)
+		bs := NewBookmarkService(&BSConfig{
+			BookmarkRepository: mockBookmarkRepository,
+		})
+		mockBookmarkRepository.On("FindBookmark", uid, mockPost.ID).Return(nil, nil)
+		mockBookmarkRepository.On("FindFolderByID", folder.ID).Return(folder, nil)
+
+		err := bs.ToggleBookmark(mockPost, uid, &folder.ID)
+
+		assert.Equal(t, apperrors.NewNotFound("folder", folder.ID), err)
+		mockBookmarkRepository.AssertNotCalled(t, "AddBookmark", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestBookmarkService_GetBookmarks(t *testing.T) {
+	t.Run("All bookmarks", func(t *testing.T) {
+		uid := fixture.RandID()
+		posts := []model.Post{*fixture.GetMockPost()}
+
+		mockBookmarkRepository := new(mocks.BookmarkRepository)
+		bs := NewBookmarkService(&BSConfig{
+			BookmarkRepository: mockBookmarkRepository,
+		})
+		mockBookmarkRepository.On("Bookmarks", uid, (*string)(nil), model.Page{}).Return(&posts, nil)
+
+		result, err := bs.GetBookmarks(uid, nil, model.Page{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &posts, result)
+		mockBookmarkRepository.AssertNotCalled(t, "FindFolderByID", mock.Anything)
+	})
+
+	t.Run("Unknown folder", func(t *testing.T) {
+		uid := fixture.RandID()
+		id := fixture.RandID()
+
+		mockBookmarkRepository := new(mocks.BookmarkRepository)
+		bs := NewBookmarkService(&BSConfig{
+			BookmarkRepository: mockBookmarkRepository,
+		})
+		mockErr := apperrors.NewNotFound("folder", id)
+		mockBookmarkRepository.On("FindFolderByID", id).Return(nil, mockErr)
+
+		result, err := bs.GetBookmarks(uid, &id, model.Page{})
+
+		assert.Nil(t, result)
+		assert.Equal(t, mockErr, err)
+		mockBookmarkRepository.AssertNotCalled(t, "Bookmarks", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestBookmarkService_CreateFolder(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		folder := &model.BookmarkFolder{UserID: fixture.RandID(), Name: "Recipes"}
+
+		mockBookmarkRepository := new(mocks.BookmarkRepository)
+		bs := NewBookmarkService(&BSConfig{
+			BookmarkRepository: mockBookmarkRepository,
+		})
+		mockBookmarkRepository.On("CreateFolder", folder).Return(nil)
+
+		created, err := bs.CreateFolder(folder)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, created.ID)
+	})
+
+	t.Run("Name taken", func(t *testing.T) {
+		folder := &model.BookmarkFolder{UserID: fixture.RandID(), Name: "Recipes"}
+
+		mockBookmarkRepository := new(mocks.BookmarkRepository)
+		bs := NewBookmarkService(&BSConfig{
+			BookmarkRepository: mockBookmarkRepository,
+		})
+		mockErr := apperrors.NewConflict("name")
+		mockBookmarkRepository.On("CreateFolder", folder).Return(mockErr)
+
+		created, err := bs.CreateFolder(folder)
+
+		assert.Nil(t, created)
+		assert.Equal(t, mockErr, err)
+	})
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/sentrionic/mirage/model"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxCardTitleLength       = 200
+	maxCardDescriptionLength = 300
+)
+
+// unfurl fetches the page of the card's URL, reads its OpenGraph or Twitter card metadata
+// and rehosts its image. The card gets saved as failed if the page has no title.
+func unfurl(cards model.CardRepository, fetcher model.CardFetcher, files model.FileRepository, link string) error {
+	now := time.Now()
+	card := &model.Card{URL: link, Status: model.CardFailed, FetchedAt: &now}
+
+	metadata, err := fetchMetadata(fetcher, link)
+
+	if err != nil {
+		log.Printf("Unable to fetch card: %v\n%v", link, err)
+		return cards.Save(card)
+	}
+
+	if metadata.Title == "" {
+		return cards.Save(card)
+	}
+
+	card.Status = model.CardReady
+	card.Title = metadata.Title
+	card.Description = metadata.Description
+	card.SiteName = metadata.SiteName
+
+	// Cards without their image are still worth showing
+	if metadata.Image != "" {
+		if image, placeholder, err := rehostImage(fetcher, files, metadata.Image); err != nil {
+			log.Printf("Unable to rehost card image: %v\n%v", metadata.Image, err)
+		} else {
+			card.Image = &image.Url
+			card.ImagePlaceholder = placeholder
+		}
+	}
+
+	return cards.Save(card)
+}
+
+// cardMetadata is what a page says about itself.
+// The image is an absolute URL.
+type cardMetadata struct {
+	Title       string
+	Description string
+	SiteName    string
+	Image       string
+}
+
+func fetchMetadata(fetcher model.CardFetcher, link string) (*cardMetadata, error) {
+	page, err := fetcher.Fetch(link, model.CardMaxPageBytes)
+
+	if err != nil {
+		return nil, err
+	}
+	defer page.Body.Close()
+
+	if !strings.HasPrefix(page.ContentType, "text/html") {
+		return nil, fmt.Errorf("not a page: %s", page.ContentType)
+	}
+
+	base, err := url.Parse(page.URL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMetadata(page.Body, base), nil
+}
+
+// rehostImage uploads the image to the bucket, so clients don't load it from other sites
+func rehostImage(fetcher model.CardFetcher, files model.FileRepository, link string) (model.FileVariant, model.Placeholder, error) {
+	image, err := fetcher.Fetch(link, model.CardMaxImageBytes)
+
+	if err != nil {
+		return model.FileVariant{}, model.Placeholder{}, err
+	}
+	defer image.Body.Close()
+
+	if !strings.HasPrefix(image.ContentType, "image/") {
+		return model.FileVariant{}, model.Placeholder{}, fmt.Errorf("not an image: %s", image.ContentType)
+	}
+
+	data, err := io.ReadAll(image.Body)
+
+	if err != nil {
+		return model.FileVariant{}, model.Placeholder{}, err
+	}
+
+	hash := sha256.Sum256([]byte(link))
+	return files.UploadCardImage(data, "cards", hex.EncodeToString(hash[:16]))
+}
+
+// parseMetadata reads the metadata from the head of the page.
+// OpenGraph properties take precedence over Twitter cards,
+// which take precedence over the title and description of the page.
+func parseMetadata(body io.Reader, base *url.URL) *cardMetadata {
+	properties := make(map[string]string)
+	var title string
+
+	tokenizer := html.NewTokenizer(body)
+	inTitle := false
+
+	for {
+		tokenType := tokenizer.Next()
+
+		if tokenType == html.ErrorToken {
+			break
+		}
+
+		token := tokenizer.Token()
+
+		if token.DataAtom == atom.Body && tokenType == html.StartTagToken {
+			break
+		}
+
+		if token.DataAtom == atom.Title {
+			inTitle = tokenType == html.StartTagToken
+			continue
+		}
+
+		if inTitle && tokenType == html.TextToken && title == "" {
+			title = token.Data
+			continue
+		}
+
+		if token.DataAtom != atom.Meta {
+			continue
+		}
+
+		var key, content string
+		for _, attr := range token.Attr {
+			switch attr.Key {
+			case "property", "name":
+				key = strings.ToLower(attr.Val)
+			case "content":
+				content = attr.Val
+			}
+		}
+
+		if _, ok := properties[key]; !ok && key != "" {
+			properties[key] = content
+		}
+	}
+
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if value := strings.TrimSpace(properties[key]); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	metadata := &cardMetadata{
+		Title:       truncate(first("og:title", "twitter:title"), maxCardTitleLength),
+		Description: truncate(first("og:description", "twitter:description", "description"), maxCardDescriptionLength),
+		SiteName:    truncate(first("og:site_name"), maxCardTitleLength),
+	}
+
+	if metadata.Title == "" {
+		metadata.Title = truncate(strings.TrimSpace(title), maxCardTitleLength)
+	}
+
+	if image := first("og:image", "og:image:url", "twitter:image", "twitter:image:src"); image != "" {
+		if ref, err := url.Parse(image); err == nil {
+			if resolved := base.ResolveReference(ref); resolved.Scheme == "http" || resolved.Scheme == "https" {
+				metadata.Image = resolved.String()
+			}
+		}
+	}
+
+	return metadata
+}
+
+// truncate shortens the text to the given number of characters
+func truncate(text string, length int) string {
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:length-1])) + "…"
+}
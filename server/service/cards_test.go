@@ -0,0 +1,157 @@
+package service
+
+import (
+	"fmt"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// localFetcher stands in for the card fetcher, which refuses to connect to local addresses
+type localFetcher struct{}
+
+func (localFetcher) Fetch(link string, maxBytes int64) (*model.FetchedResource, error) {
+	res, err := http.Get(link)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("unexpected status: %d", res.StatusCode)
+	}
+
+	return &model.FetchedResource{
+		URL:         res.Request.URL.String(),
+		ContentType: res.Header.Get("Content-Type"),
+		Body: struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(res.Body, maxBytes), res.Body},
+	}, nil
+}
+
+func TestUnfurl(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = fmt.Fprint(w, `<html><head>
+			<title>Fallback title</title>
+			<meta property="og:title" content="Gophers &amp; friends">
+			<meta name="twitter:title" content="Twitter title">
+			<meta name="description" content="All about gophers">
+			<meta property="og:site_name" content="Example">
+			<meta property="og:image" content="/images/cover.png">
+		</head><body><meta property="og:description" content="Not in the head"></body></html>`)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/article", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/images/cover.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = fmt.Fprint(w, "png")
+	})
+	mux.HandleFunc("/untitled", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprint(w, "<html><head></head><body>Hello</body></html>")
+	})
+	mux.HandleFunc("/file.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = fmt.Fprint(w, "<title>Not a page</title>")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Run("Reads the metadata and rehosts the image", func(t *testing.T) {
+		mockCardRepository := new(mocks.CardRepository)
+		mockFileRepository := new(mocks.FileRepository)
+
+		link := server.URL + "/moved"
+		rehosted := "https://bucket.s3.amazonaws.com/files/cards/cover.jpeg"
+		placeholder := model.Placeholder{BlurHash: "LEHV6nWB2yk8", DominantColor: "#336699"}
+
+		mockFileRepository.On("UploadCardImage", []byte("png"), "cards", mock.AnythingOfType("string")).
+			Return(model.FileVariant{Url: rehosted}, placeholder, nil)
+		mockCardRepository.On("Save", mock.MatchedBy(func(card *model.Card) bool {
+			return card.URL == link &&
+				card.Status == model.CardReady &&
+				card.Title == "Gophers & friends" &&
+				card.Description == "All about gophers" &&
+				card.SiteName == "Example" &&
+				*card.Image == rehosted &&
+				card.ImagePlaceholder == placeholder &&
+				card.FetchedAt != nil
+		})).Return(nil)
+
+		err := unfurl(mockCardRepository, localFetcher{}, mockFileRepository, link)
+
+		assert.NoError(t, err)
+		mockCardRepository.AssertExpectations(t)
+		mockFileRepository.AssertExpectations(t)
+	})
+
+	t.Run("Pages without a title fail", func(t *testing.T) {
+		mockCardRepository := new(mocks.CardRepository)
+		mockFileRepository := new(mocks.FileRepository)
+
+		mockCardRepository.On("Save", mock.MatchedBy(func(card *model.Card) bool {
+			return card.Status == model.CardFailed && card.Title == ""
+		})).Return(nil)
+
+		err := unfurl(mockCardRepository, localFetcher{}, mockFileRepository, server.URL+"/untitled")
+
+		assert.NoError(t, err)
+		mockCardRepository.AssertExpectations(t)
+		mockFileRepository.AssertNotCalled(t, "UploadCardImage", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Other content and errors fail", func(t *testing.T) {
+		for _, path := range []string{"/file.txt", "/missing"} {
+			mockCardRepository := new(mocks.CardRepository)
+			mockCardRepository.On("Save", mock.MatchedBy(func(card *model.Card) bool {
+				return card.Status == model.CardFailed
+			})).Return(nil)
+
+			err := unfurl(mockCardRepository, localFetcher{}, new(mocks.FileRepository), server.URL+path)
+
+			assert.NoError(t, err)
+			mockCardRepository.AssertExpectations(t)
+		}
+	})
+}
+
+func TestParseMetadata(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/post")
+
+	t.Run("Falls back to the page title", func(t *testing.T) {
+		page := `<title> Plain page </title><meta name="twitter:image" content="img/cover.png">`
+		metadata := parseMetadata(strings.NewReader(page), base)
+
+		assert.Equal(t, "Plain page", metadata.Title)
+		assert.Equal(t, "https://example.com/blog/img/cover.png", metadata.Image)
+	})
+
+	t.Run("Ignores images that aren't web links", func(t *testing.T) {
+		page := `<meta property="og:title" content="Title"><meta property="og:image" content="javascript:alert(1)">`
+		metadata := parseMetadata(strings.NewReader(page), base)
+
+		assert.Equal(t, "Title", metadata.Title)
+		assert.Empty(t, metadata.Image)
+	})
+
+	t.Run("Truncates long titles", func(t *testing.T) {
+		page := fmt.Sprintf(`<meta property="og:title" content="%s">`, strings.Repeat("ü", 300))
+		metadata := parseMetadata(strings.NewReader(page), base)
+
+		assert.Equal(t, maxCardTitleLength, len([]rune(metadata.Title)))
+		assert.True(t, strings.HasSuffix(metadata.Title, "…"))
+	})
+}
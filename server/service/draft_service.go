@@ -0,0 +1,212 @@
+package service
+
+import (
+	"errors"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"time"
+)
+
+const (
+	// schedulerLease is the lease the instance publishing scheduled drafts holds
+	schedulerLease = "scheduler"
+	// publishBatchSize is the number of due drafts published per run
+	publishBatchSize = 100
+)
+
+type draftService struct {
+	DraftRepository model.DraftRepository
+	LeaseRepository model.LeaseRepository
+	PostService     model.PostService
+}
+
+// DSConfig will hold repositories that will eventually be injected into this
+// this service layer
+type DSConfig struct {
+	DraftRepository model.DraftRepository
+	LeaseRepository model.LeaseRepository
+	PostService     model.PostService
+}
+
+// NewDraftService is a factory function for
+// initializing a DraftService with its repository layer dependencies
+func NewDraftService(c *DSConfig) model.DraftService {
+	return &draftService{
+		DraftRepository: c.DraftRepository,
+		LeaseRepository: c.LeaseRepository,
+		PostService:     c.PostService,
+	}
+}
+
+// GetDrafts returns the drafts of the user, newest first
+func (s *draftService) GetDrafts(userId string, page model.Page) (*[]model.Draft, error) {
+	return s.DraftRepository.FindByUser(userId, page)
+}
+
+// FindDraft returns the draft if it belongs to the user.
+// Drafts of other users don't exist as far as the user is concerned.
+func (s *draftService) FindDraft(userId, id string) (*model.Draft, error) {
+	draft, err := s.DraftRepository.FindByID(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if draft.UserID != userId {
+		return nil, apperrors.NewNotFound("draft", id)
+	}
+
+	return draft, nil
+}
+
+// CreateDraft saves the draft and schedules it if it has a time
+func (s *draftService) CreateDraft(draft *model.Draft) (*model.Draft, error) {
+	if err := validateSchedule(draft.ScheduledAt); err != nil {
+		return nil, err
+	}
+
+	id, err := GenerateId()
+
+	if err != nil {
+		log.Printf("Unable to create draft for user: %v\n", draft.UserID)
+		return nil, apperrors.NewInternal()
+	}
+
+	draft.ID = id
+	draft.Status = draftStatus(draft.ScheduledAt)
+
+	if err := s.DraftRepository.Create(draft); err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// UpdateDraft replaces the text and schedule of the draft.
+// Failed drafts get a new attempt, published ones can't be changed anymore.
+func (s *draftService) UpdateDraft(draft *model.Draft, text string, scheduledAt *time.Time) (*model.Draft, error) {
+	if !draft.IsEditable() {
+		return nil, apperrors.NewForbidden("the draft got published already")
+	}
+
+	if err := validateSchedule(scheduledAt); err != nil {
+		return nil, err
+	}
+
+	draft.Text = text
+	draft.ScheduledAt = scheduledAt
+	draft.Status = draftStatus(scheduledAt)
+	draft.Error = nil
+
+	if err := s.DraftRepository.Update(draft); err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// DeleteDraft removes the draft. The post of a published draft stays.
+func (s *draftService) DeleteDraft(draft *model.Draft) error {
+	if draft.Status == model.DraftPublishing {
+		return apperrors.NewForbidden("the draft is being published")
+	}
+
+	return s.DraftRepository.Delete(draft)
+}
+
+// PublishScheduled publishes the due drafts if the holder gets the scheduler's lease.
+// Each draft gets claimed before its post gets created, so even if two instances
+// hold the lease at once, because one of them stalled past its lease, every draft only gets published once.
+// Drafts that can't be published are marked as failed together with the reason.
+func (s *draftService) PublishScheduled(holder string, lease time.Duration) (*model.PublishReport, error) {
+	report := &model.PublishReport{}
+
+	leader, err := s.LeaseRepository.Acquire(schedulerLease, holder, lease)
+
+	if err != nil || !leader {
+		return report, err
+	}
+	report.Leader = true
+
+	// Instances that stopped while publishing leave their drafts behind
+	if report.Expired, err = s.DraftRepository.ExpirePublishing(time.Now().Add(-model.DraftPublishTimeout)); err != nil {
+		return report, err
+	}
+
+	drafts, err := s.DraftRepository.FindDue(time.Now(), publishBatchSize)
+
+	if err != nil {
+		return report, err
+	}
+
+	for i := range drafts {
+		draft := &drafts[i]
+		claimed, err := s.DraftRepository.Claim(draft.ID)
+
+		if err != nil {
+			return report, err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		text := draft.Text
+		post, err := s.PostService.CreatePost(&model.Post{UserID: draft.UserID, Text: &text})
+
+		if err != nil {
+			report.Failed++
+			log.Printf("Unable to publish draft: %v\n%v", draft.ID, err)
+
+			if err := s.DraftRepository.MarkFailed(draft.ID, failureReason(err)); err != nil {
+				return report, err
+			}
+			continue
+		}
+
+		// Drafts that stay publishing expire with a note to check for the post
+		if err := s.DraftRepository.MarkPublished(draft.ID, post.ID); err != nil {
+			return report, err
+		}
+
+		report.Published++
+	}
+
+	return report, nil
+}
+
+// validateSchedule makes sure scheduled drafts are due in the future
+func validateSchedule(scheduledAt *time.Time) error {
+	if scheduledAt == nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	if !scheduledAt.After(now) {
+		return apperrors.NewBadRequest("the scheduled time must be in the future")
+	}
+
+	if scheduledAt.After(now.Add(model.DraftMaxSchedule)) {
+		return apperrors.NewBadRequest("posts can only be scheduled up to a year ahead")
+	}
+
+	return nil
+}
+
+func draftStatus(scheduledAt *time.Time) string {
+	if scheduledAt == nil {
+		return model.DraftSaved
+	}
+	return model.DraftScheduled
+}
+
+// failureReason is the message of errors meant for users and a generic one for the rest
+func failureReason(err error) string {
+	var e *apperrors.Error
+	if errors.As(err, &e) && e.Type != apperrors.Internal {
+		return e.Message
+	}
+	return "the post could not be published"
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDraftService_FindDraft(t *testing.T) {
+	t.Run("Own draft", func(t *testing.T) {
+		draft := &model.Draft{ID: fixture.RandID(), UserID: fixture.RandID(), Text: "Later", Status: model.DraftSaved}
+
+		mockDraftRepository := new(mocks.DraftRepository)
+		ds := NewDraftService(&DSConfig{
+			DraftRepository: mockDraftRepository,
+		})
+		mockDraftRepository.On("FindByID", draft.ID).Return(draft, nil)
+
+		found, err := ds.FindDraft(draft.UserID, draft.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, draft, found)
+	})
+
+	t.Run("Draft of another user", func(t *testing.T) {
+		draft := &model.Draft{ID: fixture.RandID(), UserID: fixture.RandID(), Text: "Later", Status: model.DraftSaved}
+
+		mockDraftRepository := new(mocks.DraftRepository)
+		ds := NewDraftService(&DSConfig{
+			DraftRepository: mockDraftRepository,
+		})
+		mockDraftRepository.On("FindByID", draft.ID).Return(draft, nil)
+
+		found, err := ds.FindDraft(fixture.RandID(), draft.ID)
+
+		assert.Nil(t, found)
+		assert.Equal(t, apperrors.NewNotFound("draft", draft.ID), err)
+	})
+}
+
+func TestDraftService_CreateDraft(t *testing.T) {
+	t.Run("Saves a draft", func(t *testing.T) {
+		draft := &model.Draft{UserID: fixture.RandID(), Text: "Later"}
+
+		mockDraftRepository := new(mocks.DraftRepository)
+		ds := NewDraftService(&DSConfig{
+			DraftRepository: mockDraftRepository,
+		})
+		mockDraftRepository.On("Create", draft).Return(nil)
+
+		created, err := ds.CreateDraft(draft)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, created.ID)
+		assert.Equal(t, model.DraftSaved, created.Status)
+		mockDraftRepository.AssertExpectations(t)
+	})
+
+	t.Run("Schedules a draft", func(t *testing.T) {
+		at := time.Now().Add(time.Hour)
+		draft := &model.Draft{UserID: fixture.RandID(), Text: "Later", ScheduledAt: &at}
+
+		mockDraftRepository := new(mocks.DraftRepository)
+		ds := NewDraftService(&DSConfig{
+			DraftRepository: mockDraftRepository,
+		})
+		mockDraftRepository.On("Create", draft).Return(nil)
+
+		created, err := ds.CreateDraft(draft)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.DraftScheduled, created.Status)
+	})
+
+	t.Run("Rejects times outside the schedule", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		far := time.Now().Add(model.DraftMaxSchedule + time.Hour)
+
+		for _, at := range []time.Time{past, far} {
+			at := at
+			mockDraftRepository := new(mocks.DraftRepository)
+			ds := NewDraftService(&DSConfig{
+				DraftRepository: mockDraftRepository,
+			})
+
+			created, err := ds.CreateDraft(&model.Draft{UserID: fixture.RandID(), Text: "Later", ScheduledAt: &at})
+
+			assert.Nil(t, created)
+			assert.Equal(t, http.StatusBadRequest, apperrors.Status(err))
+			mockDraftRepository.AssertNotCalled(t, "Create", mock.Anything)
+		}
+	})
+}
+
+func TestDraftService_UpdateDraft(t *testing.T) {
+	t.Run("Retries a failed draft", func(t *testing.T) {
+		reason := "the post could not be published"
+		draft := &model.Draft{ID: fixture.RandID(), UserID: fixture.RandID(), Text: "Later", Status: model.DraftFailed, Error: &reason}
+		at := time.Now().Add(time.Hour)
+
+		mockDraftRepository := new(mocks.DraftRepository)
+		ds := NewDraftService(&DSConfig{
+			DraftRepository: mockDraftRepository,
+		})
+		mockDraftRepository.On("Update", draft).Return(nil)
+
+		updated, err := ds.UpdateDraft(draft, "Soon", &at)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Soon", updated.Text)
+		assert.Equal(t, &at, updated.ScheduledAt)
+		assert.Equal(t, model.DraftScheduled, updated.Status)
+		assert.Nil(t, updated.Error)
+		mockDraftRepository.AssertExpectations(t)
+	})
+
+	t.Run("Unschedules a draft", func(t *testing.T) {
+		at := time.Now().Add(time.Hour)
+		draft := &model.Draft{ID: fixture.RandID(), UserID: fixture.RandID(), Text: "Later", Status: model.DraftScheduled, ScheduledAt: &at}
+
+		mockDraftRepository := new(mocks.DraftRepository)
+		ds := NewDraftService(&DSConfig{
+			DraftRepository: mockDraftRepository,
+		})
+		mockDraftRepository.On("Update", draft).Return(nil)
+
+		updated, err := ds.UpdateDraft(draft, "Later", nil)
+
+		assert.NoError(t, err)
+		assert.Nil(t, updated.ScheduledAt)
+		assert.Equal(t, model.DraftSaved, updated.Status)
+	})
+
+	t.Run("Published drafts can't be changed", func(t *testing.T) {
+		postId := fixture.RandID()
+		draft := &model.Draft{ID: fixture.RandID(), UserID: fixture.RandID(), Text: "Later", Status: model.DraftPublished, PostID: &postId}
+
+		mockDraftRepository := new(mocks.DraftRepository)
+		ds := NewDraftService(&DSConfig{
+			DraftRepository: mockDraftRepository,
+		})
+
+		updated, err := ds.UpdateDraft(draft, "Soon", nil)
+
+		assert.Nil(t, updated)
+		assert.Equal(t, http.StatusForbidden, apperrors.Status(err))
+		mockDraftRepository.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestDraftService_DeleteDraft(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		draft := &model.Draft{ID: fixture.RandID(), UserID: fixture.RandID(), Text: "Later", Status: model.DraftSaved}
+
+		mockDraftRepository := new(mocks.DraftRepository)
+		ds := NewDraftService(&DSConfig{
+			DraftRepository: mockDraftRepository,
+		})
+		mockDraftRepository.On("Delete", draft).Return(nil)
+
+		err := ds.DeleteDraft(draft)
+
+		assert.NoError(t, err)
+		mockDraftRepository.AssertExpectations(t)
+	})
+
+	t.Run("Draft being published", func(t *testing.T) {
+		draft := &model.Draft{ID: fixture.RandID(), UserID: fixture.RandID(), Text: "Later", Status: model.DraftPublishing}
+
+		mockDraftRepository := new(mocks.DraftRepository)
+		ds := NewDraftService(&DSConfig{
+			DraftRepository: mockDraftRepository,
+		})
+
+		err := ds.DeleteDraft(draft)
+
+		assert.Equal(t, http.StatusForbidden, apperrors.Status(err))
+		mockDraftRepository.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+}
+
+func TestDraftService_PublishScheduled(t *testing.T) {
+	holder := "instance"
+	lease := time.Minute
+
+	t.Run("Only the leader publishes", func(t *testing.T) {
+		mockDraftRepository := new(mocks.DraftRepository)
+		mockLeaseRepository := new(mocks.LeaseRepository)
+		mockPostService := new(mocks.PostService)
+		ds := NewDraftService(&DSConfig{
+			DraftRepository: mockDraftRepository,
+			LeaseRepository: mockLeaseRepository,
+			PostService:     mockPostService,
+		})
+		mockLeaseRepository.On("Acquire", "scheduler", holder, lease).Return(false, nil)
+
+		report, err := ds.PublishScheduled(holder, lease)
+
+		assert.NoError(t, err)
+		assert.False(t, report.Leader)
+		mockDraftRepository.AssertNotCalled(t, "FindDue", mock.Anything, mock.Anything)
+		mockPostService.AssertNotCalled(t, "CreatePost", mock.Anything)
+	})
+
+	t.Run("Publishes the claimed drafts", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		published := model.Draft{ID: fixture.RandID(), UserID: fixture.RandID(), Text: "Now", Status: model.DraftScheduled, ScheduledAt: &past}
+		taken := model.Draft{ID: fixture.RandID(), UserID: fixture.RandID(), Text: "Taken", Status: model.DraftScheduled, ScheduledAt: &past}
+		rejected := model.Draft{ID: fixture.RandID(), UserID: fixture.RandID(), Text: "Rejected", Status: model.DraftScheduled, ScheduledAt: &past}
+		post := fixture.GetMockPost()
+
+		mockDraftRepository := new(mocks.DraftRepository)
+		mockLeaseRepository := new(mocks.LeaseRepository)
+		mockPostService := new(mocks.PostService)
+		ds := NewDraftService(&DSConfig{
+			DraftRepository: mockDraftRepository,
+			LeaseRepository: mockLeaseRepository,
+			PostService:     mockPostService,
+		})
+		mockLeaseRepository.On("Acquire", "scheduler", holder, lease).Return(true, nil)
+		mockDraftRepository.On("ExpirePublishing", mock.AnythingOfType("time.Time")).Return(1, nil)
+		mockDraftRepository.On("FindDue", mock.AnythingOfType("time.Time"), publishBatchSize).
+			Return([]model.Draft{published, taken, rejected}, nil)
+		mockDraftRepository.On("Claim", published.ID).Return(true, nil)
+		mockDraftRepository.On("Claim", taken.ID).Return(false, nil)
+		mockDraftRepository.On("Claim", rejected.ID).Return(true, nil)
+
+		mockPostService.On("CreatePost", mock.MatchedBy(func(p *model.Post) bool {
+			return p.UserID == published.UserID && *p.Text == published.Text
+		})).Return(post, nil)
+		rejection := apperrors.NewBadRequest("text is too long")
+		mockPostService.On("CreatePost", mock.MatchedBy(func(p *model.Post) bool {
+			return p.UserID == rejected.UserID
+		})).Return(nil, rejection)
+
+		mockDraftRepository.On("MarkPublished", published.ID, post.ID).Return(nil)
+		mockDraftRepository.On("MarkFailed", rejected.ID, rejection.Message).Return(nil)
+
+		report, err := ds.PublishScheduled(holder, lease)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &model.PublishReport{Leader: true, Expired: 1, Published: 1, Failed: 1}, report)
+		mockDraftRepository.AssertExpectations(t)
+		mockPostService.AssertNumberOfCalls(t, "CreatePost", 2)
+	})
+
+	t.Run("Internal errors get a generic reason", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		draft := model.Draft{ID: fixture.RandID(), UserID: fixture.RandID(), Text: "Now", Status: model.DraftScheduled, ScheduledAt: &past}
+
+		mockDraftRepository := new(mocks.DraftRepository)
+		mockLeaseRepository := new(mocks.LeaseRepository)
+		mockPostService := new(mocks.PostService)
+		ds := NewDraftService(&DSConfig{
+			DraftRepository: mockDraftRepository,
+			LeaseRepository: mockLeaseRepository,
+			PostService:     mockPostService,
+		})
+		mockLeaseRepository.On("Acquire", "scheduler", holder, lease).Return(true, nil)
+		mockDraftRepository.On("ExpirePublishing", mock.AnythingOfType("time.Time")).Return(0, nil)
+		mockDraftRepository.On("FindDue", mock.AnythingOfType("time.Time"), publishBatchSize).Return([]model.Draft{draft}, nil)
+		mockDraftRepository.On("Claim", draft.ID).Return(true, nil)
+		mockPostService.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, apperrors.NewInternal())
+		mockDraftRepository.On("MarkFailed", draft.ID, "the post could not be published").Return(nil)
+
+		report, err := ds.PublishScheduled(holder, lease)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Failed)
+		mockDraftRepository.AssertExpectations(t)
+	})
+
+	t.Run("Lease error", func(t *testing.T) {
+		mockDraftRepository := new(mocks.DraftRepository)
+		mockLeaseRepository := new(mocks.LeaseRepository)
+		ds := NewDraftService(&DSConfig{
+			DraftRepository: mockDraftRepository,
+			LeaseRepository: mockLeaseRepository,
+		})
+		mockErr := apperrors.NewInternal()
+		mockLeaseRepository.On("Acquire", "scheduler", holder, lease).Return(false, mockErr)
+
+		_, err := ds.PublishScheduled(holder, lease)
+
+		assert.Equal(t, mockErr, err)
+		mockDraftRepository.AssertNotCalled(t, "ExpirePublishing", mock.Anything)
+	})
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/model"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxMentionLength is the longest username that can be registered
+const maxMentionLength = 15
+
+var urlPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+`)
+
+// ExtractEntities finds the hashtags, mentions and URLs in the text.
+// Hashtags and mentions have to start a word, so emails and fragments of URLs don't count.
+func ExtractEntities(text string) model.Entities {
+	entities := make(model.Entities, 0)
+
+	urls := make([][2]int, 0)
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		value := trimURL(text[loc[0]:loc[1]])
+		scheme := strings.Index(value, "://") + len("://")
+		if len(value) == scheme {
+			continue
+		}
+
+		// Only the scheme and host are case-insensitive
+		host := scheme + hostLength(value[scheme:])
+		end := loc[0] + len(value)
+		urls = append(urls, [2]int{loc[0], end})
+		entities = append(entities, model.Entity{
+			Type:       model.EntityURL,
+			Text:       value,
+			Normalized: strings.ToLower(value[:host]) + value[host:],
+			Start:      loc[0],
+			End:        end,
+		})
+	}
+
+	prev := rune(0)
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+
+		if (r == '#' || r == '＃' || r == '@' || r == '＠') && !isWordRune(prev) && prev != '&' && !insideURL(urls, i) {
+			start := i + size
+			var entity *model.Entity
+			var end int
+
+			if r == '#' || r == '＃' {
+				end = scanRunes(text, start, isWordRune)
+				if tag := text[start:end]; strings.IndexFunc(tag, unicode.IsLetter) >= 0 {
+					entity = &model.Entity{Type: model.EntityHashtag, Text: tag, Normalized: strings.ToLower(tag)}
+				}
+			} else {
+				end = scanRunes(text, start, isUsernameRune)
+				next, _ := utf8.DecodeRuneInString(text[end:])
+				if name := text[start:end]; name != "" && len(name) <= maxMentionLength && !isWordRune(next) && next != '@' {
+					entity = &model.Entity{Type: model.EntityMention, Text: name, Normalized: strings.ToLower(name)}
+				}
+			}
+
+			if entity != nil {
+				entity.Start = i
+				entity.End = end
+				entities = append(entities, *entity)
+
+				prev, _ = utf8.DecodeLastRuneInString(text[:end])
+				i = end
+				continue
+			}
+		}
+
+		prev = r
+		i += size
+	}
+
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].Start < entities[j].Start
+	})
+
+	setUTF16Offsets(text, entities)
+
+	return entities
+}
+
+// trimURL drops punctuation that ends the sentence rather than the URL.
+// Closing brackets are kept if the URL opened them, as in wiki links.
+func trimURL(value string) string {
+	for len(value) > 0 {
+		last, size := utf8.DecodeLastRuneInString(value)
+		switch last {
+		case '.', ',', ':', ';', '!', '?', '\'', '*':
+		case ')':
+			if strings.Count(value, "(") >= strings.Count(value, ")") {
+				return value
+			}
+		case ']':
+			if strings.Count(value, "[") >= strings.Count(value, "]") {
+				return value
+			}
+		default:
+			return value
+		}
+		value = value[:len(value)-size]
+	}
+	return value
+}
+
+// hostLength returns the length of the host part of a URL without its scheme
+func hostLength(value string) int {
+	if end := strings.IndexAny(value, "/?#"); end >= 0 {
+		return end
+	}
+	return len(value)
+}
+
+func insideURL(urls [][2]int, index int) bool {
+	for _, url := range urls {
+		if index >= url[0] && index < url[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func scanRunes(text string, start int, matches func(rune) bool) int {
+	end := start
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if !matches(r) {
+			break
+		}
+		end += size
+	}
+	return end
+}
+
+// isWordRune reports whether the rune can be part of a hashtag.
+// Joiners are needed by some scripts and emoji sequences.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_' || r == '\u200c' || r == '\u200d'
+}
+
+func isUsernameRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// setUTF16Offsets converts the byte offsets of the entities for clients counting UTF-16 code units
+func setUTF16Offsets(text string, entities model.Entities) {
+	offsets := make(map[int]int, len(entities)*2)
+	for _, entity := range entities {
+		offsets[entity.Start] = 0
+		offsets[entity.End] = 0
+	}
+
+	units := 0
+	for i, r := range text {
+		if _, ok := offsets[i]; ok {
+			offsets[i] = units
+		}
+		if r >= 0x10000 {
+			units += 2
+		} else {
+			units++
+		}
+	}
+	if _, ok := offsets[len(text)]; ok {
+		offsets[len(text)] = units
+	}
+
+	for i := range entities {
+		entities[i].UTF16Start = offsets[entities[i].Start]
+		entities[i].UTF16End = offsets[entities[i].End]
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExtractEntities(t *testing.T) {
+	t.Run("Returns an empty array if no entities", func(t *testing.T) {
+		entities := ExtractEntities(fixture.RandStr(120))
+		assert.Empty(t, entities)
+	})
+
+	t.Run("Ignores hashtags and mentions within words", func(t *testing.T) {
+		entities := ExtractEntities("This is a test# pos#t, mail me at bob@example.com &#39; or #123")
+		assert.Empty(t, entities)
+	})
+
+	t.Run("Hashtags end at punctuation and whitespace", func(t *testing.T) {
+		entities := ExtractEntities("#go, #Tag\n#tab\t#end.")
+		assert.Equal(t, []string{"go", "tag", "tab", "end"}, entities.Hashtags())
+		assert.Equal(t, "Tag", entities[1].Text)
+	})
+
+	t.Run("Supports non-Latin hashtags", func(t *testing.T) {
+		entities := ExtractEntities("#日本語 #Über #भारत")
+		assert.Equal(t, []string{"日本語", "über", "भारत"}, entities.Hashtags())
+	})
+
+	t.Run("Finds mentions", func(t *testing.T) {
+		entities := ExtractEntities("Hi @Alice! And @bob, @thisnameiswaytoolong")
+		assert.Equal(t, []string{"alice", "bob"}, entities.Mentions())
+	})
+
+	t.Run("Finds URLs without trailing punctuation", func(t *testing.T) {
+		entities := ExtractEntities("See HTTPS://Example.com/Path?q=1#top. (https://en.wikipedia.org/wiki/Go_(language))")
+		assert.Len(t, entities, 2)
+		assert.Equal(t, model.EntityURL, entities[0].Type)
+		assert.Equal(t, "HTTPS://Example.com/Path?q=1#top", entities[0].Text)
+		assert.Equal(t, "https://example.com/Path?q=1#top", entities[0].Normalized)
+		assert.Equal(t, "https://en.wikipedia.org/wiki/Go_(language)", entities[1].Text)
+	})
+
+	t.Run("Returns byte and UTF-16 offsets", func(t *testing.T) {
+		text := "😀 é #go @bob"
+		entities := ExtractEntities(text)
+
+		assert.Equal(t, model.Entities{
+			{Type: model.EntityHashtag, Text: "go", Normalized: "go", Start: 8, End: 11, UTF16Start: 5, UTF16End: 8},
+			{Type: model.EntityMention, Text: "bob", Normalized: "bob", Start: 12, End: 16, UTF16Start: 9, UTF16End: 13},
+		}, entities)
+		assert.Equal(t, "#go", text[entities[0].Start:entities[0].End])
+	})
+}
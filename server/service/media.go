@@ -0,0 +1,48 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/sentrionic/mirage/model"
+	"io"
+	"mime/multipart"
+)
+
+// hashFile returns the hex encoded sha256 hash of the uploaded file's content
+func hashFile(header *multipart.FileHeader) (string, error) {
+	file, err := header.Open()
+
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// releaseMedia removes a reference from the media for the given ID
+// and deletes its objects from the bucket once nothing uses it anymore
+func releaseMedia(mediaRepository model.MediaRepository, fileRepository model.FileRepository, id string) error {
+	return mediaRepository.Release(id, func(keys []string) error {
+		return deleteObjects(fileRepository, keys)
+	})
+}
+
+// deleteObjects deletes the objects for the given keys from the bucket
+func deleteObjects(fileRepository model.FileRepository, keys []string) error {
+	for _, key := range keys {
+		if err := fileRepository.DeleteImage(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/model"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// objectPrefix is the prefix all uploaded objects are stored under
+	objectPrefix = "files/"
+	// mediaGCLease is the lease the instance collecting orphaned media holds
+	mediaGCLease = "media-gc"
+)
+
+type mediaService struct {
+	MediaRepository model.MediaRepository
+	FileRepository  model.FileRepository
+	LeaseRepository model.LeaseRepository
+}
+
+// MSConfig will hold repositories that will eventually be injected into this
+// this service layer
+type MSConfig struct {
+	MediaRepository model.MediaRepository
+	FileRepository  model.FileRepository
+	LeaseRepository model.LeaseRepository
+}
+
+// NewMediaService is a factory function for
+// initializing a MediaService with its repository layer dependencies
+func NewMediaService(c *MSConfig) model.MediaService {
+	return &mediaService{
+		MediaRepository: c.MediaRepository,
+		FileRepository:  c.FileRepository,
+		LeaseRepository: c.LeaseRepository,
+	}
+}
+
+// CollectGarbage deletes all uploaded objects that are older than the grace period
+// and not referenced by any file, media or user anymore, if the holder gets the lease of the job.
+// Media that nothing used during the grace period gets its references reconciled first,
+// so the objects of abandoned uploads get collected as well.
+// In dry run mode nothing gets reconciled and the orphaned objects only get reported.
+func (s *mediaService) CollectGarbage(holder string, lease, grace time.Duration, dryRun bool) (*model.GarbageReport, error) {
+	leader, err := s.LeaseRepository.Acquire(mediaGCLease, holder, lease)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !leader {
+		return &model.GarbageReport{DryRun: dryRun, Orphaned: make([]string, 0)}, nil
+	}
+
+	cutoff := time.Now().Add(-grace)
+
+	reconciled, unused := 0, 0
+	if !dryRun {
+		if reconciled, unused, err = s.MediaRepository.Reconcile(cutoff); err != nil {
+			return nil, err
+		}
+	}
+
+	// Objects get listed first, so uploads that finish in between
+	// are either referenced already or younger than the grace period
+	objects, err := s.FileRepository.ListObjects(objectPrefix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := s.MediaRepository.FindReferences()
+
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := make(map[string]bool)
+	for _, ref := range refs {
+		if key := objectKey(ref); key != "" {
+			referenced[key] = true
+		}
+	}
+
+	report := model.GarbageReport{
+		Leader:     true,
+		DryRun:     dryRun,
+		Reconciled: reconciled,
+		Unused:     unused,
+		Scanned:    len(objects),
+		Orphaned:   make([]string, 0),
+	}
+
+	for _, object := range objects {
+		switch {
+		case referenced[object.Key]:
+			report.Referenced++
+		case object.LastModified.After(cutoff):
+			report.Recent++
+		default:
+			report.Orphaned = append(report.Orphaned, object.Key)
+		}
+	}
+
+	if dryRun {
+		return &report, nil
+	}
+
+	for _, key := range report.Orphaned {
+		if err := s.FileRepository.DeleteImage(key); err != nil {
+			log.Printf("Unable to delete orphaned object: %v\n%v", key, err)
+			report.Failed++
+			continue
+		}
+		report.Deleted++
+	}
+
+	return &report, nil
+}
+
+// objectKey returns the bucket key for the given key or url.
+// It returns an empty string for urls that don't point into the bucket, like gravatars.
+func objectKey(ref string) string {
+	if !strings.Contains(ref, "://") {
+		return ref
+	}
+
+	u, err := url.Parse(ref)
+
+	if err != nil {
+		return ""
+	}
+
+	// Path style urls contain the bucket name before the key
+	i := strings.Index(u.Path, "/"+objectPrefix)
+
+	if i == -1 {
+		return ""
+	}
+
+	return u.Path[i+1:]
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"fmt"
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestMediaService_CollectGarbage(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+
+	objects := []model.StoredObject{
+		{Key: "files/media/hash_original.jpeg", LastModified: old},
+		{Key: "files/media//legacy.png", LastModified: old},
+		{Key: "files/profile_images/avatar.jpeg", LastModified: old},
+		{Key: "files/header_photo/orphan.jpeg", LastModified: old},
+		{Key: "files/media/orphan_original.jpeg", LastModified: old},
+		{Key: "files/media/uploading_original.jpeg", LastModified: time.Now()},
+	}
+
+	refs := []string{
+		"files/media/hash_original.jpeg",
+		"https://bucket.s3.region.amazonaws.com/files/media//legacy.png",
+		"https://s3.region.amazonaws.com/bucket/files/profile_images/avatar.jpeg",
+		"https://gravatar.com/avatar/hash?d=identicon",
+	}
+
+	orphaned := []string{"files/header_photo/orphan.jpeg", "files/media/orphan_original.jpeg"}
+
+	t.Run("Dry run only reports", func(t *testing.T) {
+		mockMediaRepository := new(mocks.MediaRepository)
+		mockFileRepository := new(mocks.FileRepository)
+		mockLeaseRepository := new(mocks.LeaseRepository)
+		ms := NewMediaService(&MSConfig{
+			MediaRepository: mockMediaRepository,
+			FileRepository:  mockFileRepository,
+			LeaseRepository: mockLeaseRepository,
+		})
+
+		mockLeaseRepository.On("Acquire", "media-gc", "holder", time.Hour).Return(true, nil)
+
+		mockFileRepository.On("ListObjects", "files/").Return(objects, nil)
+		mockMediaRepository.On("FindReferences").Return(refs, nil)
+
+		report, err := ms.CollectGarbage("holder", time.Hour, 24*time.Hour, true)
+
+		assert.NoError(t, err)
+		assert.True(t, report.Leader)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 6, report.Scanned)
+		assert.Equal(t, 3, report.Referenced)
+		assert.Equal(t, 1, report.Recent)
+		assert.Equal(t, orphaned, report.Orphaned)
+		assert.Equal(t, 0, report.Deleted)
+		mockMediaRepository.AssertNotCalled(t, "Reconcile", mock.Anything)
+		mockFileRepository.AssertNotCalled(t, "DeleteImage", mock.Anything)
+	})
+
+	t.Run("Deletes orphaned objects", func(t *testing.T) {
+		mockMediaRepository := new(mocks.MediaRepository)
+		mockFileRepository := new(mocks.FileRepository)
+		mockLeaseRepository := new(mocks.LeaseRepository)
+		ms := NewMediaService(&MSConfig{
+			MediaRepository: mockMediaRepository,
+			FileRepository:  mockFileRepository,
+			LeaseRepository: mockLeaseRepository,
+		})
+
+		mockLeaseRepository.On("Acquire", "media-gc", "holder", time.Hour).Return(true, nil)
+
+		mockMediaRepository.On("Reconcile", mock.AnythingOfType("time.Time")).Return(1, 2, nil)
+		mockFileRepository.On("ListObjects", "files/").Return(objects, nil)
+		mockMediaRepository.On("FindReferences").Return(refs, nil)
+		mockFileRepository.On("DeleteImage", orphaned[0]).Return(nil)
+		mockFileRepository.On("DeleteImage", orphaned[1]).Return(fmt.Errorf("some error down the call chain"))
+
+		report, err := ms.CollectGarbage("holder", time.Hour, 24*time.Hour, false)
+
+		assert.NoError(t, err)
+		assert.False(t, report.DryRun)
+		assert.Equal(t, 1, report.Reconciled)
+		assert.Equal(t, 2, report.Unused)
+		assert.Equal(t, 1, report.Deleted)
+		assert.Equal(t, 1, report.Failed)
+		mockFileRepository.AssertExpectations(t)
+	})
+
+	t.Run("FindReferences Error", func(t *testing.T) {
+		mockMediaRepository := new(mocks.MediaRepository)
+		mockFileRepository := new(mocks.FileRepository)
+		mockLeaseRepository := new(mocks.LeaseRepository)
+		ms := NewMediaService(&MSConfig{
+			MediaRepository: mockMediaRepository,
+			FileRepository:  mockFileRepository,
+			LeaseRepository: mockLeaseRepository,
+		})
+
+		mockLeaseRepository.On("Acquire", "media-gc", "holder", time.Hour).Return(true, nil)
+
+		mockError := apperrors.NewInternal()
+		mockMediaRepository.On("Reconcile", mock.AnythingOfType("time.Time")).Return(0, 0, nil)
+		mockFileRepository.On("ListObjects", "files/").Return(objects, nil)
+		mockMediaRepository.On("FindReferences").Return(nil, mockError)
+
+		report, err := ms.CollectGarbage("holder", time.Hour, 24*time.Hour, false)
+
+		assert.Nil(t, report)
+		assert.Equal(t, mockError, err)
+		mockFileRepository.AssertNotCalled(t, "DeleteImage", mock.Anything)
+	})
+
+	t.Run("Skipped without the lease", func(t *testing.T) {
+		mockMediaRepository := new(mocks.MediaRepository)
+		mockFileRepository := new(mocks.FileRepository)
+		mockLeaseRepository := new(mocks.LeaseRepository)
+		ms := NewMediaService(&MSConfig{
+			MediaRepository: mockMediaRepository,
+			FileRepository:  mockFileRepository,
+			LeaseRepository: mockLeaseRepository,
+		})
+
+		mockLeaseRepository.On("Acquire", "media-gc", "holder", time.Hour).Return(false, nil)
+
+		report, err := ms.CollectGarbage("holder", time.Hour, 24*time.Hour, false)
+
+		assert.NoError(t, err)
+		assert.False(t, report.Leader)
+		mockFileRepository.AssertNotCalled(t, "ListObjects", mock.Anything)
+		mockFileRepository.AssertNotCalled(t, "DeleteImage", mock.Anything)
+	})
+}
@@ -1,32 +1,56 @@
 package service
 
 import (
-	"github.com/lucsky/cuid"
 	"github.com/sentrionic/mirage/model"
 	"github.com/sentrionic/mirage/model/apperrors"
 	"log"
 	"mime/multipart"
 	"path"
+	"time"
 )
 
 type postService struct {
-	PostRepository model.PostRepository
-	FileRepository model.FileRepository
+	PostRepository      model.PostRepository
+	UserRepository      model.UserRepository
+	FileRepository      model.FileRepository
+	MediaRepository     model.MediaRepository
+	TimelineRepository  model.TimelineRepository
+	TypeaheadRepository model.TypeaheadRepository
+	TrendRepository     model.TrendRepository
+	CardRepository      model.CardRepository
+	CardFetcher         model.CardFetcher
+	EditWindow          time.Duration
 }
 
 // PSConfig will hold repositories that will eventually be injected into this
 // this service layer
 type PSConfig struct {
-	PostRepository model.PostRepository
-	FileRepository model.FileRepository
+	PostRepository      model.PostRepository
+	UserRepository      model.UserRepository
+	FileRepository      model.FileRepository
+	MediaRepository     model.MediaRepository
+	TimelineRepository  model.TimelineRepository
+	TypeaheadRepository model.TypeaheadRepository
+	TrendRepository     model.TrendRepository
+	CardRepository      model.CardRepository
+	CardFetcher         model.CardFetcher
+	EditWindow          time.Duration
 }
 
 // NewPostService is a factory function for
 // initializing a PostService with its repository layer dependencies
 func NewPostService(c *PSConfig) model.PostService {
 	return &postService{
-		PostRepository: c.PostRepository,
-		FileRepository: c.FileRepository,
+		PostRepository:      c.PostRepository,
+		UserRepository:      c.UserRepository,
+		FileRepository:      c.FileRepository,
+		MediaRepository:     c.MediaRepository,
+		TimelineRepository:  c.TimelineRepository,
+		TypeaheadRepository: c.TypeaheadRepository,
+		TrendRepository:     c.TrendRepository,
+		CardRepository:      c.CardRepository,
+		CardFetcher:         c.CardFetcher,
+		EditWindow:          c.EditWindow,
 	}
 }
 
@@ -43,56 +67,405 @@ func (p *postService) CreatePost(post *model.Post) (*model.Post, error) {
 	}
 	post.ID = id
 
-	if post.Text != nil {
-		post.HashTags = GetHashtags(*post.Text)
+	if post.ReplyPolicy == "" {
+		post.ReplyPolicy = model.ReplyEveryone
 	}
 
-	return p.PostRepository.Create(post)
+	if post.ReplyToID != nil {
+		if err := p.checkReply(post); err != nil {
+			p.releaseUpload(post)
+			return nil, err
+		}
+	}
+
+	if err := p.setEntities(post); err != nil {
+		p.releaseUpload(post)
+		return nil, err
+	}
+
+	p.linkCard(post)
+
+	created, err := p.PostRepository.Create(post)
+
+	if err != nil {
+		p.releaseUpload(post)
+		return nil, err
+	}
+
+	p.fetchCard(post)
+
+	// Timelines get rebuilt from the database if they miss a post, so this must not fail the request
+	entry := model.TimelineEntry{PostID: created.ID, CreatedAt: created.CreatedAt}
+	if err := fanOut(p.TimelineRepository, created.UserID, entry); err != nil {
+		log.Printf("Unable to add post to timelines: %v\n%v", created.ID, err)
+	}
+
+	p.countHashtags(created, created.HashTags, created.CreatedAt)
+
+	// Authors can always reply to their posts
+	created.CanReply = true
+
+	return created, nil
 }
 
-func (p *postService) DeletePost(post *model.Post) error {
+// checkReply makes sure the replied to post exists and its reply policy lets the author of the reply reply
+func (p *postService) checkReply(reply *model.Post) error {
+	post, err := p.PostRepository.FindByID(*reply.ReplyToID)
+
+	if err != nil {
+		return err
+	}
+
+	if err := p.PostRepository.LoadViewerState(reply.UserID, []*model.Post{post}); err != nil {
+		return err
+	}
+
+	if post.CanReply {
+		return nil
+	}
+
+	if post.ReplyPolicy == model.ReplyFollowing {
+		return apperrors.NewForbidden("only people the author follows can reply")
+	}
+
+	return apperrors.NewForbidden("only people mentioned in the post can reply")
+}
+
+// EditPost replaces the text and alt text of the post within the edit window.
+// The replaced version is kept in the history of the post.
+func (p *postService) EditPost(post *model.Post, text, altText *string) (*model.Post, error) {
+	if time.Since(post.CreatedAt) > p.EditWindow {
+		return nil, apperrors.NewForbidden("the post can't be edited anymore")
+	}
+
+	if text == nil && post.File == nil {
+		return nil, apperrors.NewBadRequest("text is required if the post has no file")
+	}
+
+	if altText != nil && post.File == nil {
+		return nil, apperrors.NewBadRequest("alt text requires a file")
+	}
+
+	previousTags := post.HashTags
+	previous := make(map[string]bool)
+	for _, tag := range previousTags {
+		previous[tag] = true
+	}
+
+	// The post only changes once the new version got saved
+	now := time.Now()
+	edited := *post
+	edited.Text = text
+	edited.Entities = nil
+	edited.HashTags = nil
+	edited.Mentions = nil
+	edited.CardURL = nil
+	edited.EditedAt = &now
+
 	if post.File != nil {
-		err := p.FileRepository.DeleteImage(post.File.Filename)
+		file := *post.File
+		file.AltText = altText
+		edited.File = &file
+	}
+
+	if err := p.setEntities(&edited); err != nil {
+		return nil, err
+	}
+
+	p.linkCard(&edited)
+
+	if err := p.PostRepository.Update(&edited); err != nil {
+		return nil, err
+	}
+
+	p.fetchCard(&edited)
+
+	current := make(map[string]bool)
+	added := make([]string, 0)
+	for _, tag := range edited.HashTags {
+		current[tag] = true
+		if !previous[tag] {
+			added = append(added, tag)
+		}
+	}
+	p.countHashtags(&edited, added, now)
+
+	removed := make([]string, 0)
+	for _, tag := range previousTags {
+		if !current[tag] {
+			removed = append(removed, tag)
+		}
+	}
+	p.uncountHashtags(&edited, removed)
+
+	return p.PostRepository.FindByID(post.ID)
+}
+
+// GetPostHistory returns the current and all previous versions of the post, newest first
+func (p *postService) GetPostHistory(post *model.Post) ([]model.PostVersion, error) {
+	versions, err := p.PostRepository.FindVersions(post.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]model.PostVersion{post.CurrentVersion()}, versions...), nil
+}
+
+// setEntities extracts the entities of the post's text and resolves its mentions.
+// Usernames that don't belong to anyone are just text.
+// Users can't block each other yet, so there is nothing else to skip.
+func (p *postService) setEntities(post *model.Post) error {
+	if post.Text == nil {
+		return nil
+	}
+
+	post.Entities = ExtractEntities(*post.Text)
+	post.HashTags = post.Entities.Hashtags()
+
+	if usernames := post.Entities.Mentions(); len(usernames) > 0 {
+		mentioned, err := p.UserRepository.FindByUsernames(usernames)
+
 		if err != nil {
 			return err
 		}
+		post.Mentions = *mentioned
 	}
 
-	return p.PostRepository.Delete(post)
+	return nil
 }
 
-func (p *postService) UploadFile(header *multipart.FileHeader) (*model.File, error) {
-	slug := cuid.New()
-	ext := path.Ext(header.Filename)
-	filename := slug + ext
-	mimetype := header.Header.Get("Content-Type")
+// linkCard links the card of the post's first URL.
+// Posts show the card once it's ready, so failing to link it only loses the preview.
+func (p *postService) linkCard(post *model.Post) {
+	link := post.Entities.FirstURL()
 
-	file := model.File{
-		FileType: mimetype,
-		Filename: filename,
+	if link == "" || len(link) > model.CardMaxURLLength {
+		return
 	}
 
-	id, err := GenerateId()
+	if err := p.CardRepository.Add(link); err != nil {
+		log.Printf("Unable to add card: %v\n%v", link, err)
+		return
+	}
+
+	post.CardURL = &link
+}
+
+// fetchCard claims the card linked by the saved post and fetches it in the background,
+// unless it got claimed for another post already
+func (p *postService) fetchCard(post *model.Post) {
+	if post.CardURL == nil {
+		return
+	}
+
+	link := *post.CardURL
+	claimed, err := p.CardRepository.Claim(link)
+
+	if err != nil {
+		log.Printf("Unable to claim card: %v\n%v", link, err)
+		return
+	}
+
+	if !claimed {
+		return
+	}
+
+	go func() {
+		if err := unfurl(p.CardRepository, p.CardFetcher, p.FileRepository, link); err != nil {
+			log.Printf("Unable to save card: %v\n%v", link, err)
+		}
+	}()
+}
+
+// countHashtags adds the hashtags of the post to the typeahead and the trends at the given time
+func (p *postService) countHashtags(post *model.Post, tags []string, at time.Time) {
+	if len(tags) == 0 {
+		return
+	}
+
+	if err := p.TypeaheadRepository.AddHashtags(tags); err != nil {
+		log.Printf("Unable to index hashtags of post: %v\n%v", post.ID, err)
+	}
+
+	if err := p.TrendRepository.AddHashtags(post.UserID, tags, at); err != nil {
+		log.Printf("Unable to count hashtags of post: %v\n%v", post.ID, err)
+	}
+}
+
+// uncountHashtags removes the hashtags of the post from the typeahead.
+// Trends only count recent uses, so they are left as they are.
+func (p *postService) uncountHashtags(post *model.Post, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+
+	if err := p.TypeaheadRepository.RemoveHashtags(tags); err != nil {
+		log.Printf("Unable to unindex hashtags of post: %v\n%v", post.ID, err)
+	}
+}
+
+// releaseUpload releases the media of a post that couldn't be created,
+// as the uploaded file would be left behind otherwise
+func (p *postService) releaseUpload(post *model.Post) {
+	if post.File != nil && post.File.MediaID != nil {
+		if err := releaseMedia(p.MediaRepository, p.FileRepository, *post.File.MediaID); err != nil {
+			log.Printf("Unable to release media: %v\n%v", *post.File.MediaID, err)
+		}
+	}
+}
+
+func (p *postService) DeletePost(post *model.Post) error {
+	if err := p.PostRepository.Delete(post); err != nil {
+		return err
+	}
+
+	p.uncountHashtags(post, post.HashTags)
+
+	// Timelines of the retweeters' followers drop the post once they fail to load it
+	if recipients, err := timelineRecipients(p.TimelineRepository, post.UserID); err != nil {
+		log.Printf("Unable to remove post from timelines: %v\n%v", post.ID, err)
+	} else if err := p.TimelineRepository.Remove(recipients, post.ID); err != nil {
+		log.Printf("Unable to remove post from timelines: %v\n%v", post.ID, err)
+	}
+
+	if post.File == nil {
+		return nil
+	}
+
+	// Files uploaded before media got deduplicated own their objects.
+	// The oldest ones only have their url, which contains the key of their object.
+	if post.File.MediaID == nil {
+		keys := make([]string, 0)
+		for _, v := range post.File.Variants {
+			keys = append(keys, v.Key)
+		}
+
+		if key := objectKey(post.File.Url); len(keys) == 0 && key != "" {
+			keys = append(keys, key)
+		}
+
+		for _, key := range keys {
+			if err := p.FileRepository.DeleteImage(key); err != nil {
+				log.Printf("Unable to delete file: %v\n%v", key, err)
+			}
+		}
+
+		return nil
+	}
+
+	if err := releaseMedia(p.MediaRepository, p.FileRepository, *post.File.MediaID); err != nil {
+		log.Printf("Unable to release media: %v\n%v", *post.File.MediaID, err)
+	}
+
+	return nil
+}
+
+func (p *postService) UploadFile(header *multipart.FileHeader) (*model.File, error) {
+	hash, err := hashFile(header)
 	if err != nil {
 		return nil, err
 	}
 
-	file.ID = id
+	id, err := GenerateId()
+	if err != nil {
+		return nil, err
+	}
 
-	directory := "media/"
-	url, err := p.FileRepository.UploadFile(header, directory, filename, mimetype)
+	directory := "media"
+	mediaId := path.Join(directory, hash)
 
+	media, err := p.MediaRepository.Acquire(mediaId)
 	if err != nil {
 		return nil, err
 	}
 
-	file.Url = url
+	var variants []model.FileVariant
+	if media != nil {
+		variants, err = p.MediaRepository.FindVariants(mediaId)
+		if err != nil {
+			_ = releaseMedia(p.MediaRepository, p.FileRepository, mediaId)
+			return nil, err
+		}
+	}
+
+	// The image only has to be processed if it is new
+	// or if no file holds on to its variants anymore
+	if len(variants) == 0 {
+		uploaded, placeholder, err := p.FileRepository.UploadImage(header, directory, hash)
+
+		if err != nil {
+			if media != nil {
+				_ = releaseMedia(p.MediaRepository, p.FileRepository, mediaId)
+			}
+			return nil, err
+		}
+		variants = uploaded
+
+		keys := make([]string, 0, len(variants))
+		for _, v := range variants {
+			keys = append(keys, v.Key)
+		}
+
+		if media != nil {
+			// Releasing the media has to delete the objects that got uploaded again as well
+			if err := p.MediaRepository.AddKeys(mediaId, keys); err != nil {
+				_ = releaseMedia(p.MediaRepository, p.FileRepository, mediaId)
+				return nil, err
+			}
+		} else {
+			media = &model.Media{
+				ID:          mediaId,
+				Hash:        hash,
+				Keys:        keys,
+				Placeholder: placeholder,
+			}
+
+			if original := defaultVariant(variants); original != nil {
+				media.Url = original.Url
+			}
+
+			if err := p.MediaRepository.Create(media); err != nil {
+				if err := deleteObjects(p.FileRepository, keys); err != nil {
+					log.Printf("Unable to delete uploaded objects of media: %v\n%v", mediaId, err)
+				}
+				return nil, err
+			}
+		}
+	}
+
+	file := model.File{
+		ID:          id,
+		MediaID:     &mediaId,
+		Variants:    make([]model.FileVariant, 0),
+		Placeholder: media.Placeholder,
+	}
+
+	for _, v := range variants {
+		v.FileID = id
+		file.Variants = append(file.Variants, v)
+	}
+
+	original := defaultVariant(file.Variants)
+	if original == nil {
+		log.Printf("Missing original variant for media: %v\n", mediaId)
+		_ = releaseMedia(p.MediaRepository, p.FileRepository, mediaId)
+		return nil, apperrors.NewInternal()
+	}
+
+	file.Url = original.Url
+	file.FileType = "image/" + original.Format
+	file.Filename = path.Base(original.Key)
 
 	return &file, nil
 }
 
 func (p *postService) ToggleLike(post *model.Post, uid string) error {
-	if post.IsLiked(uid) {
+	if err := p.PostRepository.LoadViewerState(uid, []*model.Post{post}); err != nil {
+		return err
+	}
+
+	if post.Liked {
 		return p.PostRepository.RemoveLike(post, uid)
 	} else {
 		return p.PostRepository.AddLike(post, uid)
@@ -100,29 +473,511 @@ func (p *postService) ToggleLike(post *model.Post, uid string) error {
 }
 
 func (p *postService) ToggleRetweet(post *model.Post, uid string) error {
-	if post.IsRetweeted(uid) {
-		return p.PostRepository.RemoveRetweet(post, uid)
-	} else {
-		return p.PostRepository.AddRetweet(post, uid)
+	if err := p.PostRepository.LoadViewerState(uid, []*model.Post{post}); err != nil {
+		return err
+	}
+
+	if post.Retweeted {
+		if err := p.PostRepository.RemoveRetweet(post, uid); err != nil {
+			return err
+		}
+
+		if err := p.removeRetweetFromTimelines(post, uid); err != nil {
+			log.Printf("Unable to remove retweet from timelines: %v\n%v", post.ID, err)
+		}
+
+		return nil
+	}
+
+	if err := p.PostRepository.AddRetweet(post, uid); err != nil {
+		return err
 	}
+
+	entry := model.TimelineEntry{PostID: post.ID, RetweetedBy: uid, CreatedAt: time.Now()}
+	if err := fanOut(p.TimelineRepository, uid, entry); err != nil {
+		log.Printf("Unable to add retweet to timelines: %v\n%v", post.ID, err)
+	}
+
+	return nil
 }
 
-func (p *postService) GetUserFeed(userId, cursor string) (*[]model.Post, error) {
-	return p.PostRepository.Feed(userId, cursor)
+// removeRetweetFromTimelines removes the retweet from the timelines of the retweeter's followers.
+// Followers of the author get the original post back in its place.
+func (p *postService) removeRetweetFromTimelines(post *model.Post, uid string) error {
+	recipients, err := timelineRecipients(p.TimelineRepository, uid)
+
+	if err != nil {
+		return err
+	}
+
+	if err := p.TimelineRepository.RemoveRetweet(recipients, post.ID, uid); err != nil {
+		return err
+	}
+
+	authorRecipients, err := timelineRecipients(p.TimelineRepository, post.UserID)
+
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool)
+	for _, id := range authorRecipients {
+		keep[id] = true
+	}
+
+	restore := make([]string, 0)
+	for _, id := range recipients {
+		if keep[id] {
+			restore = append(restore, id)
+		}
+	}
+
+	return p.TimelineRepository.Add(restore, model.TimelineEntry{PostID: post.ID, CreatedAt: post.CreatedAt})
 }
 
-func (p *postService) ProfilePosts(id, cursor string) (*[]model.Post, error) {
-	return p.PostRepository.List(id, cursor)
+// TogglePin pins the post to the profile of its author or unpins it if it is pinned already.
+// Pinning a post replaces the previously pinned one.
+func (p *postService) TogglePin(post *model.Post, uid string) error {
+	if post.UserID != uid {
+		return apperrors.NewForbidden("you can only pin your own posts")
+	}
+
+	user, err := p.UserRepository.FindByID(uid)
+
+	if err != nil {
+		return err
+	}
+
+	if user.PinnedPostID != nil && *user.PinnedPostID == post.ID {
+		if err := p.UserRepository.SetPinnedPost(uid, nil); err != nil {
+			return err
+		}
+		post.Pinned = false
+		return nil
+	}
+
+	if err := p.UserRepository.SetPinnedPost(uid, &post.ID); err != nil {
+		return err
+	}
+	post.Pinned = true
+
+	return nil
+}
+
+// ToggleHidden folds the reply away in the thread of the post it replies to or shows it again.
+// Only the author of that post can hide its replies.
+func (p *postService) ToggleHidden(reply *model.Post, uid string) error {
+	if reply.ReplyToID == nil {
+		return apperrors.NewBadRequest("the post is not a reply")
+	}
+
+	post, err := p.PostRepository.FindByID(*reply.ReplyToID)
+
+	if err != nil {
+		return err
+	}
+
+	if post.UserID != uid {
+		return apperrors.NewForbidden("only the author of the post can hide its replies")
+	}
+
+	if err := p.PostRepository.SetHidden(reply, !reply.Hidden); err != nil {
+		return err
+	}
+	reply.Hidden = !reply.Hidden
+
+	return nil
+}
+
+// GetReplies returns the replies to the post. Hidden replies are only returned on their own.
+func (p *postService) GetReplies(post *model.Post, hidden bool, page model.Page) (*[]model.Post, error) {
+	return p.PostRepository.Replies(post.ID, hidden, page)
+}
+
+// Vote adds the user's vote to the poll of the post.
+// Single choice polls take exactly one option, users can't vote in their own polls
+// and a vote can't be changed once it got cast.
+func (p *postService) Vote(post *model.Post, uid string, choices []int) error {
+	if post.Poll == nil {
+		return apperrors.NewNotFound("poll", post.ID)
+	}
+
+	if post.UserID == uid {
+		return apperrors.NewForbidden("authors can't vote in their own polls")
+	}
+
+	if post.Poll.IsClosed() {
+		return apperrors.NewForbidden("the poll is closed")
+	}
+
+	if len(choices) == 0 {
+		return apperrors.NewBadRequest("choose at least one option")
+	}
+
+	if !post.Poll.MultipleChoice && len(choices) > 1 {
+		return apperrors.NewBadRequest("the poll only allows one option")
+	}
+
+	chosen := make(map[int]bool)
+	for _, choice := range choices {
+		if choice < 0 || choice >= len(post.Poll.Options) {
+			return apperrors.NewBadRequest("the option does not exist")
+		}
+		if chosen[choice] {
+			return apperrors.NewBadRequest("each option can only be chosen once")
+		}
+		chosen[choice] = true
+	}
+
+	return p.PostRepository.AddVote(post.ID, uid, choices)
+}
+
+// LoadViewerState sets if the viewer liked or retweeted the posts and if they follow their authors.
+// Nothing gets loaded for anonymous viewers.
+func (p *postService) LoadViewerState(viewerId string, posts []*model.Post) error {
+	return p.PostRepository.LoadViewerState(viewerId, posts)
+}
+
+// GetUserFeed returns the home timeline of the user.
+// It gets read from the precomputed timeline, merged with the posts of followed celebrities.
+// The database gets queried directly if the timeline can't be read.
+// Retweets carry the retweeting user and are sorted by the time they got retweeted.
+// Posts deleted since they got written to the timeline get removed from it, and reading continues
+// past them until the page is full or the timeline ends, so deleted posts don't end the feed early.
+func (p *postService) GetUserFeed(userId string, page model.Page) (*[]model.Post, error) {
+	page = timelinePage(page)
+	posts := make([]model.Post, 0)
+
+	for {
+		cached := true
+		entries, err := p.timelineEntries(userId, page, model.LIMIT+1)
+
+		if err != nil {
+			log.Printf("Unable to read timeline for user: %v\n%v", userId, err)
+			cached = false
+			entries, err = p.databaseEntries(userId, page, model.LIMIT+1)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		found, deleted, err := p.hydrateEntries(entries)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if cached {
+			if err := p.TimelineRepository.Remove([]string{userId}, deleted...); err != nil {
+				log.Printf("Unable to remove deleted posts from timeline: %v\n%v", userId, err)
+			}
+		}
+
+		// Pages after a cursor end with the posts closest to it
+		if page.After != nil {
+			posts = append(found, posts...)
+		} else {
+			posts = append(posts, found...)
+		}
+
+		if len(deleted) == 0 || len(entries) <= model.LIMIT || len(posts) > model.LIMIT {
+			return &posts, nil
+		}
+
+		if page.After != nil {
+			cursor := entries[0].Cursor()
+			page = model.Page{After: &cursor}
+		} else {
+			cursor := entries[len(entries)-1].Cursor()
+			page = model.Page{Before: &cursor}
+		}
+	}
+}
+
+// NewFeedPostIDs returns the IDs of up to NewPostsLimit timeline items that are newer than since, newest first.
+// The second return value reports if there are even more. The posts don't get loaded.
+func (p *postService) NewFeedPostIDs(userId string, since model.Cursor) ([]string, bool, error) {
+	page := timelinePage(model.Page{After: &since})
+
+	entries, err := p.timelineEntries(userId, page, model.NewPostsLimit+1)
+
+	if err != nil {
+		log.Printf("Unable to read timeline for user: %v\n%v", userId, err)
+		entries, err = p.databaseEntries(userId, page, model.NewPostsLimit+1)
+
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	hasMore := len(entries) > model.NewPostsLimit
+	if hasMore {
+		entries = entries[len(entries)-model.NewPostsLimit:]
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.PostID)
+	}
+
+	return ids, hasMore, nil
+}
+
+// timelineEntries returns the entries of the user's timeline next to the cursor of the page.
+// The timeline gets rebuilt if it doesn't exist. Entries older than the ones the timeline
+// kept after reaching its size are read from the database.
+func (p *postService) timelineEntries(userId string, page model.Page, limit int) ([]model.TimelineEntry, error) {
+	regular, celebrities, err := p.TimelineRepository.FindFolloweeIDs(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries, exists, err := p.TimelineRepository.Range(userId, page, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	complete := true
+	if !exists {
+		rebuilt, err := rebuildTimeline(p.TimelineRepository, userId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		complete = len(rebuilt) < model.TimelineSize
+		entries = make([]model.TimelineEntry, 0)
+		for _, entry := range rebuilt {
+			if page.Contains(entry.Cursor()) {
+				entries = append(entries, entry)
+			}
+		}
+	} else if page.After == nil && len(entries) < limit {
+		complete, err = p.TimelineRepository.IsComplete(userId)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if page.After == nil && len(entries) < limit && !complete {
+		older := page
+		if len(entries) > 0 {
+			cursor := entries[len(entries)-1].Cursor()
+			older = model.Page{Before: &cursor}
+		}
+
+		fromDatabase, err := p.TimelineRepository.FindEntries(append(regular, userId), older, limit-len(entries), nil)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, fromDatabase...)
+	}
+
+	if len(celebrities) == 0 {
+		return mergeEntries(page, limit, entries), nil
+	}
+
+	// Posts that are already in the timeline, e.g. retweeted by a regular followee,
+	// only show up at their position in the timeline
+	members, err := p.TimelineRepository.Members(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fromCelebrities, err := p.TimelineRepository.FindEntries(celebrities, page, limit, members)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeEntries(page, limit, entries, fromCelebrities), nil
 }
 
-func (p *postService) ProfileLikes(id, cursor string) (*[]model.Post, error) {
-	return p.PostRepository.Likes(id, cursor)
+// databaseEntries reads the entries of the user's timeline straight from the database
+func (p *postService) databaseEntries(userId string, page model.Page, limit int) ([]model.TimelineEntry, error) {
+	regular, celebrities, err := p.TimelineRepository.FindFolloweeIDs(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	authors := append(append(regular, celebrities...), userId)
+	return p.TimelineRepository.FindEntries(authors, page, limit, nil)
 }
 
-func (p *postService) SearchPosts(tag, cursor string) (*[]model.Post, error) {
-	return p.PostRepository.GetPostsForHashtag(tag, cursor)
+// hydrateEntries loads the posts of the entries in timeline order and attributes retweets.
+// It also returns the IDs of the posts that don't exist anymore.
+func (p *postService) hydrateEntries(entries []model.TimelineEntry) ([]model.Post, []string, error) {
+	ids := make([]string, 0, len(entries))
+	retweeterIds := make([]string, 0)
+	for _, entry := range entries {
+		ids = append(ids, entry.PostID)
+		if entry.RetweetedBy != "" {
+			retweeterIds = append(retweeterIds, entry.RetweetedBy)
+		}
+	}
+
+	found, err := p.PostRepository.FindByIDs(ids)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byId := make(map[string]model.Post)
+	for _, post := range *found {
+		byId[post.ID] = post
+	}
+
+	retweeters := make(map[string]model.User)
+	if len(retweeterIds) > 0 {
+		users, err := p.UserRepository.FindByIDs(retweeterIds)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, user := range *users {
+			retweeters[user.ID] = user
+		}
+	}
+
+	posts := make([]model.Post, 0, len(entries))
+	deleted := make([]string, 0)
+	for _, entry := range entries {
+		post, ok := byId[entry.PostID]
+
+		if !ok {
+			deleted = append(deleted, entry.PostID)
+			continue
+		}
+
+		if retweeter, ok := retweeters[entry.RetweetedBy]; ok {
+			retweetedAt := entry.CreatedAt
+			post.RetweetedBy = &retweeter
+			post.RetweetedAt = &retweetedAt
+		}
+
+		posts = append(posts, post)
+	}
+
+	return posts, deleted, nil
 }
 
-func (p *postService) ProfileMedia(id, cursor string) (*[]model.Post, error) {
-	return p.PostRepository.Media(id, cursor)
+// ProfilePosts returns the posts and retweets of the user.
+// The pinned post gets listed on its own, so it is left out here.
+func (p *postService) ProfilePosts(id string, page model.Page) (*[]model.Post, error) {
+	user, err := p.UserRepository.FindByID(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	limit := model.LIMIT + 1
+	if user.PinnedPostID != nil {
+		limit++
+	}
+
+	entries, err := p.TimelineRepository.FindEntries([]string{id}, page, limit, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.PinnedPostID != nil {
+		entries = withoutPinned(entries, *user.PinnedPostID, page)
+	}
+
+	posts, _, err := p.hydrateEntries(entries)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &posts, nil
+}
+
+// withoutPinned removes the pinned post from the entries, but not its retweets,
+// and drops the extra entry fetched in its place if it wasn't among them
+func withoutPinned(entries []model.TimelineEntry, pinned string, page model.Page) []model.TimelineEntry {
+	filtered := make([]model.TimelineEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.PostID == pinned && entry.RetweetedBy == "" {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+
+	if len(filtered) <= model.LIMIT+1 {
+		return filtered
+	}
+
+	// Pages after a cursor end with the entries closest to it
+	if page.After != nil {
+		return filtered[len(filtered)-model.LIMIT-1:]
+	}
+
+	return filtered[:model.LIMIT+1]
+}
+
+func (p *postService) ProfileLikes(id string, page model.Page) (*[]model.Post, error) {
+	return p.PostRepository.Likes(id, page)
+}
+
+// SearchPosts finds the posts matching the query in the given order.
+// The query may contain operators, see ParsePostQuery.
+// Pages of a search sorted by relevance need cursors from such a search.
+func (p *postService) SearchPosts(search string, sort string, page model.Page) (*[]model.Post, error) {
+	query, err := ParsePostQuery(search, sort)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if query.IsEmpty() {
+		posts := make([]model.Post, 0)
+		return &posts, nil
+	}
+
+	// Without text there's nothing to rank by
+	if query.Text == "" {
+		query.Sort = model.SortRecent
+	}
+
+	if query.Sort == model.SortRelevance {
+		for _, cursor := range []*model.Cursor{page.Before, page.After} {
+			if cursor != nil && cursor.Rank == nil {
+				return nil, apperrors.NewBadRequest("invalid cursor")
+			}
+		}
+	}
+
+	return p.PostRepository.Search(query, page)
+}
+
+// GetMentions returns the posts mentioning the user
+func (p *postService) GetMentions(userId string, page model.Page) (*[]model.Post, error) {
+	return p.PostRepository.Mentions(userId, page)
+}
+
+func (p *postService) ProfileMedia(id string, page model.Page) (*[]model.Post, error) {
+	return p.PostRepository.Media(id, page)
+}
+
+// defaultVariant returns the variant for clients that don't pick one.
+// Animated gifs default to the gif, all other images to the original jpeg.
+func defaultVariant(variants []model.FileVariant) *model.FileVariant {
+	file := model.File{Variants: variants}
+
+	if file.Variant(model.PosterSize, model.JPEG) != nil {
+		return file.Variant(model.OriginalSize, model.GIF)
+	}
+
+	return file.Variant(model.OriginalSize, model.JPEG)
 }
@@ -8,7 +8,9 @@ import (
 	"github.com/sentrionic/mirage/model/fixture"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func TestPostService_FindPostByID(t *testing.T) {
@@ -59,8 +61,10 @@ func TestPostService_CreatePost(t *testing.T) {
 		}
 
 		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
 		ps := NewPostService(&PSConfig{
-			PostRepository: mockPostRepository,
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
 		})
 
 		mockPostRepository.
@@ -69,6 +73,15 @@ func TestPostService_CreatePost(t *testing.T) {
 				mockPost.ID = uid
 			}).Return(mockPost, nil)
 
+		follower, _ := GenerateId()
+		mockTimelineRepository.
+			On("FindFollowerIDs", mockPost.UserID).
+			Return([]string{follower}, false, nil)
+
+		mockTimelineRepository.
+			On("Add", []string{follower, mockPost.UserID}, model.TimelineEntry{PostID: uid, CreatedAt: mockPost.CreatedAt}).
+			Return(nil)
+
 		post, err := ps.CreatePost(initial)
 
 		assert.NoError(t, err)
@@ -77,6 +90,182 @@ func TestPostService_CreatePost(t *testing.T) {
 		assert.Equal(t, post, mockPost)
 
 		mockPostRepository.AssertExpectations(t)
+		mockTimelineRepository.AssertExpectations(t)
+	})
+
+	t.Run("Indexes and counts hashtags", func(t *testing.T) {
+		text := "Hello #Go and #gophers"
+		mockPost := fixture.GetMockPost()
+		mockPost.Text = &text
+		mockPost.HashTags = []string{"go", "gophers"}
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
+		mockTrendRepository := new(mocks.TrendRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:      mockPostRepository,
+			TimelineRepository:  mockTimelineRepository,
+			TypeaheadRepository: mockTypeaheadRepository,
+			TrendRepository:     mockTrendRepository,
+		})
+
+		mockPostRepository.
+			On("Create", mock.MatchedBy(func(p *model.Post) bool {
+				return assert.ObjectsAreEqual([]string{"go", "gophers"}, []string(p.HashTags)) && len(p.Entities) == 2
+			})).
+			Return(mockPost, nil)
+		mockTimelineRepository.On("FindFollowerIDs", mockPost.UserID).Return([]string{}, false, nil)
+		mockTimelineRepository.On("Add", mock.Anything, mock.Anything).Return(nil)
+		mockTypeaheadRepository.On("AddHashtags", []string(mockPost.HashTags)).Return(apperrors.NewInternal())
+		mockTrendRepository.On("AddHashtags", mockPost.UserID, []string(mockPost.HashTags), mockPost.CreatedAt).Return(nil)
+
+		post, err := ps.CreatePost(&model.Post{UserID: mockPost.UserID, Text: &text})
+
+		assert.NoError(t, err)
+		assert.Equal(t, mockPost, post)
+		mockTypeaheadRepository.AssertExpectations(t)
+		mockTrendRepository.AssertExpectations(t)
+	})
+
+	t.Run("Resolves mentions of existing users", func(t *testing.T) {
+		text := "Hello @Alice, @alice and @nobody"
+		alice := fixture.GetMockUser()
+		mockPost := fixture.GetMockPost()
+		mockPost.Text = &text
+		mockPost.Mentions = []model.User{*alice}
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockUserRepository := new(mocks.UserRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			UserRepository:     mockUserRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		mockUserRepository.On("FindByUsernames", []string{"alice", "nobody"}).Return(&[]model.User{*alice}, nil)
+		mockPostRepository.
+			On("Create", mock.MatchedBy(func(p *model.Post) bool {
+				return len(p.Mentions) == 1 && p.Mentions[0].ID == alice.ID
+			})).
+			Return(mockPost, nil)
+		mockTimelineRepository.On("FindFollowerIDs", mockPost.UserID).Return([]string{}, false, nil)
+		mockTimelineRepository.On("Add", mock.Anything, mock.Anything).Return(nil)
+
+		post, err := ps.CreatePost(&model.Post{UserID: mockPost.UserID, Text: &text})
+
+		assert.NoError(t, err)
+		assert.Equal(t, mockPost, post)
+		mockUserRepository.AssertExpectations(t)
+		mockPostRepository.AssertExpectations(t)
+	})
+
+	t.Run("Mention lookup errors fail the post", func(t *testing.T) {
+		text := "Hello @alice"
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockUserRepository := new(mocks.UserRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+			UserRepository: mockUserRepository,
+		})
+
+		mockErr := apperrors.NewInternal()
+		mockUserRepository.On("FindByUsernames", []string{"alice"}).Return(nil, mockErr)
+
+		post, err := ps.CreatePost(&model.Post{UserID: fixture.RandID(), Text: &text})
+
+		assert.Nil(t, post)
+		assert.Equal(t, mockErr, err)
+		mockPostRepository.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Links the card of the first URL", func(t *testing.T) {
+		text := "Read https://Example.com/Post and https://example.org"
+		mockPost := fixture.GetMockPost()
+		mockPost.Text = &text
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		mockCardRepository := new(mocks.CardRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
+			CardRepository:     mockCardRepository,
+		})
+
+		// The card got fetched for an earlier post already
+		mockCardRepository.On("Add", "https://example.com/Post").Return(nil)
+		mockCardRepository.On("Claim", "https://example.com/Post").Return(false, nil)
+		mockPostRepository.
+			On("Create", mock.MatchedBy(func(p *model.Post) bool {
+				return p.CardURL != nil && *p.CardURL == "https://example.com/Post"
+			})).
+			Return(mockPost, nil)
+		mockTimelineRepository.On("FindFollowerIDs", mockPost.UserID).Return([]string{}, false, nil)
+		mockTimelineRepository.On("Add", mock.Anything, mock.Anything).Return(nil)
+
+		post, err := ps.CreatePost(&model.Post{UserID: mockPost.UserID, Text: &text})
+
+		assert.NoError(t, err)
+		assert.Equal(t, mockPost, post)
+		mockCardRepository.AssertExpectations(t)
+		mockPostRepository.AssertExpectations(t)
+	})
+
+	t.Run("Timeline errors don't fail the post", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		initial := &model.Post{
+			UserID: mockPost.UserID,
+			Text:   mockPost.Text,
+		}
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		mockPostRepository.On("Create", initial).Return(mockPost, nil)
+		mockTimelineRepository.
+			On("FindFollowerIDs", mockPost.UserID).
+			Return(nil, false, apperrors.NewInternal())
+
+		post, err := ps.CreatePost(initial)
+
+		assert.NoError(t, err)
+		assert.Equal(t, mockPost, post)
+		mockTimelineRepository.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Celebrities only write to their own timeline", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		initial := &model.Post{
+			UserID: mockPost.UserID,
+			Text:   mockPost.Text,
+		}
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		mockPostRepository.On("Create", initial).Return(mockPost, nil)
+		mockTimelineRepository.
+			On("FindFollowerIDs", mockPost.UserID).
+			Return(nil, true, nil)
+		mockTimelineRepository.
+			On("Add", []string{mockPost.UserID}, mock.AnythingOfType("model.TimelineEntry")).
+			Return(nil)
+
+		_, err := ps.CreatePost(initial)
+
+		assert.NoError(t, err)
+		mockTimelineRepository.AssertExpectations(t)
 	})
 
 	t.Run("Error", func(t *testing.T) {
@@ -105,49 +294,214 @@ func TestPostService_CreatePost(t *testing.T) {
 
 		mockPostRepository.AssertExpectations(t)
 	})
+
+	t.Run("Error releases the uploaded media", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mediaId := "media/hash"
+		initial := &model.Post{
+			UserID: mockPost.UserID,
+			File:   &model.File{MediaID: &mediaId},
+		}
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+		us := NewPostService(&PSConfig{
+			PostRepository:  mockPostRepository,
+			MediaRepository: mockMediaRepository,
+		})
+
+		mockErr := apperrors.NewInternal()
+
+		mockPostRepository.
+			On("Create", initial).
+			Return(nil, mockErr)
+
+		mockMediaRepository.
+			On("Release", mediaId, mock.Anything).
+			Return(nil)
+
+		post, err := us.CreatePost(initial)
+
+		assert.EqualError(t, err, mockErr.Error())
+		assert.Nil(t, post)
+
+		mockPostRepository.AssertExpectations(t)
+		mockMediaRepository.AssertExpectations(t)
+	})
+
+	t.Run("Reply", func(t *testing.T) {
+		parent := fixture.GetMockPost()
+		mockPost := fixture.GetMockPost()
+		mockPost.ReplyToID = &parent.ID
+
+		initial := &model.Post{
+			UserID:    mockPost.UserID,
+			Text:      mockPost.Text,
+			ReplyToID: &parent.ID,
+		}
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		mockPostRepository.On("FindByID", parent.ID).Return(parent, nil)
+		mockPostRepository.
+			On("LoadViewerState", mockPost.UserID, []*model.Post{parent}).
+			Run(func(args mock.Arguments) {
+				parent.CanReply = true
+			}).
+			Return(nil)
+		mockPostRepository.On("Create", initial).Return(mockPost, nil)
+		mockTimelineRepository.On("FindFollowerIDs", mockPost.UserID).Return([]string{}, false, nil)
+		mockTimelineRepository.On("Add", mock.Anything, mock.Anything).Return(nil)
+
+		post, err := ps.CreatePost(initial)
+
+		assert.NoError(t, err)
+		assert.Equal(t, mockPost, post)
+		assert.Equal(t, model.ReplyEveryone, initial.ReplyPolicy)
+		assert.True(t, post.CanReply)
+		mockPostRepository.AssertExpectations(t)
+	})
+
+	t.Run("Reply not allowed", func(t *testing.T) {
+		for policy, reason := range map[string]string{
+			model.ReplyFollowing: "only people the author follows can reply",
+			model.ReplyMentioned: "only people mentioned in the post can reply",
+		} {
+			parent := fixture.GetMockPost()
+			parent.ReplyPolicy = policy
+			mediaId := "media/hash"
+
+			initial := &model.Post{
+				UserID:    fixture.RandID(),
+				ReplyToID: &parent.ID,
+				File:      &model.File{MediaID: &mediaId},
+			}
+
+			mockPostRepository := new(mocks.PostRepository)
+			mockMediaRepository := new(mocks.MediaRepository)
+			ps := NewPostService(&PSConfig{
+				PostRepository:  mockPostRepository,
+				MediaRepository: mockMediaRepository,
+			})
+
+			mockPostRepository.On("FindByID", parent.ID).Return(parent, nil)
+			mockPostRepository.On("LoadViewerState", initial.UserID, []*model.Post{parent}).Return(nil)
+			mockMediaRepository.On("Release", mediaId, mock.Anything).Return(nil)
+
+			post, err := ps.CreatePost(initial)
+
+			assert.Nil(t, post)
+			assert.Error(t, err)
+			assert.Equal(t, apperrors.NewForbidden(reason), err)
+			mockPostRepository.AssertNotCalled(t, "Create", mock.Anything)
+			mockMediaRepository.AssertExpectations(t)
+		}
+	})
+
+	t.Run("Reply to missing post", func(t *testing.T) {
+		id := fixture.RandID()
+		initial := &model.Post{
+			UserID:    fixture.RandID(),
+			ReplyToID: &id,
+		}
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+
+		mockErr := apperrors.NewNotFound("id", id)
+		mockPostRepository.On("FindByID", id).Return(nil, mockErr)
+
+		post, err := ps.CreatePost(initial)
+
+		assert.Nil(t, post)
+		assert.Equal(t, mockErr, err)
+		mockPostRepository.AssertNotCalled(t, "Create", mock.Anything)
+	})
 }
 
 func TestPostService_UploadFile(t *testing.T) {
 	mockPostRepository := new(mocks.PostRepository)
 	mockFileRepository := new(mocks.FileRepository)
+	mockMediaRepository := new(mocks.MediaRepository)
+	mockTimelineRepository := new(mocks.TimelineRepository)
 
 	ps := NewPostService(&PSConfig{
-		PostRepository: mockPostRepository,
-		FileRepository: mockFileRepository,
+		PostRepository:     mockPostRepository,
+		FileRepository:     mockFileRepository,
+		MediaRepository:    mockMediaRepository,
+		TimelineRepository: mockTimelineRepository,
 	})
 
+	mockTimelineRepository.On("FindFollowerIDs", mock.AnythingOfType("string")).Return([]string{}, false, nil)
+	mockTimelineRepository.On("Add", mock.Anything, mock.Anything).Return(nil)
+
 	mockUser := fixture.GetMockUser()
 
+	placeholder := model.Placeholder{
+		BlurHash:      "LEHV6nWB2yk8pyo0adR*.7kCMdnj",
+		DominantColor: "#1da1f2",
+	}
+
 	t.Run("Successful image upload", func(t *testing.T) {
 		mockPost := fixture.GetMockPost()
 
-		file := &model.File{
-			PostId:   mockPost.ID,
-			FileType: "image/png",
-		}
-
 		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
 		defer multipartImageFixture.Close()
 		imageFileHeader := multipartImageFixture.GetFormFile()
-		directory := "media/"
+		directory := "media"
 
-		uploadFileArgs := mock.Arguments{
+		uploadImageArgs := mock.Arguments{
 			imageFileHeader,
 			directory,
 			mock.AnythingOfType("string"),
-			file.FileType,
 		}
 
-		imageURL := "https://imageurl.com/jdfkj34kljl"
+		variants := []model.FileVariant{
+			{Size: model.ThumbSize, Format: model.JPEG, Key: "files/media/hash_thumb.jpeg", Url: "https://imageurl.com/thumb.jpeg"},
+			{Size: model.OriginalSize, Format: model.JPEG, Key: "files/media/hash_original.jpeg", Url: "https://imageurl.com/original.jpeg"},
+			{Size: model.OriginalSize, Format: model.WEBP, Key: "files/media/hash_original.webp", Url: "https://imageurl.com/original.webp"},
+		}
+
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(nil, nil)
 
 		mockFileRepository.
-			On("UploadFile", uploadFileArgs...).
-			Return(imageURL, nil)
+			On("UploadImage", uploadImageArgs...).
+			Return(variants, placeholder, nil)
+
+		mockMediaRepository.
+			On("Create", mock.AnythingOfType("*model.Media")).
+			Return(nil)
+
+		uploadedFile, err := ps.UploadFile(imageFileHeader)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://imageurl.com/original.jpeg", uploadedFile.Url)
+		assert.Equal(t, "image/jpeg", uploadedFile.FileType)
+		assert.Equal(t, "hash_original.jpeg", uploadedFile.Filename)
+		assert.Equal(t, placeholder, uploadedFile.Placeholder)
+		assert.Len(t, uploadedFile.Variants, 3)
+		for _, v := range uploadedFile.Variants {
+			assert.Equal(t, uploadedFile.ID, v.FileID)
+		}
 
-		file.Url = imageURL
+		media := mockMediaRepository.Calls[1].Arguments.Get(0).(*model.Media)
+		assert.Equal(t, "media/"+media.Hash, media.ID)
+		assert.Equal(t, media.ID, *uploadedFile.MediaID)
+		assert.Equal(t, "https://imageurl.com/original.jpeg", media.Url)
+		assert.Len(t, media.Keys, 3)
+		mockFileRepository.AssertCalled(t, "UploadImage", imageFileHeader, directory, media.Hash)
 
 		initial := &model.Post{
-			File: file,
+			File: uploadedFile,
 			User: *mockUser,
 		}
 
@@ -155,322 +509,1676 @@ func TestPostService_UploadFile(t *testing.T) {
 			On("Create", initial).
 			Return(mockPost, nil)
 
-		uploadedFile, err := ps.UploadFile(imageFileHeader)
-		assert.NoError(t, err)
-		assert.NotNil(t, uploadedFile)
-
 		newPost, err := ps.CreatePost(initial)
 
 		assert.NoError(t, err)
 		assert.Equal(t, newPost, mockPost)
-		mockFileRepository.AssertCalled(t, "UploadFile", uploadFileArgs...)
 		mockPostRepository.AssertCalled(t, "Create", initial)
 	})
 
-	t.Run("FileRepository Error", func(t *testing.T) {
-		mockPostRepository := new(mocks.PostRepository)
+	t.Run("Successful animated gif upload", func(t *testing.T) {
 		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
 
 		ps := NewPostService(&PSConfig{
-			PostRepository: mockPostRepository,
-			FileRepository: mockFileRepository,
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
 		})
 
-		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
+		multipartImageFixture := fixture.NewMultipartImage("image.gif", "image/gif")
 		defer multipartImageFixture.Close()
 		imageFileHeader := multipartImageFixture.GetFormFile()
-		directory := "media/"
 
-		uploadFileArgs := mock.Arguments{
-			imageFileHeader,
-			directory,
-			mock.AnythingOfType("string"),
-			mock.AnythingOfType("string"),
+		variants := []model.FileVariant{
+			{Size: model.OriginalSize, Format: model.GIF, Key: "files/media/hash_original.gif", Url: "https://imageurl.com/original.gif"},
+			{Size: model.PosterSize, Format: model.JPEG, Key: "files/media/hash_poster.jpeg", Url: "https://imageurl.com/poster.jpeg"},
+			{Size: model.PosterSize, Format: model.WEBP, Key: "files/media/hash_poster.webp", Url: "https://imageurl.com/poster.webp"},
 		}
 
-		mockError := apperrors.NewInternal()
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(nil, nil)
+
 		mockFileRepository.
-			On("UploadFile", uploadFileArgs...).
-			Return("", mockError)
+			On("UploadImage", imageFileHeader, "media", mock.AnythingOfType("string")).
+			Return(variants, placeholder, nil)
+
+		mockMediaRepository.
+			On("Create", mock.AnythingOfType("*model.Media")).
+			Return(nil)
 
 		uploadedFile, err := ps.UploadFile(imageFileHeader)
-		assert.Nil(t, uploadedFile)
-		assert.Error(t, err)
 
-		mockFileRepository.AssertCalled(t, "UploadFile", uploadFileArgs...)
-		mockPostRepository.AssertNotCalled(t, "Create")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://imageurl.com/original.gif", uploadedFile.Url)
+		assert.Equal(t, "image/gif", uploadedFile.FileType)
+		assert.Equal(t, "hash_original.gif", uploadedFile.Filename)
+		assert.NotNil(t, uploadedFile.Variant(model.PosterSize, model.WEBP))
 	})
 
-	t.Run("PostRepository Create Error", func(t *testing.T) {
-		uid, _ := GenerateId()
-		imageURL := "https://imageurl.com/jdfkj34kljl"
+	t.Run("Reuses stored media", func(t *testing.T) {
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+
+		ps := NewPostService(&PSConfig{
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
 
 		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
 		defer multipartImageFixture.Close()
 		imageFileHeader := multipartImageFixture.GetFormFile()
-		directory := "media/"
 
-		uploadFileArgs := mock.Arguments{
-			imageFileHeader,
-			directory,
-			mock.AnythingOfType("string"),
-			mock.AnythingOfType("string"),
+		media := &model.Media{
+			ID:          "media/hash",
+			Hash:        "hash",
+			Refs:        2,
+			Url:         "https://imageurl.com/original.jpeg",
+			Keys:        []string{"files/media/hash_original.jpeg"},
+			Placeholder: placeholder,
 		}
 
-		mockFileRepository.
-			On("UploadFile", uploadFileArgs...).
-			Return(imageURL, nil)
+		variants := []model.FileVariant{
+			{FileID: "other", Size: model.OriginalSize, Format: model.JPEG, Key: "files/media/hash_original.jpeg", Url: "https://imageurl.com/original.jpeg"},
+		}
 
-		file := &model.File{
-			PostId:   uid,
-			FileType: "image/png",
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(media, nil)
+
+		mockMediaRepository.
+			On("FindVariants", mock.AnythingOfType("string")).
+			Return(variants, nil)
+
+		uploadedFile, err := ps.UploadFile(imageFileHeader)
+
+		assert.NoError(t, err)
+		assert.Equal(t, media.Url, uploadedFile.Url)
+		assert.Equal(t, placeholder, uploadedFile.Placeholder)
+		assert.Equal(t, uploadedFile.ID, uploadedFile.Variants[0].FileID)
+		assert.Equal(t, "other", variants[0].FileID)
+		mockFileRepository.AssertNotCalled(t, "UploadImage", mock.Anything, mock.Anything, mock.Anything)
+		mockMediaRepository.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Reprocesses media without variants", func(t *testing.T) {
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+
+		ps := NewPostService(&PSConfig{
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
+
+		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
+		defer multipartImageFixture.Close()
+		imageFileHeader := multipartImageFixture.GetFormFile()
+
+		media := &model.Media{
+			ID:          "media/hash",
+			Hash:        "hash",
+			Refs:        2,
+			Placeholder: placeholder,
 		}
 
-		initial := &model.Post{
-			ID:   uid,
-			User: *mockUser,
-			File: file,
+		variants := []model.FileVariant{
+			{Size: model.OriginalSize, Format: model.JPEG, Key: "files/media/hash_original.jpeg", Url: "https://imageurl.com/original.jpeg"},
 		}
 
-		mockError := apperrors.NewInternal()
-		mockPostRepository.
-			On("Create", initial).
-			Return(nil, mockError)
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(media, nil)
+
+		mockMediaRepository.
+			On("FindVariants", mock.AnythingOfType("string")).
+			Return(nil, nil)
+
+		mockFileRepository.
+			On("UploadImage", imageFileHeader, "media", mock.AnythingOfType("string")).
+			Return(variants, model.Placeholder{}, nil)
+
+		// The objects that got uploaded again belong to the media
+		mockMediaRepository.
+			On("AddKeys", mock.AnythingOfType("string"), []string{"files/media/hash_original.jpeg"}).
+			Return(nil)
 
 		uploadedFile, err := ps.UploadFile(imageFileHeader)
+
 		assert.NoError(t, err)
-		assert.NotNil(t, uploadedFile)
+		assert.Equal(t, "https://imageurl.com/original.jpeg", uploadedFile.Url)
+		assert.Equal(t, placeholder, uploadedFile.Placeholder)
+		mockMediaRepository.AssertExpectations(t)
+		mockMediaRepository.AssertNotCalled(t, "Create", mock.Anything)
+	})
 
-		createdPost, err := ps.CreatePost(initial)
+	t.Run("MediaRepository Create Error", func(t *testing.T) {
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
 
-		assert.Error(t, err)
-		assert.Nil(t, createdPost)
-		mockFileRepository.AssertCalled(t, "UploadFile", uploadFileArgs...)
-		mockPostRepository.AssertCalled(t, "Create", initial)
+		ps := NewPostService(&PSConfig{
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
+
+		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
+		defer multipartImageFixture.Close()
+		imageFileHeader := multipartImageFixture.GetFormFile()
+
+		variants := []model.FileVariant{
+			{Size: model.OriginalSize, Format: model.JPEG, Key: "files/media/hash_original.jpeg", Url: "https://imageurl.com/original.jpeg"},
+			{Size: model.OriginalSize, Format: model.WEBP, Key: "files/media/hash_original.webp", Url: "https://imageurl.com/original.webp"},
+		}
+
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(nil, nil)
+
+		mockFileRepository.
+			On("UploadImage", imageFileHeader, "media", mock.AnythingOfType("string")).
+			Return(variants, placeholder, nil)
+
+		mockMediaRepository.
+			On("Create", mock.AnythingOfType("*model.Media")).
+			Return(apperrors.NewInternal())
+
+		// Nothing references the uploaded objects
+		mockFileRepository.On("DeleteImage", variants[0].Key).Return(nil)
+		mockFileRepository.On("DeleteImage", variants[1].Key).Return(nil)
+
+		uploadedFile, err := ps.UploadFile(imageFileHeader)
+
+		assert.Nil(t, uploadedFile)
+		assert.Equal(t, apperrors.NewInternal(), err)
+		mockFileRepository.AssertExpectations(t)
+	})
+
+	t.Run("FileRepository Error", func(t *testing.T) {
+		mockPostRepository := new(mocks.PostRepository)
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+
+		ps := NewPostService(&PSConfig{
+			PostRepository:  mockPostRepository,
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
+
+		multipartImageFixture := fixture.NewMultipartImage("image.gif", "image/gif")
+		defer multipartImageFixture.Close()
+		imageFileHeader := multipartImageFixture.GetFormFile()
+
+		uploadImageArgs := mock.Arguments{
+			imageFileHeader,
+			"media",
+			mock.AnythingOfType("string"),
+		}
+
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(nil, nil)
+
+		mockError := apperrors.NewBadRequest("gifs can have at most 300 frames")
+		mockFileRepository.
+			On("UploadImage", uploadImageArgs...).
+			Return(nil, model.Placeholder{}, mockError)
+
+		uploadedFile, err := ps.UploadFile(imageFileHeader)
+		assert.Nil(t, uploadedFile)
+		assert.EqualError(t, err, mockError.Error())
+
+		mockFileRepository.AssertCalled(t, "UploadImage", uploadImageArgs...)
+		mockMediaRepository.AssertNotCalled(t, "Create", mock.Anything)
+		mockMediaRepository.AssertNotCalled(t, "Release", mock.Anything, mock.Anything)
+		mockPostRepository.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("Missing original variant", func(t *testing.T) {
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+
+		ps := NewPostService(&PSConfig{
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
+
+		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
+		defer multipartImageFixture.Close()
+		imageFileHeader := multipartImageFixture.GetFormFile()
+
+		variants := []model.FileVariant{
+			{Size: model.ThumbSize, Format: model.JPEG, Key: "files/media/hash_thumb.jpeg", Url: "https://imageurl.com/thumb.jpeg"},
+		}
+
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(nil, nil)
+
+		mockFileRepository.
+			On("UploadImage", imageFileHeader, "media", mock.AnythingOfType("string")).
+			Return(variants, placeholder, nil)
+
+		mockMediaRepository.
+			On("Create", mock.AnythingOfType("*model.Media")).
+			Return(nil)
+
+		mockMediaRepository.
+			On("Release", mock.AnythingOfType("string"), mock.Anything).
+			Return(nil)
+
+		uploadedFile, err := ps.UploadFile(imageFileHeader)
+
+		assert.Nil(t, uploadedFile)
+		assert.EqualError(t, err, apperrors.NewInternal().Error())
+		mockMediaRepository.AssertCalled(t, "Release", mock.AnythingOfType("string"), mock.Anything)
+	})
+
+	t.Run("PostRepository Create Error", func(t *testing.T) {
+		uid, _ := GenerateId()
+
+		file := &model.File{
+			PostId:   uid,
+			FileType: "image/jpeg",
+		}
+
+		initial := &model.Post{
+			ID:   uid,
+			User: *mockUser,
+			File: file,
+		}
+
+		mockError := apperrors.NewInternal()
+		mockPostRepository.
+			On("Create", initial).
+			Return(nil, mockError)
+
+		createdPost, err := ps.CreatePost(initial)
+
+		assert.Error(t, err)
+		assert.Nil(t, createdPost)
+		mockPostRepository.AssertCalled(t, "Create", initial)
+	})
+}
+
+func TestPostService_EditPost(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.HashTags = []string{"go", "rust"}
+		alice := fixture.GetMockUser()
+		edited := fixture.GetMockPost()
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockUserRepository := new(mocks.UserRepository)
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
+		mockTrendRepository := new(mocks.TrendRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:      mockPostRepository,
+			UserRepository:      mockUserRepository,
+			TypeaheadRepository: mockTypeaheadRepository,
+			TrendRepository:     mockTrendRepository,
+			EditWindow:          time.Hour,
+		})
+
+		text := "Still #Go, now with #gophers and @alice"
+		mockUserRepository.On("FindByUsernames", []string{"alice"}).Return(&[]model.User{*alice}, nil)
+		mockPostRepository.
+			On("Update", mock.MatchedBy(func(p *model.Post) bool {
+				return *p.Text == text &&
+					assert.ObjectsAreEqual([]string{"go", "gophers"}, []string(p.HashTags)) &&
+					len(p.Mentions) == 1 &&
+					p.EditedAt != nil
+			})).
+			Return(nil)
+		mockPostRepository.On("FindByID", mockPost.ID).Return(edited, nil)
+		// Only hashtags that weren't in the post before get counted
+		mockTypeaheadRepository.On("AddHashtags", []string{"gophers"}).Return(nil)
+		mockTrendRepository.On("AddHashtags", mockPost.UserID, []string{"gophers"}, mock.AnythingOfType("time.Time")).Return(nil)
+		// Hashtags that got removed aren't suggested as often anymore
+		mockTypeaheadRepository.On("RemoveHashtags", []string{"rust"}).Return(nil)
+
+		post, err := ps.EditPost(mockPost, &text, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, edited, post)
+		mockPostRepository.AssertExpectations(t)
+		mockTypeaheadRepository.AssertExpectations(t)
+		mockTrendRepository.AssertExpectations(t)
+	})
+
+	t.Run("Sets the alt text of the file", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.File = fixture.GetMockFile(mockPost.ID)
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+			EditWindow:     time.Hour,
+		})
+
+		altText := "A gopher"
+		mockPostRepository.
+			On("Update", mock.MatchedBy(func(p *model.Post) bool {
+				return p.Text == nil && *p.File.AltText == altText
+			})).
+			Return(nil)
+		mockPostRepository.On("FindByID", mockPost.ID).Return(mockPost, nil)
+
+		_, err := ps.EditPost(mockPost, nil, &altText)
+
+		assert.NoError(t, err)
+		mockPostRepository.AssertExpectations(t)
+	})
+
+	t.Run("Update error keeps the post and its card", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		original := *mockPost.Text
+		mockPost.File = fixture.GetMockFile(mockPost.ID)
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockCardRepository := new(mocks.CardRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+			CardRepository: mockCardRepository,
+			EditWindow:     time.Hour,
+		})
+
+		text := "Read https://example.com/Post"
+		altText := "A gopher"
+		mockErr := apperrors.NewInternal()
+		mockCardRepository.On("Add", "https://example.com/Post").Return(nil)
+		mockPostRepository.On("Update", mock.AnythingOfType("*model.Post")).Return(mockErr)
+
+		post, err := ps.EditPost(mockPost, &text, &altText)
+
+		assert.Nil(t, post)
+		assert.Equal(t, mockErr, err)
+		assert.Equal(t, original, *mockPost.Text)
+		assert.Nil(t, mockPost.CardURL)
+		assert.Nil(t, mockPost.EditedAt)
+		assert.Nil(t, mockPost.File.AltText)
+		mockCardRepository.AssertNotCalled(t, "Claim", mock.Anything)
+	})
+
+	t.Run("Edit window closed", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.CreatedAt = time.Now().Add(-2 * time.Hour)
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+			EditWindow:     time.Hour,
+		})
+
+		text := "Too late"
+		post, err := ps.EditPost(mockPost, &text, nil)
+
+		assert.Nil(t, post)
+		assert.Equal(t, apperrors.Forbidden, err.(*apperrors.Error).Type)
+		mockPostRepository.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Text required without a file", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+			EditWindow:     time.Hour,
+		})
+
+		altText := "A gopher"
+
+		_, err := ps.EditPost(mockPost, nil, nil)
+		assert.Equal(t, apperrors.BadRequest, err.(*apperrors.Error).Type)
+
+		_, err = ps.EditPost(mockPost, mockPost.Text, &altText)
+		assert.Equal(t, apperrors.BadRequest, err.(*apperrors.Error).Type)
+
+		mockPostRepository.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestPostService_GetPostHistory(t *testing.T) {
+	t.Run("Starts with the current version", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		editedAt := time.Now()
+		mockPost.EditedAt = &editedAt
+
+		original := "First try"
+		versions := []model.PostVersion{{PostID: mockPost.ID, Text: &original, PublishedAt: mockPost.CreatedAt, ReplacedAt: &editedAt}}
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("FindVersions", mockPost.ID).Return(versions, nil)
+
+		history, err := ps.GetPostHistory(mockPost)
+
+		assert.NoError(t, err)
+		assert.Len(t, history, 2)
+		assert.Equal(t, mockPost.Text, history[0].Text)
+		assert.Equal(t, editedAt, history[0].PublishedAt)
+		assert.Nil(t, history[0].ReplacedAt)
+		assert.Equal(t, versions[0], history[1])
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockErr := apperrors.NewInternal()
+		mockPostRepository.On("FindVersions", mockPost.ID).Return(nil, mockErr)
+
+		history, err := ps.GetPostHistory(mockPost)
+
+		assert.Nil(t, history)
+		assert.Equal(t, mockErr, err)
+	})
+}
+
+func TestPostService_DeletePost(t *testing.T) {
+	t.Run("Releases the media", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.File = fixture.GetMockFile(mockPost.ID)
+		mediaId := "media/hash"
+		mockPost.File.MediaID = &mediaId
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			FileRepository:     mockFileRepository,
+			MediaRepository:    mockMediaRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		mockTimelineRepository.On("FindFollowerIDs", mockPost.UserID).Return([]string{}, false, nil)
+		mockTimelineRepository.On("Remove", []string{mockPost.UserID}, mockPost.ID).Return(nil)
+
+		media := &model.Media{
+			ID:   mediaId,
+			Keys: []string{"files/media/hash_thumb.jpeg", "files/media/hash_thumb.webp"},
+		}
+
+		mockPostRepository.On("Delete", mockPost).Return(nil)
+		mockMediaRepository.On("Release", mediaId, mock.Anything).
+			Run(func(args mock.Arguments) {
+				_ = args.Get(1).(func([]string) error)(media.Keys)
+			}).
+			Return(nil)
+		mockFileRepository.On("DeleteImage", "files/media/hash_thumb.jpeg").Return(nil)
+		mockFileRepository.On("DeleteImage", "files/media/hash_thumb.webp").Return(nil)
+
+		err := ps.DeletePost(mockPost)
+
+		assert.NoError(t, err)
+		mockPostRepository.AssertExpectations(t)
+		mockMediaRepository.AssertExpectations(t)
+		mockFileRepository.AssertExpectations(t)
+		mockTimelineRepository.AssertExpectations(t)
+	})
+
+	t.Run("Uncounts the hashtags", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.HashTags = []string{"go"}
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:      mockPostRepository,
+			TimelineRepository:  mockTimelineRepository,
+			TypeaheadRepository: mockTypeaheadRepository,
+		})
+
+		mockTimelineRepository.On("FindFollowerIDs", mockPost.UserID).Return([]string{}, false, nil)
+		mockTimelineRepository.On("Remove", []string{mockPost.UserID}, mockPost.ID).Return(nil)
+
+		mockPostRepository.On("Delete", mockPost).Return(nil)
+		mockTypeaheadRepository.On("RemoveHashtags", []string{"go"}).Return(nil)
+
+		err := ps.DeletePost(mockPost)
+
+		assert.NoError(t, err)
+		mockTypeaheadRepository.AssertExpectations(t)
+	})
+
+	t.Run("Keeps media that is still in use", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.File = fixture.GetMockFile(mockPost.ID)
+		mediaId := "media/hash"
+		mockPost.File.MediaID = &mediaId
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			FileRepository:     mockFileRepository,
+			MediaRepository:    mockMediaRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		mockTimelineRepository.On("FindFollowerIDs", mockPost.UserID).Return([]string{}, false, nil)
+		mockTimelineRepository.On("Remove", []string{mockPost.UserID}, mockPost.ID).Return(nil)
+
+		mockPostRepository.On("Delete", mockPost).Return(nil)
+		mockMediaRepository.On("Release", mediaId, mock.Anything).Return(nil)
+
+		err := ps.DeletePost(mockPost)
+
+		assert.NoError(t, err)
+		mockMediaRepository.AssertExpectations(t)
+		mockFileRepository.AssertNotCalled(t, "DeleteImage", mock.Anything)
+	})
+
+	t.Run("Deletes the object of legacy files", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.File = fixture.GetMockFile(mockPost.ID)
+		mockPost.File.Url = "https://bucket.s3.region.amazonaws.com/files/media/" + mockPost.UserID + "/" + mockPost.File.Filename
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockFileRepository := new(mocks.FileRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			FileRepository:     mockFileRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		mockTimelineRepository.On("FindFollowerIDs", mockPost.UserID).Return([]string{}, false, nil)
+		mockTimelineRepository.On("Remove", []string{mockPost.UserID}, mockPost.ID).Return(nil)
+
+		mockFileRepository.On("DeleteImage", "files/media/"+mockPost.UserID+"/"+mockPost.File.Filename).Return(nil)
+		mockPostRepository.On("Delete", mockPost).Return(nil)
+
+		err := ps.DeletePost(mockPost)
+
+		assert.NoError(t, err)
+		mockFileRepository.AssertExpectations(t)
+	})
+
+	t.Run("Deletes all variants of legacy files", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.File = fixture.GetMockFile(mockPost.ID)
+		mockPost.File.Variants = []model.FileVariant{
+			{Size: model.ThumbSize, Format: model.JPEG, Key: "files/media/thumb.jpeg"},
+			{Size: model.ThumbSize, Format: model.WEBP, Key: "files/media/thumb.webp"},
+		}
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockFileRepository := new(mocks.FileRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			FileRepository:     mockFileRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		mockTimelineRepository.On("FindFollowerIDs", mockPost.UserID).Return([]string{}, false, nil)
+		mockTimelineRepository.On("Remove", []string{mockPost.UserID}, mockPost.ID).Return(nil)
+
+		mockFileRepository.On("DeleteImage", "files/media/thumb.jpeg").Return(nil)
+		mockFileRepository.On("DeleteImage", "files/media/thumb.webp").Return(fmt.Errorf("some error down the call chain"))
+		mockPostRepository.On("Delete", mockPost).Return(nil)
+
+		err := ps.DeletePost(mockPost)
+
+		assert.NoError(t, err)
+		mockFileRepository.AssertExpectations(t)
+		mockFileRepository.AssertNotCalled(t, "DeleteImage", mockPost.File.Filename)
+		mockPostRepository.AssertExpectations(t)
+	})
+
+	t.Run("PostRepository Delete Error", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.File = fixture.GetMockFile(mockPost.ID)
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockFileRepository := new(mocks.FileRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+			FileRepository: mockFileRepository,
+		})
+
+		mockError := apperrors.NewInternal()
+		mockPostRepository.On("Delete", mockPost).Return(mockError)
+
+		err := ps.DeletePost(mockPost)
+
+		assert.Equal(t, mockError, err)
+		mockFileRepository.AssertNotCalled(t, "DeleteImage", mock.Anything)
+	})
+}
+
+func TestPostService_ToggleLike(t *testing.T) {
+	t.Run("Success change to liked", func(t *testing.T) {
+		uid, _ := GenerateId()
+		mockPost := fixture.GetMockPost()
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("LoadViewerState", uid, []*model.Post{mockPost}).Return(nil)
+		mockPostRepository.On("AddLike", mockPost, uid).Return(nil)
+
+		err := ps.ToggleLike(mockPost, uid)
+
+		assert.NoError(t, err)
+		mockPostRepository.AssertExpectations(t)
+		mockPostRepository.AssertNotCalled(t, "RemoveLike", mockPost, uid)
+	})
+
+	t.Run("Success change to unliked", func(t *testing.T) {
+		mockUser := fixture.GetMockUser()
+		mockPost := fixture.GetMockPost()
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("LoadViewerState", mockUser.ID, []*model.Post{mockPost}).
+			Run(func(args mock.Arguments) {
+				mockPost.Liked = true
+			}).
+			Return(nil)
+		mockPostRepository.On("RemoveLike", mockPost, mockUser.ID).Return(nil)
+
+		err := ps.ToggleLike(mockPost, mockUser.ID)
+
+		assert.NoError(t, err)
+		mockPostRepository.AssertExpectations(t)
+		mockPostRepository.AssertNotCalled(t, "AddLike", mockPost, mockUser.ID)
+	})
+
+	t.Run("Error from RemoveLike", func(t *testing.T) {
+		mockUser := fixture.GetMockUser()
+		mockPost := fixture.GetMockPost()
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("LoadViewerState", mockUser.ID, []*model.Post{mockPost}).
+			Run(func(args mock.Arguments) {
+				mockPost.Liked = true
+			}).
+			Return(nil)
+		mockPostRepository.On("RemoveLike", mockPost, mockUser.ID).Return(fmt.Errorf("some error down the call chain"))
+
+		err := ps.ToggleLike(mockPost, mockUser.ID)
+
+		assert.Error(t, err)
+		mockPostRepository.AssertExpectations(t)
+	})
+
+	t.Run("Error from AddLike", func(t *testing.T) {
+		mockUser := fixture.GetMockUser()
+		mockPost := fixture.GetMockPost()
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("LoadViewerState", mockUser.ID, []*model.Post{mockPost}).Return(nil)
+		mockPostRepository.On("AddLike", mockPost, mockUser.ID).Return(fmt.Errorf("some error down the call chain"))
+
+		err := ps.ToggleLike(mockPost, mockUser.ID)
+
+		assert.Error(t, err)
+		mockPostRepository.AssertExpectations(t)
+	})
+}
+
+func TestPostService_ToggleRetweet(t *testing.T) {
+	t.Run("Successful retweet", func(t *testing.T) {
+		uid, _ := GenerateId()
+		mockPost := fixture.GetMockPost()
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
+		mockPostRepository.On("LoadViewerState", uid, []*model.Post{mockPost}).Return(nil)
+		mockPostRepository.On("AddRetweet", mockPost, uid).Return(nil)
+		mockTimelineRepository.On("FindFollowerIDs", uid).Return([]string{}, false, nil)
+		mockTimelineRepository.
+			On("Add", []string{uid}, mock.MatchedBy(func(entry model.TimelineEntry) bool {
+				return entry.PostID == mockPost.ID && entry.RetweetedBy == uid && entry.CreatedAt.After(mockPost.CreatedAt)
+			})).
+			Return(nil)
+
+		err := ps.ToggleRetweet(mockPost, uid)
+
+		assert.NoError(t, err)
+		mockPostRepository.AssertExpectations(t)
+		mockTimelineRepository.AssertExpectations(t)
+		mockPostRepository.AssertNotCalled(t, "RemoveRetweet", mockPost, uid)
+	})
+
+	t.Run("Successfully removed retweet", func(t *testing.T) {
+		mockUser := fixture.GetMockUser()
+		mockPost := fixture.GetMockPost()
+
+		// Followers of the author get the original post back
+		authorFollower := fixture.RandID()
+		follower := fixture.RandID()
+
+		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
+		mockPostRepository.On("LoadViewerState", mockUser.ID, []*model.Post{mockPost}).
+			Run(func(args mock.Arguments) {
+				mockPost.Retweeted = true
+			}).
+			Return(nil)
+		mockPostRepository.On("RemoveRetweet", mockPost, mockUser.ID).Return(nil)
+		mockTimelineRepository.
+			On("FindFollowerIDs", mockUser.ID).
+			Return([]string{follower, authorFollower}, false, nil)
+		mockTimelineRepository.
+			On("FindFollowerIDs", mockPost.UserID).
+			Return([]string{authorFollower}, false, nil)
+		mockTimelineRepository.
+			On("RemoveRetweet", []string{follower, authorFollower, mockUser.ID}, mockPost.ID, mockUser.ID).
+			Return(nil)
+		mockTimelineRepository.
+			On("Add", []string{authorFollower}, model.TimelineEntry{PostID: mockPost.ID, CreatedAt: mockPost.CreatedAt}).
+			Return(nil)
+
+		err := ps.ToggleRetweet(mockPost, mockUser.ID)
+
+		assert.NoError(t, err)
+		mockPostRepository.AssertExpectations(t)
+		mockTimelineRepository.AssertExpectations(t)
+		mockPostRepository.AssertNotCalled(t, "AddRetweet", mockPost, mockUser.ID)
+	})
+
+	t.Run("Error from RemoveRetweet", func(t *testing.T) {
+		mockUser := fixture.GetMockUser()
+		mockPost := fixture.GetMockPost()
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("LoadViewerState", mockUser.ID, []*model.Post{mockPost}).
+			Run(func(args mock.Arguments) {
+				mockPost.Retweeted = true
+			}).
+			Return(nil)
+		mockPostRepository.On("RemoveRetweet", mockPost, mockUser.ID).Return(fmt.Errorf("some error down the call chain"))
+
+		err := ps.ToggleRetweet(mockPost, mockUser.ID)
+
+		assert.Error(t, err)
+		mockPostRepository.AssertExpectations(t)
+	})
+
+	t.Run("Error from AddRetweet", func(t *testing.T) {
+		mockUser := fixture.GetMockUser()
+		mockPost := fixture.GetMockPost()
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("LoadViewerState", mockUser.ID, []*model.Post{mockPost}).Return(nil)
+		mockPostRepository.On("AddRetweet", mockPost, mockUser.ID).Return(fmt.Errorf("some error down the call chain"))
+
+		err := ps.ToggleRetweet(mockPost, mockUser.ID)
+
+		assert.Error(t, err)
+		mockPostRepository.AssertExpectations(t)
+	})
+}
+
+func TestPostService_TogglePin(t *testing.T) {
+	t.Run("Pin", func(t *testing.T) {
+		mockUser := fixture.GetMockUser()
+		mockPost := fixture.GetMockPost()
+		mockPost.UserID = mockUser.ID
+
+		mockUserRepository := new(mocks.UserRepository)
+		ps := NewPostService(&PSConfig{
+			UserRepository: mockUserRepository,
+		})
+		mockUserRepository.On("FindByID", mockUser.ID).Return(mockUser, nil)
+		mockUserRepository.On("SetPinnedPost", mockUser.ID, &mockPost.ID).Return(nil)
+
+		err := ps.TogglePin(mockPost, mockUser.ID)
+
+		assert.NoError(t, err)
+		assert.True(t, mockPost.Pinned)
+		mockUserRepository.AssertExpectations(t)
+	})
+
+	t.Run("Unpin", func(t *testing.T) {
+		mockUser := fixture.GetMockUser()
+		mockPost := fixture.GetMockPost()
+		mockPost.UserID = mockUser.ID
+		mockUser.PinnedPostID = &mockPost.ID
+
+		mockUserRepository := new(mocks.UserRepository)
+		ps := NewPostService(&PSConfig{
+			UserRepository: mockUserRepository,
+		})
+		mockUserRepository.On("FindByID", mockUser.ID).Return(mockUser, nil)
+		mockUserRepository.On("SetPinnedPost", mockUser.ID, (*string)(nil)).Return(nil)
+
+		err := ps.TogglePin(mockPost, mockUser.ID)
+
+		assert.NoError(t, err)
+		assert.False(t, mockPost.Pinned)
+		mockUserRepository.AssertExpectations(t)
+	})
+
+	t.Run("Replaces the pinned post", func(t *testing.T) {
+		mockUser := fixture.GetMockUser()
+		pinned := fixture.GetMockPost().ID
+		mockUser.PinnedPostID = &pinned
+		mockPost := fixture.GetMockPost()
+		mockPost.UserID = mockUser.ID
+
+		mockUserRepository := new(mocks.UserRepository)
+		ps := NewPostService(&PSConfig{
+			UserRepository: mockUserRepository,
+		})
+		mockUserRepository.On("FindByID", mockUser.ID).Return(mockUser, nil)
+		mockUserRepository.On("SetPinnedPost", mockUser.ID, &mockPost.ID).Return(nil)
+
+		err := ps.TogglePin(mockPost, mockUser.ID)
+
+		assert.NoError(t, err)
+		assert.True(t, mockPost.Pinned)
+		mockUserRepository.AssertExpectations(t)
+	})
+
+	t.Run("Post of another user", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+
+		mockUserRepository := new(mocks.UserRepository)
+		ps := NewPostService(&PSConfig{
+			UserRepository: mockUserRepository,
+		})
+
+		err := ps.TogglePin(mockPost, fixture.RandID())
+
+		assert.Error(t, err)
+		assert.Equal(t, apperrors.Forbidden, err.(*apperrors.Error).Type)
+		mockUserRepository.AssertNotCalled(t, "SetPinnedPost")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockUser := fixture.GetMockUser()
+		mockPost := fixture.GetMockPost()
+		mockPost.UserID = mockUser.ID
+
+		mockUserRepository := new(mocks.UserRepository)
+		ps := NewPostService(&PSConfig{
+			UserRepository: mockUserRepository,
+		})
+		mockUserRepository.On("FindByID", mockUser.ID).Return(mockUser, nil)
+		mockUserRepository.On("SetPinnedPost", mockUser.ID, &mockPost.ID).Return(apperrors.NewInternal())
+
+		err := ps.TogglePin(mockPost, mockUser.ID)
+
+		assert.Error(t, err)
+		assert.False(t, mockPost.Pinned)
+		mockUserRepository.AssertExpectations(t)
+	})
+}
+
+func TestPostService_ToggleHidden(t *testing.T) {
+	t.Run("Hide", func(t *testing.T) {
+		parent := fixture.GetMockPost()
+		reply := fixture.GetMockPost()
+		reply.ReplyToID = &parent.ID
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("FindByID", parent.ID).Return(parent, nil)
+		mockPostRepository.On("SetHidden", reply, true).Return(nil)
+
+		err := ps.ToggleHidden(reply, parent.UserID)
+
+		assert.NoError(t, err)
+		assert.True(t, reply.Hidden)
+		mockPostRepository.AssertExpectations(t)
+	})
+
+	t.Run("Show", func(t *testing.T) {
+		parent := fixture.GetMockPost()
+		reply := fixture.GetMockPost()
+		reply.ReplyToID = &parent.ID
+		reply.Hidden = true
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("FindByID", parent.ID).Return(parent, nil)
+		mockPostRepository.On("SetHidden", reply, false).Return(nil)
+
+		err := ps.ToggleHidden(reply, parent.UserID)
+
+		assert.NoError(t, err)
+		assert.False(t, reply.Hidden)
+		mockPostRepository.AssertExpectations(t)
+	})
+
+	t.Run("Not a reply", func(t *testing.T) {
+		post := fixture.GetMockPost()
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+
+		err := ps.ToggleHidden(post, post.UserID)
+
+		assert.Error(t, err)
+		assert.Equal(t, apperrors.BadRequest, err.(*apperrors.Error).Type)
+		mockPostRepository.AssertNotCalled(t, "SetHidden", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Reply to another user's post", func(t *testing.T) {
+		parent := fixture.GetMockPost()
+		reply := fixture.GetMockPost()
+		reply.ReplyToID = &parent.ID
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("FindByID", parent.ID).Return(parent, nil)
+
+		err := ps.ToggleHidden(reply, reply.UserID)
+
+		assert.Error(t, err)
+		assert.Equal(t, apperrors.Forbidden, err.(*apperrors.Error).Type)
+		assert.False(t, reply.Hidden)
+		mockPostRepository.AssertNotCalled(t, "SetHidden", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		parent := fixture.GetMockPost()
+		reply := fixture.GetMockPost()
+		reply.ReplyToID = &parent.ID
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("FindByID", parent.ID).Return(parent, nil)
+		mockPostRepository.On("SetHidden", reply, true).Return(apperrors.NewInternal())
+
+		err := ps.ToggleHidden(reply, parent.UserID)
+
+		assert.Error(t, err)
+		assert.False(t, reply.Hidden)
+		mockPostRepository.AssertExpectations(t)
+	})
+}
+
+func TestPostService_GetReplies(t *testing.T) {
+	post := fixture.GetMockPost()
+
+	replies := make([]model.Post, 0)
+	for i := 0; i < 5; i++ {
+		reply := fixture.GetMockPost()
+		reply.ReplyToID = &post.ID
+		replies = append(replies, *reply)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("Replies", post.ID, false, model.Page{}).Return(&replies, nil)
+
+		result, err := ps.GetReplies(post, false, model.Page{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, replies, *result)
+		mockPostRepository.AssertExpectations(t)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("Replies", post.ID, true, model.Page{}).Return(nil, apperrors.NewInternal())
+
+		result, err := ps.GetReplies(post, true, model.Page{})
+
+		assert.Nil(t, result)
+		assert.Error(t, err)
+		mockPostRepository.AssertExpectations(t)
+	})
+}
+
+func TestPostService_Vote(t *testing.T) {
+	newPoll := func(multipleChoice bool) *model.Poll {
+		return &model.Poll{
+			MultipleChoice: multipleChoice,
+			EndsAt:         time.Now().Add(time.Hour),
+			Options:        []model.PollOption{{Position: 0, Text: "Yes"}, {Position: 1, Text: "No"}, {Position: 2, Text: "Maybe"}},
+		}
+	}
+
+	t.Run("Adds the vote", func(t *testing.T) {
+		mockPost := fixture.GetMockPost()
+		mockPost.Poll = newPoll(true)
+		uid := fixture.RandID()
+		choices := []int{0, 2}
+
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+		mockPostRepository.On("AddVote", mockPost.ID, uid, choices).Return(nil)
+
+		err := ps.Vote(mockPost, uid, choices)
+
+		assert.NoError(t, err)
+		mockPostRepository.AssertExpectations(t)
+	})
+
+	t.Run("Rejects invalid choices", func(t *testing.T) {
+		cases := map[string]struct {
+			multipleChoice bool
+			choices        []int
+		}{
+			"No choice":                   {true, []int{}},
+			"Unknown option":              {true, []int{3}},
+			"Negative option":             {true, []int{-1}},
+			"Duplicate option":            {true, []int{1, 1}},
+			"Multiple on a single choice": {false, []int{0, 1}},
+		}
+
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				mockPost := fixture.GetMockPost()
+				mockPost.Poll = newPoll(tc.multipleChoice)
+
+				mockPostRepository := new(mocks.PostRepository)
+				ps := NewPostService(&PSConfig{
+					PostRepository: mockPostRepository,
+				})
+
+				err := ps.Vote(mockPost, fixture.RandID(), tc.choices)
+
+				assert.Equal(t, http.StatusBadRequest, apperrors.Status(err))
+				mockPostRepository.AssertNotCalled(t, "AddVote", mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
 	})
-}
 
-func TestPostService_ToggleLike(t *testing.T) {
-	t.Run("Success change to liked", func(t *testing.T) {
-		uid, _ := GenerateId()
+	t.Run("Post without a poll", func(t *testing.T) {
 		mockPost := fixture.GetMockPost()
 
 		mockPostRepository := new(mocks.PostRepository)
 		ps := NewPostService(&PSConfig{
 			PostRepository: mockPostRepository,
 		})
-		mockPostRepository.On("AddLike", mockPost, uid).Return(nil)
 
-		err := ps.ToggleLike(mockPost, uid)
+		err := ps.Vote(mockPost, fixture.RandID(), []int{0})
 
-		assert.NoError(t, err)
-		mockPostRepository.AssertExpectations(t)
-		mockPostRepository.AssertNotCalled(t, "RemoveLike", mockPost, uid)
+		assert.Equal(t, http.StatusNotFound, apperrors.Status(err))
+		mockPostRepository.AssertNotCalled(t, "AddVote", mock.Anything, mock.Anything, mock.Anything)
 	})
 
-	t.Run("Success change to unliked", func(t *testing.T) {
-		mockUser := fixture.GetMockUser()
+	t.Run("Closed poll", func(t *testing.T) {
 		mockPost := fixture.GetMockPost()
-		mockPost.Likes = append(mockPost.Likes, *mockUser)
+		mockPost.Poll = newPoll(false)
+		mockPost.Poll.EndsAt = time.Now().Add(-time.Minute)
 
 		mockPostRepository := new(mocks.PostRepository)
 		ps := NewPostService(&PSConfig{
 			PostRepository: mockPostRepository,
 		})
-		mockPostRepository.On("RemoveLike", mockPost, mockUser.ID).Return(nil)
 
-		err := ps.ToggleLike(mockPost, mockUser.ID)
+		err := ps.Vote(mockPost, fixture.RandID(), []int{0})
 
-		assert.NoError(t, err)
-		mockPostRepository.AssertExpectations(t)
-		mockPostRepository.AssertNotCalled(t, "AddLike", mockPost, mockUser.ID)
+		assert.Equal(t, http.StatusForbidden, apperrors.Status(err))
+		mockPostRepository.AssertNotCalled(t, "AddVote", mock.Anything, mock.Anything, mock.Anything)
 	})
 
-	t.Run("Error from RemoveLike", func(t *testing.T) {
-		mockUser := fixture.GetMockUser()
+	t.Run("Author can't vote", func(t *testing.T) {
 		mockPost := fixture.GetMockPost()
-		mockPost.Likes = append(mockPost.Likes, *mockUser)
+		mockPost.Poll = newPoll(false)
 
 		mockPostRepository := new(mocks.PostRepository)
 		ps := NewPostService(&PSConfig{
 			PostRepository: mockPostRepository,
 		})
-		mockPostRepository.On("RemoveLike", mockPost, mockUser.ID).Return(fmt.Errorf("some error down the call chain"))
 
-		err := ps.ToggleLike(mockPost, mockUser.ID)
+		err := ps.Vote(mockPost, mockPost.UserID, []int{0})
 
-		assert.Error(t, err)
-		mockPostRepository.AssertExpectations(t)
+		assert.Equal(t, http.StatusForbidden, apperrors.Status(err))
+		mockPostRepository.AssertNotCalled(t, "AddVote", mock.Anything, mock.Anything, mock.Anything)
 	})
 
-	t.Run("Error from AddLike", func(t *testing.T) {
-		mockUser := fixture.GetMockUser()
+	t.Run("Second vote conflicts", func(t *testing.T) {
 		mockPost := fixture.GetMockPost()
+		mockPost.Poll = newPoll(false)
+		uid := fixture.RandID()
 
 		mockPostRepository := new(mocks.PostRepository)
 		ps := NewPostService(&PSConfig{
 			PostRepository: mockPostRepository,
 		})
-		mockPostRepository.On("AddLike", mockPost, mockUser.ID).Return(fmt.Errorf("some error down the call chain"))
+		mockErr := apperrors.NewConflict("vote")
+		mockPostRepository.On("AddVote", mockPost.ID, uid, []int{1}).Return(mockErr)
 
-		err := ps.ToggleLike(mockPost, mockUser.ID)
+		err := ps.Vote(mockPost, uid, []int{1})
 
-		assert.Error(t, err)
+		assert.Equal(t, mockErr, err)
 		mockPostRepository.AssertExpectations(t)
 	})
 }
 
-func TestPostService_ToggleRetweet(t *testing.T) {
-	t.Run("Successful retweet", func(t *testing.T) {
-		uid, _ := GenerateId()
+func TestPostService_ProfilePosts(t *testing.T) {
+	profile := fixture.GetMockUser()
+
+	posts := make([]model.Post, 0)
+	entries := make([]model.TimelineEntry, 0)
+	for i := 0; i < 5; i++ {
 		mockPost := fixture.GetMockPost()
+		mockPost.UserID = profile.ID
+		posts = append(posts, *mockPost)
+		entries = append(entries, model.TimelineEntry{PostID: mockPost.ID, CreatedAt: mockPost.CreatedAt})
+	}
+
+	ids := make([]string, 0)
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
 
+	t.Run("Success", func(t *testing.T) {
 		mockPostRepository := new(mocks.PostRepository)
+		mockUserRepository := new(mocks.UserRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
 		ps := NewPostService(&PSConfig{
-			PostRepository: mockPostRepository,
+			PostRepository:     mockPostRepository,
+			UserRepository:     mockUserRepository,
+			TimelineRepository: mockTimelineRepository,
 		})
-		mockPostRepository.On("AddRetweet", mockPost, uid).Return(nil)
+		mockUserRepository.On("FindByID", profile.ID).Return(profile, nil)
+		mockTimelineRepository.On("FindEntries", []string{profile.ID}, model.Page{}, model.LIMIT+1, ([]string)(nil)).Return(entries, nil)
+		mockPostRepository.On("FindByIDs", ids).Return(&posts, nil)
 
-		err := ps.ToggleRetweet(mockPost, uid)
+		result, err := ps.ProfilePosts(profile.ID, model.Page{})
 
 		assert.NoError(t, err)
+		assert.Equal(t, posts, *result)
 		mockPostRepository.AssertExpectations(t)
-		mockPostRepository.AssertNotCalled(t, "RemoveRetweet", mockPost, uid)
+		mockTimelineRepository.AssertExpectations(t)
 	})
 
-	t.Run("Successfully removed retweet", func(t *testing.T) {
-		mockUser := fixture.GetMockUser()
-		mockPost := fixture.GetMockPost()
-		mockPost.Retweets = append(mockPost.Retweets, *mockUser)
+	t.Run("Leaves out the pinned post", func(t *testing.T) {
+		pinner := *profile
+		pinner.PinnedPostID = &posts[1].ID
+
+		// The pinned post was retweeted by the user as well
+		retweetedAt := time.Now()
+		withRetweet := append([]model.TimelineEntry{{PostID: posts[1].ID, RetweetedBy: profile.ID, CreatedAt: retweetedAt}}, entries...)
+
+		expectedIds := []string{posts[1].ID, posts[0].ID, posts[2].ID, posts[3].ID, posts[4].ID}
+		found := []model.Post{posts[0], posts[1], posts[2], posts[3], posts[4]}
 
 		mockPostRepository := new(mocks.PostRepository)
+		mockUserRepository := new(mocks.UserRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
 		ps := NewPostService(&PSConfig{
-			PostRepository: mockPostRepository,
+			PostRepository:     mockPostRepository,
+			UserRepository:     mockUserRepository,
+			TimelineRepository: mockTimelineRepository,
 		})
-		mockPostRepository.On("RemoveRetweet", mockPost, mockUser.ID).Return(nil)
+		mockUserRepository.On("FindByID", profile.ID).Return(&pinner, nil)
+		mockTimelineRepository.On("FindEntries", []string{profile.ID}, model.Page{}, model.LIMIT+2, ([]string)(nil)).Return(withRetweet, nil)
+		mockUserRepository.On("FindByIDs", []string{profile.ID}).Return(&[]model.User{*profile}, nil)
+		mockPostRepository.On("FindByIDs", expectedIds).Return(&found, nil)
 
-		err := ps.ToggleRetweet(mockPost, mockUser.ID)
+		result, err := ps.ProfilePosts(profile.ID, model.Page{})
 
 		assert.NoError(t, err)
+		assert.Len(t, *result, 5)
+		assert.Equal(t, posts[1].ID, (*result)[0].ID)
+		assert.NotNil(t, (*result)[0].RetweetedBy)
+		for _, post := range (*result)[1:] {
+			assert.NotEqual(t, posts[1].ID, post.ID)
+		}
 		mockPostRepository.AssertExpectations(t)
-		mockPostRepository.AssertNotCalled(t, "AddRetweet", mockPost, mockUser.ID)
+		mockTimelineRepository.AssertExpectations(t)
 	})
 
-	t.Run("Error from RemoveRetweet", func(t *testing.T) {
-		mockUser := fixture.GetMockUser()
+	t.Run("Error", func(t *testing.T) {
+		mockUserRepository := new(mocks.UserRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			UserRepository:     mockUserRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		mockUserRepository.On("FindByID", profile.ID).Return(profile, nil)
+		mockTimelineRepository.On("FindEntries", []string{profile.ID}, model.Page{}, model.LIMIT+1, ([]string)(nil)).Return(nil, fmt.Errorf("some error down the call chain"))
+
+		result, err := ps.ProfilePosts(profile.ID, model.Page{})
+
+		assert.Nil(t, result)
+		assert.Error(t, err)
+		mockTimelineRepository.AssertExpectations(t)
+	})
+}
+
+func TestPostService_GetUserFeed(t *testing.T) {
+	authUser := fixture.GetMockUser()
+
+	posts := make([]model.Post, 0)
+	entries := make([]model.TimelineEntry, 0)
+	for i := 0; i < 5; i++ {
 		mockPost := fixture.GetMockPost()
-		mockPost.Retweets = append(mockPost.Retweets, *mockUser)
+		mockPost.CreatedAt = time.Now().Add(-time.Duration(i) * time.Hour)
+		posts = append(posts, *mockPost)
+		entries = append(entries, model.TimelineEntry{PostID: mockPost.ID, CreatedAt: mockPost.CreatedAt})
+	}
+
+	ids := func(entries []model.TimelineEntry) []string {
+		result := make([]string, 0)
+		for _, entry := range entries {
+			result = append(result, entry.PostID)
+		}
+		return result
+	}
+
+	// The repository doesn't return the posts in timeline order
+	reversed := make([]model.Post, 0)
+	for i := len(posts) - 1; i >= 0; i-- {
+		reversed = append(reversed, posts[i])
+	}
 
+	t.Run("Success", func(t *testing.T) {
 		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
 		ps := NewPostService(&PSConfig{
-			PostRepository: mockPostRepository,
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
 		})
-		mockPostRepository.On("RemoveRetweet", mockPost, mockUser.ID).Return(fmt.Errorf("some error down the call chain"))
 
-		err := ps.ToggleRetweet(mockPost, mockUser.ID)
+		mockTimelineRepository.On("Range", authUser.ID, model.Page{}, model.LIMIT+1).Return(entries, true, nil)
+		mockTimelineRepository.On("IsComplete", authUser.ID).Return(true, nil)
+		mockTimelineRepository.On("FindFolloweeIDs", authUser.ID).Return([]string{}, []string{}, nil)
+		mockTimelineRepository.On("Remove", []string{authUser.ID}).Return(nil)
+		mockPostRepository.On("FindByIDs", ids(entries)).Return(&reversed, nil)
 
-		assert.Error(t, err)
+		result, err := ps.GetUserFeed(authUser.ID, model.Page{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, posts, *result)
 		mockPostRepository.AssertExpectations(t)
+		mockTimelineRepository.AssertExpectations(t)
 	})
 
-	t.Run("Error from AddRetweet", func(t *testing.T) {
-		mockUser := fixture.GetMockUser()
-		mockPost := fixture.GetMockPost()
-
+	t.Run("Merges posts of celebrities", func(t *testing.T) {
 		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
 		ps := NewPostService(&PSConfig{
-			PostRepository: mockPostRepository,
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
 		})
-		mockPostRepository.On("AddRetweet", mockPost, mockUser.ID).Return(fmt.Errorf("some error down the call chain"))
 
-		err := ps.ToggleRetweet(mockPost, mockUser.ID)
+		celebrity, _ := GenerateId()
+		page := model.Page{Before: &model.Cursor{Time: time.Now().Add(time.Minute).Truncate(time.Millisecond), ID: authUser.ID}}
 
-		assert.Error(t, err)
+		timeline := []model.TimelineEntry{entries[0], entries[2], entries[3], entries[4]}
+		fromCelebrity := []model.TimelineEntry{entries[1]}
+
+		// Posts already in the timeline don't get merged in again
+		members := ids(timeline)
+
+		mockTimelineRepository.On("Range", authUser.ID, page, model.LIMIT+1).Return(timeline, true, nil)
+		mockTimelineRepository.On("IsComplete", authUser.ID).Return(true, nil)
+		mockTimelineRepository.On("FindFolloweeIDs", authUser.ID).Return([]string{}, []string{celebrity}, nil)
+		mockTimelineRepository.On("Members", authUser.ID).Return(members, nil)
+		mockTimelineRepository.On("FindEntries", []string{celebrity}, page, model.LIMIT+1, members).Return(fromCelebrity, nil)
+		mockTimelineRepository.On("Remove", []string{authUser.ID}).Return(nil)
+		mockPostRepository.On("FindByIDs", ids(entries)).Return(&reversed, nil)
+
+		result, err := ps.GetUserFeed(authUser.ID, page)
+
+		assert.NoError(t, err)
+		assert.Equal(t, posts, *result)
 		mockPostRepository.AssertExpectations(t)
+		mockTimelineRepository.AssertExpectations(t)
 	})
-}
 
-func TestPostService_ProfilePosts(t *testing.T) {
+	t.Run("Attributes retweets", func(t *testing.T) {
+		mockPostRepository := new(mocks.PostRepository)
+		mockUserRepository := new(mocks.UserRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			UserRepository:     mockUserRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
 
-	authUser := fixture.GetMockUser()
-	profile := fixture.GetMockUser()
+		retweeter := fixture.GetMockUser()
+		retweetedAt := time.Now().Add(time.Hour)
 
-	for i := 0; i < 5; i++ {
-		mockPost := fixture.GetMockPost()
-		profile.Posts = append(profile.Posts, *mockPost)
-	}
+		// The retweet moved the oldest post to the top
+		timeline := []model.TimelineEntry{
+			{PostID: entries[4].PostID, RetweetedBy: retweeter.ID, CreatedAt: retweetedAt},
+			entries[0], entries[1], entries[2], entries[3],
+		}
 
-	t.Run("Success", func(t *testing.T) {
+		mockTimelineRepository.On("Range", authUser.ID, model.Page{}, model.LIMIT+1).Return(timeline, true, nil)
+		mockTimelineRepository.On("IsComplete", authUser.ID).Return(true, nil)
+		mockTimelineRepository.On("FindFolloweeIDs", authUser.ID).Return([]string{}, []string{}, nil)
+		mockTimelineRepository.On("Remove", []string{authUser.ID}).Return(nil)
+		mockPostRepository.On("FindByIDs", ids(timeline)).Return(&reversed, nil)
+		mockUserRepository.On("FindByIDs", []string{retweeter.ID}).Return(&[]model.User{*retweeter}, nil)
+
+		result, err := ps.GetUserFeed(authUser.ID, model.Page{})
+
+		assert.NoError(t, err)
+		assert.Len(t, *result, 5)
+		assert.Equal(t, entries[4].PostID, (*result)[0].ID)
+		assert.Equal(t, retweeter.ID, (*result)[0].RetweetedBy.ID)
+		assert.Equal(t, retweetedAt, *(*result)[0].RetweetedAt)
+		assert.Nil(t, (*result)[1].RetweetedBy)
+		mockUserRepository.AssertExpectations(t)
+	})
+
+	t.Run("Rebuilds a missing timeline", func(t *testing.T) {
 		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
 		ps := NewPostService(&PSConfig{
-			PostRepository: mockPostRepository,
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
 		})
-		mockPostRepository.On("List", authUser.ID, "").Return(&profile.Posts, nil)
 
-		posts, err := ps.ProfilePosts(authUser.ID, "")
+		followee, _ := GenerateId()
+
+		mockTimelineRepository.On("Range", authUser.ID, model.Page{}, model.LIMIT+1).Return([]model.TimelineEntry{}, false, nil)
+		mockTimelineRepository.On("FindFolloweeIDs", authUser.ID).Return([]string{followee}, []string{}, nil)
+		mockTimelineRepository.On("FindEntries", []string{followee, authUser.ID}, model.Page{}, model.TimelineSize, ([]string)(nil)).Return(entries, nil)
+		mockTimelineRepository.On("Replace", authUser.ID, entries, true).Return(nil)
+		mockTimelineRepository.On("Remove", []string{authUser.ID}).Return(nil)
+		mockPostRepository.On("FindByIDs", ids(entries)).Return(&reversed, nil)
+
+		result, err := ps.GetUserFeed(authUser.ID, model.Page{})
 
 		assert.NoError(t, err)
-		assert.Equal(t, len(*posts), 5)
-		mockPostRepository.AssertExpectations(t)
+		assert.Equal(t, posts, *result)
+		mockTimelineRepository.AssertExpectations(t)
 	})
 
-	t.Run("Error", func(t *testing.T) {
+	t.Run("Reads past the cached timeline", func(t *testing.T) {
 		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
 		ps := NewPostService(&PSConfig{
-			PostRepository: mockPostRepository,
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
 		})
 
-		mockPostRepository.On("List", authUser.ID, "").Return(nil, fmt.Errorf("some error down the call chain"))
+		followee, _ := GenerateId()
 
-		posts, err := ps.ProfilePosts(authUser.ID, "")
+		// The timeline got trimmed and only kept the newest posts
+		cached := entries[:3]
+		cursor := cached[len(cached)-1].Cursor()
 
-		assert.Nil(t, posts)
-		assert.Error(t, err)
+		mockTimelineRepository.On("Range", authUser.ID, model.Page{}, model.LIMIT+1).Return(cached, true, nil)
+		mockTimelineRepository.On("IsComplete", authUser.ID).Return(false, nil)
+		mockTimelineRepository.On("FindFolloweeIDs", authUser.ID).Return([]string{followee}, []string{}, nil)
+		mockTimelineRepository.
+			On("FindEntries", []string{followee, authUser.ID}, model.Page{Before: &cursor}, model.LIMIT+1-len(cached), ([]string)(nil)).
+			Return(entries[3:], nil)
+		mockTimelineRepository.On("Remove", []string{authUser.ID}).Return(nil)
+		mockPostRepository.On("FindByIDs", ids(entries)).Return(&reversed, nil)
+
+		result, err := ps.GetUserFeed(authUser.ID, model.Page{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, posts, *result)
 		mockPostRepository.AssertExpectations(t)
+		mockTimelineRepository.AssertExpectations(t)
 	})
-}
 
-func TestPostService_GetUserFeed(t *testing.T) {
+	t.Run("Drops deleted posts", func(t *testing.T) {
+		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
 
-	authUser := fixture.GetMockUser()
-	profile := fixture.GetMockUser()
-	profile.Followers = append(profile.Followers, authUser)
+		existing := posts[1:]
 
-	for i := 0; i < 5; i++ {
-		mockPost := fixture.GetMockPost()
-		profile.Posts = append(profile.Posts, *mockPost)
-	}
+		mockTimelineRepository.On("Range", authUser.ID, model.Page{}, model.LIMIT+1).Return(entries, true, nil)
+		mockTimelineRepository.On("IsComplete", authUser.ID).Return(true, nil)
+		mockTimelineRepository.On("FindFolloweeIDs", authUser.ID).Return([]string{}, []string{}, nil)
+		mockTimelineRepository.On("Remove", []string{authUser.ID}, posts[0].ID).Return(nil)
+		mockPostRepository.On("FindByIDs", ids(entries)).Return(&existing, nil)
 
-	t.Run("Success", func(t *testing.T) {
+		result, err := ps.GetUserFeed(authUser.ID, model.Page{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, existing, *result)
+		mockTimelineRepository.AssertExpectations(t)
+	})
+
+	t.Run("Reads past deleted posts", func(t *testing.T) {
 		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
 		ps := NewPostService(&PSConfig{
-			PostRepository: mockPostRepository,
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		timeline := make([]model.TimelineEntry, 0)
+		live := make([]model.Post, 0)
+		for i := 0; i < model.LIMIT+4; i++ {
+			mockPost := fixture.GetMockPost()
+			mockPost.CreatedAt = time.Now().Add(-time.Duration(i) * time.Minute).Truncate(time.Millisecond)
+			timeline = append(timeline, model.TimelineEntry{PostID: mockPost.ID, CreatedAt: mockPost.CreatedAt})
+			// The second and third post got deleted
+			if i != 1 && i != 2 {
+				live = append(live, *mockPost)
+			}
+		}
+
+		first := timeline[:model.LIMIT+1]
+		cursor := first[len(first)-1].Cursor()
+		rest := timeline[model.LIMIT+1:]
+
+		mockTimelineRepository.On("Range", authUser.ID, model.Page{}, model.LIMIT+1).Return(first, true, nil)
+		mockTimelineRepository.On("Range", authUser.ID, model.Page{Before: &cursor}, model.LIMIT+1).Return(rest, true, nil)
+		mockTimelineRepository.On("IsComplete", authUser.ID).Return(true, nil)
+		mockTimelineRepository.On("FindFolloweeIDs", authUser.ID).Return([]string{}, []string{}, nil)
+		mockTimelineRepository.On("Remove", []string{authUser.ID}, timeline[1].PostID, timeline[2].PostID).Return(nil)
+		mockTimelineRepository.On("Remove", []string{authUser.ID}).Return(nil)
+
+		firstLive := live[:model.LIMIT-1]
+		restLive := live[model.LIMIT-1:]
+		mockPostRepository.On("FindByIDs", ids(first)).Return(&firstLive, nil)
+		mockPostRepository.On("FindByIDs", ids(rest)).Return(&restLive, nil)
+
+		result, err := ps.GetUserFeed(authUser.ID, model.Page{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, live, *result)
+
+		// The page is full and the feed goes on
+		items, hasMore := model.Trim(*result, model.Page{})
+		assert.Len(t, items, model.LIMIT)
+		assert.True(t, hasMore)
+		mockPostRepository.AssertExpectations(t)
+		mockTimelineRepository.AssertExpectations(t)
+	})
+
+	t.Run("Falls back to the database", func(t *testing.T) {
+		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
 		})
-		mockPostRepository.On("Feed", authUser.ID, "").Return(&profile.Posts, nil)
 
-		posts, err := ps.GetUserFeed(authUser.ID, "")
+		followee, _ := GenerateId()
+		celebrity := fixture.RandID()
+
+		mockTimelineRepository.On("Range", authUser.ID, model.Page{}, model.LIMIT+1).Return(nil, false, apperrors.NewInternal())
+		mockTimelineRepository.On("FindFolloweeIDs", authUser.ID).Return([]string{followee}, []string{celebrity}, nil)
+		mockTimelineRepository.
+			On("FindEntries", []string{followee, celebrity, authUser.ID}, model.Page{}, model.LIMIT+1, ([]string)(nil)).
+			Return(entries, nil)
+		mockPostRepository.On("FindByIDs", ids(entries)).Return(&reversed, nil)
+
+		result, err := ps.GetUserFeed(authUser.ID, model.Page{})
 
 		assert.NoError(t, err)
-		assert.Equal(t, len(*posts), 5)
+		assert.Equal(t, posts, *result)
 		mockPostRepository.AssertExpectations(t)
+		mockTimelineRepository.AssertExpectations(t)
+		mockTimelineRepository.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Pages towards newer posts", func(t *testing.T) {
+		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		// Only the two posts right after the cursor fit on the page
+		page := model.Page{After: &model.Cursor{Time: entries[3].CreatedAt.Truncate(time.Millisecond), ID: entries[3].PostID}}
+		timeline := []model.TimelineEntry{entries[1], entries[2]}
+		celebrity := fixture.RandID()
+		fromCelebrity := []model.TimelineEntry{entries[0], entries[2]}
+
+		mockTimelineRepository.On("Range", authUser.ID, page, 2).Return(timeline, true, nil)
+		mockTimelineRepository.On("FindFolloweeIDs", authUser.ID).Return([]string{}, []string{celebrity}, nil)
+		mockTimelineRepository.On("Members", authUser.ID).Return(ids(timeline), nil)
+		mockTimelineRepository.On("FindEntries", []string{celebrity}, page, 2, ids(timeline)).Return(fromCelebrity, nil)
+
+		result, err := ps.(*postService).timelineEntries(authUser.ID, page, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.TimelineEntry{entries[1], entries[2]}, result)
+		mockTimelineRepository.AssertExpectations(t)
 	})
 
 	t.Run("Error", func(t *testing.T) {
 		mockPostRepository := new(mocks.PostRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
 		ps := NewPostService(&PSConfig{
-			PostRepository: mockPostRepository,
+			PostRepository:     mockPostRepository,
+			TimelineRepository: mockTimelineRepository,
 		})
 
-		mockPostRepository.On("Feed", authUser.ID, "").Return(nil, fmt.Errorf("some error down the call chain"))
+		mockTimelineRepository.On("Range", authUser.ID, model.Page{}, model.LIMIT+1).Return(entries, true, nil)
+		mockTimelineRepository.On("IsComplete", authUser.ID).Return(true, nil)
+		mockTimelineRepository.On("FindFolloweeIDs", authUser.ID).Return([]string{}, []string{}, nil)
+		mockPostRepository.On("FindByIDs", ids(entries)).Return(nil, fmt.Errorf("some error down the call chain"))
 
-		posts, err := ps.GetUserFeed(authUser.ID, "")
+		result, err := ps.GetUserFeed(authUser.ID, model.Page{})
 
-		assert.Nil(t, posts)
+		assert.Nil(t, result)
 		assert.Error(t, err)
 		mockPostRepository.AssertExpectations(t)
 	})
 }
 
+func TestPostService_NewFeedPostIDs(t *testing.T) {
+	authUser := fixture.GetMockUser()
+	since := model.Cursor{Time: time.Now().Add(-time.Hour).Truncate(time.Millisecond), ID: fixture.RandID()}
+	page := model.Page{After: &since}
+
+	t.Run("Success", func(t *testing.T) {
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		entries := []model.TimelineEntry{
+			{PostID: fixture.RandID(), CreatedAt: time.Now()},
+			{PostID: fixture.RandID(), CreatedAt: time.Now().Add(-time.Minute)},
+		}
+
+		mockTimelineRepository.On("Range", authUser.ID, page, model.NewPostsLimit+1).Return(entries, true, nil)
+		mockTimelineRepository.On("FindFolloweeIDs", authUser.ID).Return([]string{}, []string{}, nil)
+
+		ids, hasMore, err := ps.NewFeedPostIDs(authUser.ID, since)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{entries[0].PostID, entries[1].PostID}, ids)
+		assert.False(t, hasMore)
+		mockTimelineRepository.AssertExpectations(t)
+	})
+
+	t.Run("Caps the number of posts", func(t *testing.T) {
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		entries := make([]model.TimelineEntry, 0)
+		for i := 0; i < model.NewPostsLimit+1; i++ {
+			entries = append(entries, model.TimelineEntry{PostID: fixture.RandID(), CreatedAt: time.Now().Add(-time.Duration(i) * time.Second)})
+		}
+
+		mockTimelineRepository.On("Range", authUser.ID, page, model.NewPostsLimit+1).Return(entries, true, nil)
+		mockTimelineRepository.On("FindFolloweeIDs", authUser.ID).Return([]string{}, []string{}, nil)
+
+		ids, hasMore, err := ps.NewFeedPostIDs(authUser.ID, since)
+
+		assert.NoError(t, err)
+		assert.Len(t, ids, model.NewPostsLimit)
+		assert.Equal(t, entries[len(entries)-1].PostID, ids[len(ids)-1])
+		assert.True(t, hasMore)
+	})
+
+	t.Run("Falls back to the database", func(t *testing.T) {
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		ps := NewPostService(&PSConfig{
+			TimelineRepository: mockTimelineRepository,
+		})
+
+		entries := []model.TimelineEntry{{PostID: fixture.RandID(), CreatedAt: time.Now()}}
+
+		mockTimelineRepository.On("Range", authUser.ID, page, model.NewPostsLimit+1).Return(nil, false, apperrors.NewInternal())
+		mockTimelineRepository.On("FindFolloweeIDs", authUser.ID).Return([]string{}, []string{}, nil)
+		mockTimelineRepository.
+			On("FindEntries", []string{authUser.ID}, page, model.NewPostsLimit+1, ([]string)(nil)).
+			Return(entries, nil)
+
+		ids, hasMore, err := ps.NewFeedPostIDs(authUser.ID, since)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{entries[0].PostID}, ids)
+		assert.False(t, hasMore)
+		mockTimelineRepository.AssertExpectations(t)
+	})
+}
+
 func TestPostService_ProfileLikes(t *testing.T) {
 
 	authUser := fixture.GetMockUser()
@@ -487,9 +2195,9 @@ func TestPostService_ProfileLikes(t *testing.T) {
 		ps := NewPostService(&PSConfig{
 			PostRepository: mockPostRepository,
 		})
-		mockPostRepository.On("Likes", authUser.ID, "").Return(&posts, nil)
+		mockPostRepository.On("Likes", authUser.ID, model.Page{}).Return(&posts, nil)
 
-		rsp, err := ps.ProfileLikes(authUser.ID, "")
+		rsp, err := ps.ProfileLikes(authUser.ID, model.Page{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, len(*rsp), 5)
@@ -502,9 +2210,9 @@ func TestPostService_ProfileLikes(t *testing.T) {
 			PostRepository: mockPostRepository,
 		})
 
-		mockPostRepository.On("Likes", authUser.ID, "").Return(nil, fmt.Errorf("some error down the call chain"))
+		mockPostRepository.On("Likes", authUser.ID, model.Page{}).Return(nil, fmt.Errorf("some error down the call chain"))
 
-		rsp, err := ps.ProfileLikes(authUser.ID, "")
+		rsp, err := ps.ProfileLikes(authUser.ID, model.Page{})
 
 		assert.Nil(t, rsp)
 		assert.Error(t, err)
@@ -531,9 +2239,9 @@ func TestPostService_SearchPosts(t *testing.T) {
 
 		term := "tes"
 
-		mockPostRepository.On("GetPostsForHashtag", term, "").Return(&posts, nil)
+		mockPostRepository.On("Search", model.PostQuery{Text: term, Sort: model.SortRecent}, model.Page{}).Return(&posts, nil)
 
-		rsp, err := ps.SearchPosts(term, "")
+		rsp, err := ps.SearchPosts(term, model.SortRecent, model.Page{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, 5, len(*rsp))
@@ -547,14 +2255,90 @@ func TestPostService_SearchPosts(t *testing.T) {
 		})
 
 		term := "tes"
-		mockPostRepository.On("GetPostsForHashtag", term, "").Return(nil, fmt.Errorf("some error down the call chain"))
+		mockPostRepository.On("Search", model.PostQuery{Text: term, Sort: model.SortRecent}, model.Page{}).Return(nil, fmt.Errorf("some error down the call chain"))
 
-		rsp, err := ps.SearchPosts(term, "")
+		rsp, err := ps.SearchPosts(term, model.SortRecent, model.Page{})
 
 		assert.Nil(t, rsp)
 		assert.Error(t, err)
 		mockPostRepository.AssertExpectations(t)
 	})
+
+	t.Run("Sorted by relevance", func(t *testing.T) {
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+
+		rank := 0.5
+		page := model.Page{Before: &model.Cursor{Rank: &rank, Time: time.Now(), ID: fixture.RandID()}}
+		mockPostRepository.On("Search", model.PostQuery{Text: "tes", Sort: model.SortRelevance}, page).Return(&posts, nil)
+
+		rsp, err := ps.SearchPosts("tes", model.SortRelevance, page)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, len(*rsp))
+		mockPostRepository.AssertExpectations(t)
+	})
+
+	t.Run("Relevance cursor without rank", func(t *testing.T) {
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+
+		page := model.Page{After: &model.Cursor{Time: time.Now(), ID: fixture.RandID()}}
+
+		rsp, err := ps.SearchPosts("tes", model.SortRelevance, page)
+
+		assert.Nil(t, rsp)
+		assert.Equal(t, apperrors.BadRequest, err.(*apperrors.Error).Type)
+		mockPostRepository.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Operators without text", func(t *testing.T) {
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+
+		// There's nothing to rank, so the posts get sorted by recency
+		query := model.PostQuery{From: "alice", HasMedia: true, Sort: model.SortRecent}
+		page := model.Page{Before: &model.Cursor{Time: time.Now(), ID: fixture.RandID()}}
+		mockPostRepository.On("Search", query, page).Return(&posts, nil)
+
+		rsp, err := ps.SearchPosts("from:@alice has:media", model.SortRelevance, page)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, len(*rsp))
+		mockPostRepository.AssertExpectations(t)
+	})
+
+	t.Run("Invalid operator", func(t *testing.T) {
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+
+		rsp, err := ps.SearchPosts("hello min_likes:many", model.SortRecent, model.Page{})
+
+		assert.Nil(t, rsp)
+		assert.Equal(t, 17, *err.(*apperrors.Error).Position)
+		mockPostRepository.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Empty query", func(t *testing.T) {
+		mockPostRepository := new(mocks.PostRepository)
+		ps := NewPostService(&PSConfig{
+			PostRepository: mockPostRepository,
+		})
+
+		rsp, err := ps.SearchPosts("  ", model.SortRecent, model.Page{})
+
+		assert.NoError(t, err)
+		assert.Empty(t, *rsp)
+		mockPostRepository.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
 }
 
 func TestPostService_ProfileMedia(t *testing.T) {
@@ -574,9 +2358,9 @@ func TestPostService_ProfileMedia(t *testing.T) {
 		ps := NewPostService(&PSConfig{
 			PostRepository: mockPostRepository,
 		})
-		mockPostRepository.On("Media", profile.ID, "").Return(&posts, nil)
+		mockPostRepository.On("Media", profile.ID, model.Page{}).Return(&posts, nil)
 
-		rsp, err := ps.ProfileMedia(profile.ID, "")
+		rsp, err := ps.ProfileMedia(profile.ID, model.Page{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, len(*rsp), 5)
@@ -589,9 +2373,9 @@ func TestPostService_ProfileMedia(t *testing.T) {
 			PostRepository: mockPostRepository,
 		})
 
-		mockPostRepository.On("Media", profile.ID, "").Return(nil, fmt.Errorf("some error down the call chain"))
+		mockPostRepository.On("Media", profile.ID, model.Page{}).Return(nil, fmt.Errorf("some error down the call chain"))
 
-		rsp, err := ps.ProfileMedia(profile.ID, "")
+		rsp, err := ps.ProfileMedia(profile.ID, model.Page{})
 
 		assert.Nil(t, rsp)
 		assert.Error(t, err)
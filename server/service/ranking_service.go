@@ -0,0 +1,241 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+type rankingService struct {
+	RankingRepository model.RankingRepository
+	PostRepository    model.PostRepository
+	Config            model.RankingConfig
+}
+
+// RSConfig will hold repositories that will eventually be injected into this
+// this service layer
+type RSConfig struct {
+	RankingRepository model.RankingRepository
+	PostRepository    model.PostRepository
+	Config            model.RankingConfig
+}
+
+// NewRankingService is a factory function for
+// initializing a RankingService with its repository layer dependencies
+func NewRankingService(c *RSConfig) model.RankingService {
+	return &rankingService{
+		RankingRepository: c.RankingRepository,
+		PostRepository:    c.PostRepository,
+		Config:            c.Config,
+	}
+}
+
+// ForYou returns the page of the For You timeline at the cursor and the cursor of the next page.
+// Without a cursor a new session gets ranked, so paging through a session
+// is stable even if scores change in the meantime.
+func (s *rankingService) ForYou(userId string, cursor *model.RankingCursor) (*[]model.Post, *model.RankingCursor, error) {
+	var rankings []model.Ranking
+
+	if cursor == nil {
+		sessionId, err := GenerateId()
+
+		if err != nil {
+			log.Printf("Unable to create session for user: %v\n", userId)
+			return nil, nil, apperrors.NewInternal()
+		}
+
+		rankings, err = s.rank(userId, time.Now())
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := s.RankingRepository.SaveSession(userId, sessionId, rankings); err != nil {
+			return nil, nil, err
+		}
+
+		cursor = &model.RankingCursor{Session: sessionId}
+	} else {
+		var err error
+		rankings, err = s.RankingRepository.FindSession(userId, cursor.Session)
+
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	start := cursor.Offset
+	if start > len(rankings) {
+		start = len(rankings)
+	}
+	end := start + model.LIMIT
+	if end > len(rankings) {
+		end = len(rankings)
+	}
+	page := rankings[start:end]
+
+	ids := make([]string, 0, len(page))
+	for _, ranking := range page {
+		ids = append(ids, ranking.PostID)
+	}
+
+	found, err := s.PostRepository.FindByIDs(ids)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byId := make(map[string]model.Post)
+	for _, post := range *found {
+		byId[post.ID] = post
+	}
+
+	// Posts deleted since the session got ranked are left out
+	posts := make([]model.Post, 0, len(page))
+	for i := range page {
+		if post, ok := byId[page[i].PostID]; ok {
+			post.Ranking = &page[i]
+			posts = append(posts, post)
+		}
+	}
+
+	var next *model.RankingCursor
+	if end < len(rankings) {
+		next = &model.RankingCursor{Session: cursor.Session, Offset: end}
+	}
+
+	return &posts, next, nil
+}
+
+// rank collects the candidates of the user and returns the best model.ForYouSize of them
+func (s *rankingService) rank(userId string, now time.Time) ([]model.Ranking, error) {
+	since := now.Add(-s.Config.Window)
+
+	// Sources are in order of priority, a post found by several keeps the first
+	sources := []func(string, time.Time, int) ([]model.RankingCandidate, error){
+		s.RankingRepository.FindFollowingCandidates,
+		s.RankingRepository.FindNetworkCandidates,
+		s.RankingRepository.FindHashtagCandidates,
+	}
+
+	candidates := make([]model.RankingCandidate, 0)
+	seen := make(map[string]bool)
+	authorIds := make([]string, 0)
+	authors := make(map[string]bool)
+	for _, source := range sources {
+		found, err := source(userId, since, model.ForYouSize)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, candidate := range found {
+			if seen[candidate.PostID] || candidate.AuthorID == userId {
+				continue
+			}
+			seen[candidate.PostID] = true
+			candidates = append(candidates, candidate)
+
+			if !authors[candidate.AuthorID] {
+				authors[candidate.AuthorID] = true
+				authorIds = append(authorIds, candidate.AuthorID)
+			}
+		}
+	}
+
+	affinities, err := s.RankingRepository.FindAffinities(userId, authorIds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rankings := diversify(scoreCandidates(s.Config, now, candidates, affinities), model.LIMIT, s.Config.MaxPerAuthor)
+
+	if len(rankings) > model.ForYouSize {
+		rankings = rankings[:model.ForYouSize]
+	}
+
+	return rankings, nil
+}
+
+// scoreCandidates scores the candidates and sorts them best first.
+// Equal scores are sorted by post ID, so the order is deterministic.
+func scoreCandidates(config model.RankingConfig, now time.Time, candidates []model.RankingCandidate, affinities map[string]int) []model.Ranking {
+	boosts := map[string]float64{
+		model.SourceFollowing: config.FollowingBoost,
+		model.SourceNetwork:   config.NetworkBoost,
+		model.SourceHashtag:   config.HashtagBoost,
+	}
+
+	rankings := make([]model.Ranking, 0, len(candidates))
+	for _, candidate := range candidates {
+		age := math.Max(now.Sub(candidate.CreatedAt).Hours(), 0)
+		engagement := float64(candidate.LikeCount + 2*candidate.RetweetCount)
+
+		ranking := model.Ranking{
+			PostID:   candidate.PostID,
+			AuthorID: candidate.AuthorID,
+			Source:   candidate.Source,
+			Recency:  math.Pow(0.5, age/config.HalfLife.Hours()),
+			Velocity: math.Log1p(engagement / (age + 2)),
+			Affinity: math.Log1p(float64(affinities[candidate.AuthorID])),
+			Boost:    boosts[candidate.Source],
+		}
+		ranking.Score = config.RecencyWeight*ranking.Recency +
+			config.VelocityWeight*ranking.Velocity +
+			config.AffinityWeight*ranking.Affinity +
+			ranking.Boost
+
+		rankings = append(rankings, ranking)
+	}
+
+	sort.SliceStable(rankings, func(i, j int) bool {
+		if rankings[i].Score == rankings[j].Score {
+			return rankings[i].PostID > rankings[j].PostID
+		}
+		return rankings[i].Score > rankings[j].Score
+	})
+
+	return rankings
+}
+
+// diversify fills pages of the given size in score order with at most maxPerAuthor posts per author.
+// Posts over the limit move to the next page that has room for them.
+func diversify(rankings []model.Ranking, pageSize, maxPerAuthor int) []model.Ranking {
+	positions := make(map[string]int)
+	for i, ranking := range rankings {
+		positions[ranking.PostID] = i
+	}
+
+	result := make([]model.Ranking, 0, len(rankings))
+	remaining := rankings
+	for len(remaining) > 0 {
+		counts := make(map[string]int)
+		deferred := make([]model.Ranking, 0)
+		filled := 0
+
+		for _, ranking := range remaining {
+			if filled < pageSize && (maxPerAuthor <= 0 || counts[ranking.AuthorID] < maxPerAuthor) {
+				counts[ranking.AuthorID]++
+				filled++
+				result = append(result, ranking)
+			} else {
+				deferred = append(deferred, ranking)
+			}
+		}
+
+		remaining = deferred
+	}
+
+	for i := range result {
+		result[i].Rank = i + 1
+		if moved := i - positions[result[i].PostID]; moved > 0 {
+			result[i].Demoted = moved
+		}
+	}
+
+	return result
+}
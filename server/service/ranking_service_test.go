@@ -0,0 +1,151 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestRankingService_ForYou(t *testing.T) {
+	authUser := fixture.GetMockUser()
+
+	t.Run("Ranks a new session", func(t *testing.T) {
+		mockRankingRepository := new(mocks.RankingRepository)
+		mockPostRepository := new(mocks.PostRepository)
+		rs := NewRankingService(&RSConfig{
+			RankingRepository: mockRankingRepository,
+			PostRepository:    mockPostRepository,
+			Config:            model.DefaultRankingConfig(),
+		})
+
+		followed, liked, popular := fixture.RandID(), fixture.RandID(), fixture.RandID()
+		recent := model.RankingCandidate{PostID: fixture.RandID(), AuthorID: followed, Source: model.SourceFollowing, CreatedAt: time.Now()}
+		old := model.RankingCandidate{PostID: fixture.RandID(), AuthorID: followed, Source: model.SourceFollowing, CreatedAt: time.Now().Add(-48 * time.Hour)}
+		network := model.RankingCandidate{PostID: fixture.RandID(), AuthorID: liked, Source: model.SourceNetwork, LikeCount: 50, CreatedAt: time.Now().Add(-time.Hour)}
+		own := model.RankingCandidate{PostID: fixture.RandID(), AuthorID: authUser.ID, Source: model.SourceHashtag, CreatedAt: time.Now()}
+		// Also found through a hashtag, but it keeps the following source
+		duplicate := model.RankingCandidate{PostID: recent.PostID, AuthorID: followed, Source: model.SourceHashtag, CreatedAt: recent.CreatedAt}
+		trending := model.RankingCandidate{PostID: fixture.RandID(), AuthorID: popular, Source: model.SourceHashtag, CreatedAt: time.Now().Add(-2 * time.Hour)}
+
+		mockRankingRepository.On("FindFollowingCandidates", authUser.ID, mock.AnythingOfType("time.Time"), model.ForYouSize).
+			Return([]model.RankingCandidate{recent, old}, nil)
+		mockRankingRepository.On("FindNetworkCandidates", authUser.ID, mock.AnythingOfType("time.Time"), model.ForYouSize).
+			Return([]model.RankingCandidate{network}, nil)
+		mockRankingRepository.On("FindHashtagCandidates", authUser.ID, mock.AnythingOfType("time.Time"), model.ForYouSize).
+			Return([]model.RankingCandidate{own, duplicate, trending}, nil)
+		mockRankingRepository.On("FindAffinities", authUser.ID, []string{followed, liked, popular}).
+			Return(map[string]int{liked: 10}, nil)
+
+		var saved []model.Ranking
+		mockRankingRepository.On("SaveSession", authUser.ID, mock.AnythingOfType("string"), mock.Anything).
+			Run(func(args mock.Arguments) {
+				saved = args.Get(2).([]model.Ranking)
+			}).
+			Return(nil)
+
+		posts := []model.Post{{ID: trending.PostID}, {ID: recent.PostID}, {ID: old.PostID}, {ID: network.PostID}}
+		mockPostRepository.On("FindByIDs", mock.Anything).Return(&posts, nil)
+
+		result, next, err := rs.ForYou(authUser.ID, nil)
+
+		assert.NoError(t, err)
+		assert.Nil(t, next)
+
+		ids := make([]string, 0)
+		for _, ranking := range saved {
+			ids = append(ids, ranking.PostID)
+		}
+		assert.Equal(t, []string{network.PostID, recent.PostID, trending.PostID, old.PostID}, ids)
+		assert.Equal(t, model.SourceFollowing, saved[1].Source)
+		assert.Equal(t, 2, saved[1].Rank)
+
+		assert.Len(t, *result, 4)
+		assert.Equal(t, network.PostID, (*result)[0].ID)
+		assert.Equal(t, 1, (*result)[0].Ranking.Rank)
+		mockRankingRepository.AssertExpectations(t)
+	})
+
+	t.Run("Pages through a session", func(t *testing.T) {
+		mockRankingRepository := new(mocks.RankingRepository)
+		mockPostRepository := new(mocks.PostRepository)
+		rs := NewRankingService(&RSConfig{
+			RankingRepository: mockRankingRepository,
+			PostRepository:    mockPostRepository,
+			Config:            model.DefaultRankingConfig(),
+		})
+
+		rankings := make([]model.Ranking, 0)
+		posts := make([]model.Post, 0)
+		for i := 0; i < model.LIMIT+5; i++ {
+			id := fixture.RandID()
+			rankings = append(rankings, model.Ranking{PostID: id, Rank: i + 1})
+			posts = append(posts, model.Post{ID: id})
+		}
+
+		cursor := &model.RankingCursor{Session: fixture.RandID()}
+		mockRankingRepository.On("FindSession", authUser.ID, cursor.Session).Return(rankings, nil)
+		mockPostRepository.On("FindByIDs", mock.Anything).Return(&posts, nil)
+
+		first, next, err := rs.ForYou(authUser.ID, cursor)
+
+		assert.NoError(t, err)
+		assert.Len(t, *first, model.LIMIT)
+		assert.Equal(t, &model.RankingCursor{Session: cursor.Session, Offset: model.LIMIT}, next)
+
+		second, last, err := rs.ForYou(authUser.ID, next)
+
+		assert.NoError(t, err)
+		assert.Len(t, *second, 5)
+		assert.Equal(t, rankings[model.LIMIT].PostID, (*second)[0].ID)
+		assert.Nil(t, last)
+		mockRankingRepository.AssertNotCalled(t, "SaveSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Expired session", func(t *testing.T) {
+		mockRankingRepository := new(mocks.RankingRepository)
+		mockPostRepository := new(mocks.PostRepository)
+		rs := NewRankingService(&RSConfig{
+			RankingRepository: mockRankingRepository,
+			PostRepository:    mockPostRepository,
+			Config:            model.DefaultRankingConfig(),
+		})
+
+		cursor := &model.RankingCursor{Session: fixture.RandID(), Offset: model.LIMIT}
+		mockErr := apperrors.NewNotFound("session", cursor.Session)
+		mockRankingRepository.On("FindSession", authUser.ID, cursor.Session).Return(nil, mockErr)
+
+		posts, next, err := rs.ForYou(authUser.ID, cursor)
+
+		assert.Nil(t, posts)
+		assert.Nil(t, next)
+		assert.Equal(t, mockErr, err)
+		mockPostRepository.AssertNotCalled(t, "FindByIDs", mock.Anything)
+	})
+}
+
+func TestDiversify(t *testing.T) {
+	rankings := []model.Ranking{
+		{PostID: "1", AuthorID: "a"},
+		{PostID: "2", AuthorID: "a"},
+		{PostID: "3", AuthorID: "a"},
+		{PostID: "4", AuthorID: "b"},
+		{PostID: "5", AuthorID: "c"},
+	}
+
+	result := diversify(rankings, 3, 2)
+
+	ids := make([]string, 0)
+	for _, ranking := range result {
+		ids = append(ids, ranking.PostID)
+	}
+
+	assert.Equal(t, []string{"1", "2", "4", "3", "5"}, ids)
+	assert.Equal(t, 4, result[3].Rank)
+	assert.Equal(t, 1, result[3].Demoted)
+	assert.Equal(t, 0, result[2].Demoted)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"fmt"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// searchDateLayout is the layout of since: and until: dates
+const searchDateLayout = "2006-01-02"
+
+// searchToken is a word or quoted phrase of a search and the position of its first character
+type searchToken struct {
+	text     string
+	position int
+}
+
+// ParsePostQuery splits a search into its operators and the text left for the full-text search.
+// Words that look like operators but aren't known ones stay part of the text.
+// Errors are bad requests with the position of the offending token.
+func ParsePostQuery(input string, sort string) (model.PostQuery, error) {
+	query := model.PostQuery{Sort: sort}
+
+	tokens, err := tokenizeSearch(input)
+
+	if err != nil {
+		return query, err
+	}
+
+	text := make([]string, 0)
+	retweetPosition := 0
+	for _, token := range tokens {
+		name, value, negated, ok := splitOperator(token.text)
+
+		if !ok {
+			text = append(text, token.text)
+			continue
+		}
+
+		// The value starts after the name, the colon and the optional minus
+		valuePosition := token.position + len([]rune(name)) + 1
+		if negated {
+			valuePosition++
+		}
+
+		if value == "" {
+			return query, apperrors.NewBadRequestAt(fmt.Sprintf("%s: needs a value", name), valuePosition)
+		}
+
+		if negated && name != "filter" {
+			return query, apperrors.NewBadRequestAt(fmt.Sprintf("%s: can't be negated", name), token.position)
+		}
+
+		switch name {
+		case "from":
+			query.From = strings.TrimPrefix(value, "@")
+		case "to":
+			query.To = strings.TrimPrefix(value, "@")
+		case "since", "until":
+			date, err := time.Parse(searchDateLayout, value)
+			if err != nil {
+				return query, apperrors.NewBadRequestAt(fmt.Sprintf("%s: needs a date like 2024-01-31", name), valuePosition)
+			}
+			if name == "since" {
+				query.Since = &date
+			} else {
+				query.Until = &date
+			}
+		case "has":
+			if value != "media" {
+				return query, apperrors.NewBadRequestAt("has: only supports media", valuePosition)
+			}
+			query.HasMedia = true
+		case "min_likes":
+			likes, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return query, apperrors.NewBadRequestAt("min_likes: needs a number", valuePosition)
+			}
+			query.MinLikes = uint(likes)
+		case "is":
+			if value != "retweet" {
+				return query, apperrors.NewBadRequestAt("is: only supports retweet", valuePosition)
+			}
+			query.IsRetweet = true
+			retweetPosition = token.position
+		case "filter":
+			if !negated || value != "replies" {
+				return query, apperrors.NewBadRequestAt("filter: only supports -filter:replies", token.position)
+			}
+			query.ExcludeReplies = true
+		}
+	}
+
+	// Retweets are only stored per retweeting user
+	if query.IsRetweet && query.From == "" {
+		return query, apperrors.NewBadRequestAt("is:retweet needs from:", retweetPosition)
+	}
+
+	query.Text = strings.Join(text, " ")
+
+	return query, nil
+}
+
+// searchOperators are the operators ParsePostQuery understands
+var searchOperators = map[string]bool{
+	"from":      true,
+	"to":        true,
+	"since":     true,
+	"until":     true,
+	"has":       true,
+	"min_likes": true,
+	"is":        true,
+	"filter":    true,
+}
+
+// splitOperator splits a token like -name:value.
+// It reports false for tokens that aren't a known operator.
+func splitOperator(token string) (string, string, bool, bool) {
+	negated := strings.HasPrefix(token, "-")
+	name, value, found := strings.Cut(strings.TrimPrefix(token, "-"), ":")
+
+	if !found || !searchOperators[strings.ToLower(name)] {
+		return "", "", false, false
+	}
+
+	return strings.ToLower(name), value, negated, true
+}
+
+// tokenizeSearch splits the input at whitespace outside of quotes.
+// Quoted phrases keep their quotes, so the full-text search treats them as phrases.
+func tokenizeSearch(input string) ([]searchToken, error) {
+	tokens := make([]searchToken, 0)
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) {
+			if runes[i] == '"' {
+				quote := i
+				for i++; i < len(runes) && runes[i] != '"'; i++ {
+				}
+				if i == len(runes) {
+					return nil, apperrors.NewBadRequestAt("unterminated quote", quote+1)
+				}
+			}
+			i++
+		}
+
+		tokens = append(tokens, searchToken{text: string(runes[start:i]), position: start + 1})
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParsePostQuery(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Operators and text", func(t *testing.T) {
+		query, err := ParsePostQuery(
+			`golang "go modules" -java from:@alice to:bob since:2024-01-01 until:2024-02-01 has:media min_likes:5 is:retweet -filter:replies`,
+			model.SortRelevance,
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.PostQuery{
+			Text:           `golang "go modules" -java`,
+			From:           "alice",
+			To:             "bob",
+			Since:          &since,
+			Until:          &until,
+			HasMedia:       true,
+			MinLikes:       5,
+			IsRetweet:      true,
+			ExcludeReplies: true,
+			Sort:           model.SortRelevance,
+		}, query)
+	})
+
+	t.Run("Unknown operators stay text", func(t *testing.T) {
+		query, err := ParsePostQuery("https://example.com FROM:alice", model.SortRecent)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com", query.Text)
+		assert.Equal(t, "alice", query.From)
+	})
+
+	t.Run("Quoted phrases keep their spaces", func(t *testing.T) {
+		query, err := ParsePostQuery(`"from:alice is   here"`, model.SortRecent)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `"from:alice is   here"`, query.Text)
+		assert.Empty(t, query.From)
+	})
+
+	errors := []struct {
+		name     string
+		input    string
+		position int
+	}{
+		{name: "Unterminated quote", input: `hello "world`, position: 7},
+		{name: "Missing value", input: "hello from:", position: 12},
+		{name: "Invalid date", input: "since:yesterday", position: 7},
+		{name: "Invalid number", input: "min_likes:-1", position: 11},
+		{name: "Unsupported has", input: "has:links", position: 5},
+		{name: "Unsupported is", input: "is:reply", position: 4},
+		{name: "Filter without negation", input: "filter:replies", position: 1},
+		{name: "Retweets without from", input: "golang is:retweet", position: 8},
+		{name: "Negated operator", input: "a -from:alice", position: 3},
+		{name: "Position counts characters", input: "héllo has:x", position: 11},
+	}
+
+	for _, tc := range errors {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParsePostQuery(tc.input, model.SortRecent)
+
+			e, ok := err.(*apperrors.Error)
+			assert.True(t, ok)
+			assert.Equal(t, apperrors.BadRequest, e.Type)
+			assert.Equal(t, tc.position, *e.Position)
+		})
+	}
+}
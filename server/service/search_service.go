@@ -0,0 +1,136 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/model"
+	"sort"
+	"strings"
+)
+
+type searchService struct {
+	TypeaheadRepository model.TypeaheadRepository
+	UserRepository      model.UserRepository
+}
+
+// SSConfig will hold repositories that will eventually be injected into this
+// this service layer
+type SSConfig struct {
+	TypeaheadRepository model.TypeaheadRepository
+	UserRepository      model.UserRepository
+}
+
+// NewSearchService is a factory function for
+// initializing a SearchService with its repository layer dependencies
+func NewSearchService(c *SSConfig) model.SearchService {
+	return &searchService{
+		TypeaheadRepository: c.TypeaheadRepository,
+		UserRepository:      c.UserRepository,
+	}
+}
+
+// Typeahead suggests users and hashtags starting with the query.
+// A leading @ only suggests users, a leading # only hashtags.
+// Users the viewer follows come first, the other suggestions alternate between users and hashtags.
+func (s *searchService) Typeahead(viewerId, query string) ([]model.TypeaheadSuggestion, error) {
+	prefix := strings.ToLower(strings.TrimSpace(query))
+	withUsers := !strings.HasPrefix(prefix, "#")
+	withHashtags := !strings.HasPrefix(prefix, "@")
+	prefix = strings.TrimLeft(prefix, "@#")
+
+	suggestions := make([]model.TypeaheadSuggestion, 0)
+
+	if prefix == "" {
+		return suggestions, nil
+	}
+
+	users := make([]model.TypeaheadSuggestion, 0)
+	if withUsers {
+		var err error
+		if users, err = s.userSuggestions(viewerId, prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	hashtags := make([]model.TypeaheadSuggestion, 0)
+	if withHashtags {
+		found, err := s.TypeaheadRepository.FindHashtags(prefix, model.TypeaheadLimit)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range found {
+			if strings.HasPrefix(found[i].Tag, prefix) {
+				hashtags = append(hashtags, model.TypeaheadSuggestion{Type: model.SuggestionHashtag, Hashtag: &found[i]})
+			}
+		}
+	}
+
+	for len(users) > 0 && users[0].User.Following {
+		suggestions = append(suggestions, users[0])
+		users = users[1:]
+	}
+
+	for len(users) > 0 || len(hashtags) > 0 {
+		if len(users) > 0 {
+			suggestions = append(suggestions, users[0])
+			users = users[1:]
+		}
+		if len(hashtags) > 0 {
+			suggestions = append(suggestions, hashtags[0])
+			hashtags = hashtags[1:]
+		}
+	}
+
+	if len(suggestions) > model.TypeaheadLimit {
+		suggestions = suggestions[:model.TypeaheadLimit]
+	}
+
+	return suggestions, nil
+}
+
+// userSuggestions returns the users whose username starts with the prefix,
+// the ones the viewer follows first and by followers after that
+func (s *searchService) userSuggestions(viewerId, prefix string) ([]model.TypeaheadSuggestion, error) {
+	ids, err := s.TypeaheadRepository.FindUserIDs(prefix, model.TypeaheadCandidates)
+
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]model.TypeaheadSuggestion, 0)
+
+	if len(ids) == 0 {
+		return suggestions, nil
+	}
+
+	found, err := s.UserRepository.FindByIDs(ids)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Renamed users stay indexed under their old username if unindexing it failed
+	users := make([]*model.User, 0, len(*found))
+	for i := range *found {
+		if strings.HasPrefix(strings.ToLower((*found)[i].Username), prefix) {
+			users = append(users, &(*found)[i])
+		}
+	}
+
+	if err := s.UserRepository.LoadViewerState(viewerId, users); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		if users[i].Following != users[j].Following {
+			return users[i].Following
+		}
+		return users[i].FollowerCount > users[j].FollowerCount
+	})
+
+	for _, user := range users {
+		suggestions = append(suggestions, model.TypeaheadSuggestion{Type: model.SuggestionUser, User: user})
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/sentrionic/mirage/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestSearchService_Typeahead(t *testing.T) {
+	viewer := fixture.GetMockUser()
+
+	t.Run("Mixes users and hashtags", func(t *testing.T) {
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
+		mockUserRepository := new(mocks.UserRepository)
+		ss := NewSearchService(&SSConfig{
+			TypeaheadRepository: mockTypeaheadRepository,
+			UserRepository:      mockUserRepository,
+		})
+
+		popular := model.User{ID: fixture.RandID(), Username: "GoFans", FollowerCount: 100}
+		followed := model.User{ID: fixture.RandID(), Username: "gopher", FollowerCount: 5}
+		// Renamed after getting indexed as go...
+		renamed := model.User{ID: fixture.RandID(), Username: "alice", FollowerCount: 50}
+		ids := []string{popular.ID, renamed.ID, followed.ID}
+		hashtags := []model.HashtagCount{{Tag: "golang", Posts: 12}, {Tag: "gophers", Posts: 3}}
+
+		mockTypeaheadRepository.On("FindUserIDs", "go", model.TypeaheadCandidates).Return(ids, nil)
+		mockTypeaheadRepository.On("FindHashtags", "go", model.TypeaheadLimit).Return(hashtags, nil)
+		mockUserRepository.On("FindByIDs", ids).Return(&[]model.User{popular, renamed, followed}, nil)
+		mockUserRepository.On("LoadViewerState", viewer.ID, mock.AnythingOfType("[]*model.User")).
+			Run(func(args mock.Arguments) {
+				for _, user := range args.Get(1).([]*model.User) {
+					user.Following = user.ID == followed.ID
+				}
+			}).
+			Return(nil)
+
+		suggestions, err := ss.Typeahead(viewer.ID, " Go")
+
+		assert.NoError(t, err)
+		assert.Len(t, suggestions, 4)
+		assert.Equal(t, followed.ID, suggestions[0].User.ID)
+		assert.Equal(t, popular.ID, suggestions[1].User.ID)
+		assert.Equal(t, model.SuggestionHashtag, suggestions[2].Type)
+		assert.Equal(t, "golang", suggestions[2].Hashtag.Tag)
+		assert.Equal(t, "gophers", suggestions[3].Hashtag.Tag)
+		mockTypeaheadRepository.AssertExpectations(t)
+	})
+
+	t.Run("Only users after an @", func(t *testing.T) {
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
+		mockUserRepository := new(mocks.UserRepository)
+		ss := NewSearchService(&SSConfig{
+			TypeaheadRepository: mockTypeaheadRepository,
+			UserRepository:      mockUserRepository,
+		})
+
+		mockTypeaheadRepository.On("FindUserIDs", "al", model.TypeaheadCandidates).Return([]string{}, nil)
+
+		suggestions, err := ss.Typeahead(viewer.ID, "@al")
+
+		assert.NoError(t, err)
+		assert.Empty(t, suggestions)
+		mockTypeaheadRepository.AssertNotCalled(t, "FindHashtags", mock.Anything, mock.Anything)
+		mockUserRepository.AssertNotCalled(t, "FindByIDs", mock.Anything)
+	})
+
+	t.Run("Only hashtags after a #", func(t *testing.T) {
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
+		ss := NewSearchService(&SSConfig{
+			TypeaheadRepository: mockTypeaheadRepository,
+		})
+
+		hashtags := []model.HashtagCount{{Tag: "golang", Posts: 12}}
+		mockTypeaheadRepository.On("FindHashtags", "gol", model.TypeaheadLimit).Return(hashtags, nil)
+
+		suggestions, err := ss.Typeahead(viewer.ID, "#Gol")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.TypeaheadSuggestion{{Type: model.SuggestionHashtag, Hashtag: &hashtags[0]}}, suggestions)
+		mockTypeaheadRepository.AssertNotCalled(t, "FindUserIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Empty query", func(t *testing.T) {
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
+		ss := NewSearchService(&SSConfig{
+			TypeaheadRepository: mockTypeaheadRepository,
+		})
+
+		suggestions, err := ss.Typeahead(viewer.ID, "@")
+
+		assert.NoError(t, err)
+		assert.Empty(t, suggestions)
+		mockTypeaheadRepository.AssertNotCalled(t, "FindUserIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
+		ss := NewSearchService(&SSConfig{
+			TypeaheadRepository: mockTypeaheadRepository,
+		})
+
+		mockErr := apperrors.NewInternal()
+		mockTypeaheadRepository.On("FindHashtags", "go", model.TypeaheadLimit).Return(nil, mockErr)
+
+		suggestions, err := ss.Typeahead(viewer.ID, "#go")
+
+		assert.Nil(t, suggestions)
+		assert.Equal(t, mockErr, err)
+	})
+}
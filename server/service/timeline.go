@@ -0,0 +1,98 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/model"
+	"sort"
+	"time"
+)
+
+// timelineRecipients returns the users whose timelines get the posts and retweets of the author.
+// Celebrities only write to their own timeline, their followers merge them in on read.
+func timelineRecipients(timelineRepository model.TimelineRepository, authorId string) ([]string, error) {
+	followers, celebrity, err := timelineRepository.FindFollowerIDs(authorId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if celebrity {
+		return []string{authorId}, nil
+	}
+
+	return append(followers, authorId), nil
+}
+
+// fanOut writes the entry to the timelines of the author and their followers
+func fanOut(timelineRepository model.TimelineRepository, authorId string, entry model.TimelineEntry) error {
+	recipients, err := timelineRecipients(timelineRepository, authorId)
+
+	if err != nil {
+		return err
+	}
+
+	return timelineRepository.Add(recipients, entry)
+}
+
+// rebuildTimeline fills the timeline of the user from the database with their own posts
+// and the posts of everyone they follow except celebrities
+func rebuildTimeline(timelineRepository model.TimelineRepository, userId string) ([]model.TimelineEntry, error) {
+	followees, _, err := timelineRepository.FindFolloweeIDs(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := timelineRepository.FindEntries(append(followees, userId), model.Page{}, model.TimelineSize, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// A timeline that didn't fill up holds every entry there is
+	return entries, timelineRepository.Replace(userId, entries, len(entries) < model.TimelineSize)
+}
+
+// timelinePage truncates the cursors of the page to milliseconds,
+// the precision timeline entries are stored with
+func timelinePage(page model.Page) model.Page {
+	truncate := func(cursor *model.Cursor) *model.Cursor {
+		if cursor == nil {
+			return nil
+		}
+		return &model.Cursor{Time: cursor.Time.Truncate(time.Millisecond), ID: cursor.ID}
+	}
+
+	return model.Page{Before: truncate(page.Before), After: truncate(page.After)}
+}
+
+// mergeEntries combines the timelines newest first and keeps each post once at its newest position.
+// It keeps the limit entries next to the cursor of the page.
+func mergeEntries(page model.Page, limit int, timelines ...[]model.TimelineEntry) []model.TimelineEntry {
+	merged := make([]model.TimelineEntry, 0)
+	for _, entries := range timelines {
+		merged = append(merged, entries...)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Cursor().NewerThan(merged[j].Cursor())
+	})
+
+	entries := make([]model.TimelineEntry, 0, len(merged))
+	seen := make(map[string]bool)
+	for _, entry := range merged {
+		if !seen[entry.PostID] {
+			seen[entry.PostID] = true
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) <= limit {
+		return entries
+	}
+
+	if page.After != nil {
+		return entries[len(entries)-limit:]
+	}
+
+	return entries[:limit]
+}
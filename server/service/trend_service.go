@@ -0,0 +1,105 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/model"
+	"math"
+	"sort"
+	"time"
+)
+
+type trendService struct {
+	TrendRepository model.TrendRepository
+}
+
+// TSConfig will hold repositories that will eventually be injected into this
+// this service layer
+type TSConfig struct {
+	TrendRepository model.TrendRepository
+}
+
+// NewTrendService is a factory function for
+// initializing a TrendService with its repository layer dependencies
+func NewTrendService(c *TSConfig) model.TrendService {
+	return &trendService{
+		TrendRepository: c.TrendRepository,
+	}
+}
+
+// GetTrends returns the hashtags whose use within the trend window
+// is furthest above their use in the baseline buckets before it
+func (s *trendService) GetTrends() ([]model.Trend, error) {
+	buckets, err := s.TrendRepository.FindBuckets(time.Now(), model.TrendWindowBuckets+model.TrendBaselineBuckets)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scoreTrends(buckets), nil
+}
+
+// scoreTrends compares the decayed number of authors using each hashtag in the window
+// with the number expected from its baseline rate.
+// The difference is scaled down for hashtags that are commonly used anyway.
+func scoreTrends(buckets []model.HashtagBucket) []model.Trend {
+	window := buckets
+	baseline := make([]model.HashtagBucket, 0)
+	if len(buckets) > model.TrendWindowBuckets {
+		window = buckets[:model.TrendWindowBuckets]
+		baseline = buckets[model.TrendWindowBuckets:]
+	}
+
+	current := make(map[string]float64)
+	trends := make(map[string]*model.Trend)
+	totalWeight := 0.0
+	for i, bucket := range window {
+		weight := math.Pow(model.TrendDecay, float64(i))
+		totalWeight += weight
+
+		for tag, authors := range bucket.Authors {
+			current[tag] += weight * float64(authors)
+		}
+
+		for tag, posts := range bucket.Posts {
+			if trends[tag] == nil {
+				trends[tag] = &model.Trend{Hashtag: tag}
+			}
+			trends[tag].Posts += posts
+			trends[tag].Authors += bucket.Authors[tag]
+		}
+	}
+
+	result := make([]model.Trend, 0)
+	for tag, trend := range trends {
+		if trend.Authors < model.TrendMinAuthors {
+			continue
+		}
+
+		usual := 0.0
+		for _, bucket := range baseline {
+			usual += float64(bucket.Authors[tag])
+		}
+		if len(baseline) > 0 {
+			usual /= float64(len(baseline))
+		}
+
+		expected := usual * totalWeight
+		trend.Score = (current[tag] - expected) / math.Sqrt(expected+1)
+
+		if trend.Score > 0 {
+			result = append(result, *trend)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score == result[j].Score {
+			return result[i].Hashtag < result[j].Hashtag
+		}
+		return result[i].Score > result[j].Score
+	})
+
+	if len(result) > model.TrendLimit {
+		result = result[:model.TrendLimit]
+	}
+
+	return result
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"github.com/sentrionic/mirage/mocks"
+	"github.com/sentrionic/mirage/model"
+	"github.com/sentrionic/mirage/model/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestTrendService_GetTrends(t *testing.T) {
+	count := model.TrendWindowBuckets + model.TrendBaselineBuckets
+
+	t.Run("Ranks hashtags by how far they exceed their baseline", func(t *testing.T) {
+		mockTrendRepository := new(mocks.TrendRepository)
+		ts := NewTrendService(&TSConfig{
+			TrendRepository: mockTrendRepository,
+		})
+
+		buckets := make([]model.HashtagBucket, 0)
+		for i := 0; i < count; i++ {
+			buckets = append(buckets, model.HashtagBucket{
+				Authors: map[string]uint{"weather": 5},
+				Posts:   map[string]uint{"weather": 5},
+			})
+		}
+		// A breaking topic, one used by the same few authors over and over,
+		// a smaller rising one and one that is used as much as always
+		buckets[0].Authors["breaking"], buckets[0].Posts["breaking"] = 20, 25
+		buckets[1].Authors["breaking"], buckets[1].Posts["breaking"] = 4, 4
+		buckets[0].Authors["spam"], buckets[0].Posts["spam"] = 2, 200
+		buckets[0].Authors["rising"], buckets[0].Posts["rising"] = 4, 4
+
+		mockTrendRepository.On("FindBuckets", mock.AnythingOfType("time.Time"), count).Return(buckets, nil)
+
+		trends, err := ts.GetTrends()
+
+		assert.NoError(t, err)
+		assert.Len(t, trends, 2)
+		assert.Equal(t, "breaking", trends[0].Hashtag)
+		assert.Equal(t, uint(29), trends[0].Posts)
+		assert.Equal(t, "rising", trends[1].Hashtag)
+		mockTrendRepository.AssertExpectations(t)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockTrendRepository := new(mocks.TrendRepository)
+		ts := NewTrendService(&TSConfig{
+			TrendRepository: mockTrendRepository,
+		})
+
+		mockErr := apperrors.NewInternal()
+		mockTrendRepository.On("FindBuckets", mock.AnythingOfType("time.Time"), count).Return(nil, mockErr)
+
+		trends, err := ts.GetTrends()
+
+		assert.Nil(t, trends)
+		assert.Equal(t, mockErr, err)
+	})
+}
@@ -5,26 +5,37 @@ import (
 	"github.com/sentrionic/mirage/model/apperrors"
 	"log"
 	"mime/multipart"
+	"path"
+	"strings"
 )
 
 type userService struct {
-	UserRepository model.UserRepository
-	FileRepository model.FileRepository
+	UserRepository      model.UserRepository
+	FileRepository      model.FileRepository
+	MediaRepository     model.MediaRepository
+	TimelineRepository  model.TimelineRepository
+	TypeaheadRepository model.TypeaheadRepository
 }
 
 // USConfig will hold repositories that will eventually be injected into this
 // this service layer
 type USConfig struct {
-	UserRepository model.UserRepository
-	FileRepository model.FileRepository
+	UserRepository      model.UserRepository
+	FileRepository      model.FileRepository
+	MediaRepository     model.MediaRepository
+	TimelineRepository  model.TimelineRepository
+	TypeaheadRepository model.TypeaheadRepository
 }
 
 // NewUserService is a factory function for
 // initializing a UserService with its repository layer dependencies
 func NewUserService(c *USConfig) model.UserService {
 	return &userService{
-		UserRepository: c.UserRepository,
-		FileRepository: c.FileRepository,
+		UserRepository:      c.UserRepository,
+		FileRepository:      c.FileRepository,
+		MediaRepository:     c.MediaRepository,
+		TimelineRepository:  c.TimelineRepository,
+		TypeaheadRepository: c.TypeaheadRepository,
 	}
 }
 
@@ -55,7 +66,18 @@ func (s *userService) Register(user *model.User) (*model.User, error) {
 
 	user.Image = GetGravatar(user.Email)
 
-	return s.UserRepository.Create(user)
+	created, err := s.UserRepository.Create(user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The user only misses out on suggestions, so this must not fail the request
+	if err := s.TypeaheadRepository.AddUser(created); err != nil {
+		log.Printf("Unable to index user: %v\n%v", created.ID, err)
+	}
+
+	return created, nil
 }
 
 func (s *userService) Login(email, password string) (*model.User, error) {
@@ -81,19 +103,101 @@ func (s *userService) Login(email, password string) (*model.User, error) {
 }
 
 func (s *userService) Update(user *model.User) error {
-	return s.UserRepository.Update(user)
+	// The stored username is needed to unindex it when it changes
+	stored, err := s.UserRepository.FindByID(user.ID)
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.UserRepository.Update(user); err != nil {
+		return err
+	}
+
+	if stored.Username == user.Username {
+		return nil
+	}
+
+	if err := s.TypeaheadRepository.RenameUser(user, stored.Username); err != nil {
+		log.Printf("Unable to reindex user: %v\n%v", user.ID, err)
+	}
+
+	return nil
 }
 
-func (s *userService) ChangeAvatar(header *multipart.FileHeader, directory string) (string, error) {
-	return s.FileRepository.UploadAvatar(header, directory)
+// ChangeAvatar sets the uploaded image as the user's avatar.
+// The previous avatar stays in use until it gets released with ReleaseMedia.
+func (s *userService) ChangeAvatar(user *model.User, header *multipart.FileHeader) error {
+	media, err := s.acquireMedia(header, "profile_images", s.FileRepository.UploadAvatar)
+
+	if err != nil {
+		return err
+	}
+
+	user.Image = media.Url
+	user.ImagePlaceholder = media.Placeholder
+	user.ImageMediaID = &media.ID
+
+	return nil
 }
 
-func (s *userService) ChangeBanner(header *multipart.FileHeader, directory string) (string, error) {
-	return s.FileRepository.UploadBanner(header, directory)
+// ChangeBanner sets the uploaded image as the user's banner.
+// The previous banner stays in use until it gets released with ReleaseMedia.
+func (s *userService) ChangeBanner(user *model.User, header *multipart.FileHeader) error {
+	media, err := s.acquireMedia(header, "header_photo", s.FileRepository.UploadBanner)
+
+	if err != nil {
+		return err
+	}
+
+	user.Banner = &media.Url
+	user.BannerPlaceholder = media.Placeholder
+	user.BannerMediaID = &media.ID
+
+	return nil
 }
 
-func (s *userService) DeleteImage(key string) error {
-	return s.FileRepository.DeleteImage(key)
+func (s *userService) ReleaseMedia(id string) error {
+	return releaseMedia(s.MediaRepository, s.FileRepository, id)
+}
+
+type profileUpload func(header *multipart.FileHeader, directory, slug string) (model.FileVariant, model.Placeholder, error)
+
+// acquireMedia returns the media for the uploaded image with a new reference.
+// The image only gets uploaded if its content hasn't been stored before.
+func (s *userService) acquireMedia(header *multipart.FileHeader, directory string, upload profileUpload) (*model.Media, error) {
+	hash, err := hashFile(header)
+
+	if err != nil {
+		return nil, err
+	}
+
+	id := path.Join(directory, hash)
+	media, err := s.MediaRepository.Acquire(id)
+
+	if err != nil || media != nil {
+		return media, err
+	}
+
+	variant, placeholder, err := upload(header, directory, hash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	media = &model.Media{
+		ID:          id,
+		Hash:        hash,
+		Url:         variant.Url,
+		Keys:        []string{variant.Key},
+		Placeholder: placeholder,
+	}
+
+	if err := s.MediaRepository.Create(media); err != nil {
+		return nil, err
+	}
+
+	return media, nil
 }
 
 func (s *userService) FindByUsername(username string) (*model.User, error) {
@@ -101,13 +205,77 @@ func (s *userService) FindByUsername(username string) (*model.User, error) {
 }
 
 func (s *userService) ChangeFollow(user *model.User, current string) error {
-	if user.IsFollowing(current) {
-		return s.UserRepository.RemoveFollow(user.ID, current)
-	} else {
-		return s.UserRepository.AddFollow(user.ID, current)
+	if err := s.UserRepository.LoadViewerState(current, []*model.User{user}); err != nil {
+		return err
+	}
+
+	if user.Following {
+		if err := s.UserRepository.RemoveFollow(user.ID, current); err != nil {
+			return err
+		}
+
+		s.countFollower(user, -1)
+
+		// Posts of the user that people who are still followed retweeted stay in the timeline
+		followees, _, err := s.TimelineRepository.FindFolloweeIDs(current)
+
+		if err == nil {
+			err = s.TimelineRepository.RemoveAuthor(current, user.ID, append(followees, current))
+		}
+
+		if err != nil {
+			log.Printf("Unable to remove user from timeline of user: %v\n%v", current, err)
+		}
+
+		return nil
+	}
+
+	if err := s.UserRepository.AddFollow(user.ID, current); err != nil {
+		return err
 	}
+
+	s.countFollower(user, 1)
+
+	// Posts of celebrities get merged in on read
+	if user.FollowerCount >= model.CelebrityFollowers {
+		return nil
+	}
+
+	entries, err := s.TimelineRepository.FindEntries([]string{user.ID}, model.Page{}, model.TimelineSize, nil)
+
+	if err == nil {
+		err = s.TimelineRepository.Add([]string{current}, entries...)
+	}
+
+	if err != nil {
+		log.Printf("Unable to backfill timeline for user: %v\n%v", current, err)
+	}
+
+	return nil
+}
+
+// countFollower updates the typeahead score of the user after they gained or lost a follower
+func (s *userService) countFollower(user *model.User, delta int) {
+	if err := s.TypeaheadRepository.AddFollowers(user, delta); err != nil {
+		log.Printf("Unable to update typeahead score of user: %v\n%v", user.ID, err)
+	}
+}
+
+// Search finds the users matching the term, ranked for the viewer.
+// The cursor is the last user of the previous page.
+func (s *userService) Search(term, viewerId string, cursor *model.ProfileCursor) (*[]model.User, error) {
+	term = strings.TrimSpace(term)
+
+	if term == "" {
+		users := make([]model.User, 0)
+		return &users, nil
+	}
+
+	return s.UserRepository.SearchProfiles(term, viewerId, cursor)
 }
 
-func (s *userService) Search(term string) (*[]model.User, error) {
-	return s.UserRepository.SearchProfiles(term)
+// LoadViewerState sets if the viewer follows the users.
+// Nothing gets loaded for anonymous viewers.
+func (s *userService) LoadViewerState(viewerId string, users []*model.User) error {
+	return s.UserRepository.LoadViewerState(viewerId, users)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/sentrionic/mirage/model/fixture"
 	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -97,8 +98,10 @@ func TestRegister(t *testing.T) {
 		}
 
 		mockUserRepository := new(mocks.UserRepository)
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
 		us := NewUserService(&USConfig{
-			UserRepository: mockUserRepository,
+			UserRepository:      mockUserRepository,
+			TypeaheadRepository: mockTypeaheadRepository,
 		})
 
 		// We can use Run method to modify the user when the Create method is called.
@@ -108,6 +111,7 @@ func TestRegister(t *testing.T) {
 			Run(func(args mock.Arguments) {
 				mockUser.ID = uid
 			}).Return(mockUser, nil)
+		mockTypeaheadRepository.On("AddUser", mockUser).Return(nil)
 
 		user, err := us.Register(initial)
 
@@ -118,6 +122,27 @@ func TestRegister(t *testing.T) {
 		assert.Equal(t, user, mockUser)
 
 		mockUserRepository.AssertExpectations(t)
+		mockTypeaheadRepository.AssertExpectations(t)
+	})
+
+	t.Run("Indexing error", func(t *testing.T) {
+		mockUser := fixture.GetMockUser()
+
+		mockUserRepository := new(mocks.UserRepository)
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
+		us := NewUserService(&USConfig{
+			UserRepository:      mockUserRepository,
+			TypeaheadRepository: mockTypeaheadRepository,
+		})
+
+		mockUserRepository.On("Create", mock.AnythingOfType("*model.User")).Return(mockUser, nil)
+		mockTypeaheadRepository.On("AddUser", mockUser).Return(apperrors.NewInternal())
+
+		user, err := us.Register(&model.User{Username: mockUser.Username, Email: mockUser.Email, Password: mockUser.Password})
+
+		assert.NoError(t, err)
+		assert.Equal(t, mockUser, user)
+		mockTypeaheadRepository.AssertExpectations(t)
 	})
 
 	t.Run("Error", func(t *testing.T) {
@@ -207,8 +232,10 @@ func TestLogin(t *testing.T) {
 
 func TestUpdateDetails(t *testing.T) {
 	mockUserRepository := new(mocks.UserRepository)
+	mockTypeaheadRepository := new(mocks.TypeaheadRepository)
 	us := NewUserService(&USConfig{
-		UserRepository: mockUserRepository,
+		UserRepository:      mockUserRepository,
+		TypeaheadRepository: mockTypeaheadRepository,
 	})
 
 	t.Run("Success", func(t *testing.T) {
@@ -217,17 +244,47 @@ func TestUpdateDetails(t *testing.T) {
 		mockUser := fixture.GetMockUser()
 		mockUser.ID = uid
 
+		stored := *mockUser
+		stored.Username = "previous"
+
 		mockArgs := mock.Arguments{
 			mockUser,
 		}
 
+		mockUserRepository.On("FindByID", uid).Return(&stored, nil)
 		mockUserRepository.
 			On("Update", mockArgs...).Return(nil)
+		mockTypeaheadRepository.On("RenameUser", mockUser, "previous").Return(nil)
 
 		err := us.Update(mockUser)
 
 		assert.NoError(t, err)
 		mockUserRepository.AssertCalled(t, "Update", mockArgs...)
+		mockTypeaheadRepository.AssertCalled(t, "RenameUser", mockUser, "previous")
+	})
+
+	t.Run("Unchanged username stays indexed", func(t *testing.T) {
+		mockUserRepository := new(mocks.UserRepository)
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
+		us := NewUserService(&USConfig{
+			UserRepository:      mockUserRepository,
+			TypeaheadRepository: mockTypeaheadRepository,
+		})
+
+		uid, _ := GenerateId()
+
+		mockUser := fixture.GetMockUser()
+		mockUser.ID = uid
+		stored := *mockUser
+
+		mockUserRepository.On("FindByID", uid).Return(&stored, nil)
+		mockUserRepository.On("Update", mockUser).Return(nil)
+
+		err := us.Update(mockUser)
+
+		assert.NoError(t, err)
+		mockUserRepository.AssertCalled(t, "Update", mockUser)
+		mockTypeaheadRepository.AssertNotCalled(t, "RenameUser", mockUser, mock.Anything)
 	})
 
 	t.Run("Failure", func(t *testing.T) {
@@ -243,6 +300,7 @@ func TestUpdateDetails(t *testing.T) {
 
 		mockError := apperrors.NewInternal()
 
+		mockUserRepository.On("FindByID", uid).Return(&model.User{ID: uid, Username: "previous"}, nil)
 		mockUserRepository.
 			On("Update", mockArgs...).Return(mockError)
 
@@ -254,235 +312,62 @@ func TestUpdateDetails(t *testing.T) {
 		assert.Equal(t, apperrors.Internal, apperror.Type)
 
 		mockUserRepository.AssertCalled(t, "Update", mockArgs...)
+		mockTypeaheadRepository.AssertNotCalled(t, "RenameUser", mockUser, mock.Anything)
 	})
 }
 
-func TestUserService_ChangeAvatar(t *testing.T) {
-	mockUserRepository := new(mocks.UserRepository)
-	mockFileRepository := new(mocks.FileRepository)
-
-	us := NewUserService(&USConfig{
-		UserRepository: mockUserRepository,
-		FileRepository: mockFileRepository,
-	})
-
-	t.Run("Successful new image", func(t *testing.T) {
+func TestUserService_ChangeFollow(t *testing.T) {
+	t.Run("Success change to following", func(t *testing.T) {
 		uid, _ := GenerateId()
-
-		// does not have have imageURL
 		mockUser := fixture.GetMockUser()
-		mockUser.ID = uid
-		mockUser.Image = ""
-
-		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
-		defer multipartImageFixture.Close()
-		imageFileHeader := multipartImageFixture.GetFormFile()
-		directory := "test_dir"
-
-		uploadFileArgs := mock.Arguments{
-			imageFileHeader,
-			directory,
-		}
-
-		imageURL := "https://imageurl.com/jdfkj34kljl"
-
-		mockFileRepository.
-			On("UploadAvatar", uploadFileArgs...).
-			Return(imageURL, nil)
-
-		updateArgs := mock.Arguments{
-			mockUser,
-		}
-
-		mockUpdatedUser := &model.User{
-			ID:          uid,
-			Email:       mockUser.Email,
-			Bio:         mockUser.Bio,
-			Username:    mockUser.Username,
-			DisplayName: mockUser.DisplayName,
-			Image:       imageURL,
-			Password:    mockUser.Password,
-			CreatedAt:   mockUser.CreatedAt,
-			UpdatedAt:   mockUser.UpdatedAt,
-		}
-
-		mockUserRepository.
-			On("Update", updateArgs...).
-			Return(nil)
-
-		url, err := us.ChangeAvatar(imageFileHeader, directory)
-		assert.NoError(t, err)
-		mockUser.Image = url
-
-		err = us.Update(mockUser)
-
-		assert.NoError(t, err)
-		assert.Equal(t, mockUpdatedUser, mockUser)
-		mockFileRepository.AssertCalled(t, "UploadAvatar", uploadFileArgs...)
-		mockUserRepository.AssertCalled(t, "Update", updateArgs...)
-	})
-
-	t.Run("Successful update image", func(t *testing.T) {
-		imageURL := "https://imageurl.com/jdfkj34kljl"
-		uid, _ := GenerateId()
-
-		mockUser := &model.User{
-			Email:    "new@bob.com",
-			Username: "NewRobert",
-			Image:    imageURL,
-		}
-		mockUser.ID = uid
-
-		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
-		defer multipartImageFixture.Close()
-		imageFileHeader := multipartImageFixture.GetFormFile()
-		directory := "test_dir"
-
-		uploadFileArgs := mock.Arguments{
-			imageFileHeader,
-			directory,
-		}
-
-		deleteImageArgs := mock.Arguments{
-			imageURL,
-		}
-
-		mockFileRepository.
-			On("UploadAvatar", uploadFileArgs...).
-			Return(imageURL, nil)
-
-		mockFileRepository.
-			On("DeleteImage", deleteImageArgs...).
-			Return(nil)
-
-		mockUpdatedUser := &model.User{
-			Email:    "new@bob.com",
-			Username: "NewRobert",
-			Image:    imageURL,
-		}
-		mockUpdatedUser.ID = uid
-
-		updateArgs := mock.Arguments{
-			mockUser,
-		}
-
-		mockUserRepository.
-			On("Update", updateArgs...).
-			Return(nil)
-
-		url, err := us.ChangeAvatar(imageFileHeader, directory)
-		assert.NoError(t, err)
-		err = us.DeleteImage(mockUser.Image)
-		assert.NoError(t, err)
-
-		mockUser.Image = url
-		err = us.Update(mockUser)
-		assert.NoError(t, err)
-
-		assert.Equal(t, mockUpdatedUser, mockUser)
-		mockFileRepository.AssertCalled(t, "UploadAvatar", uploadFileArgs...)
-		mockFileRepository.AssertCalled(t, "DeleteImage", imageURL)
-		mockUserRepository.AssertCalled(t, "Update", updateArgs...)
-	})
 
-	t.Run("FileRepository Error", func(t *testing.T) {
-		// need to create a new UserService and repository
-		// because testify has no way to overwrite a mock's
-		// "On" call.
 		mockUserRepository := new(mocks.UserRepository)
-		mockFileRepository := new(mocks.FileRepository)
-
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
 		us := NewUserService(&USConfig{
-			UserRepository: mockUserRepository,
-			FileRepository: mockFileRepository,
+			UserRepository:      mockUserRepository,
+			TimelineRepository:  mockTimelineRepository,
+			TypeaheadRepository: mockTypeaheadRepository,
 		})
 
-		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
-		defer multipartImageFixture.Close()
-		imageFileHeader := multipartImageFixture.GetFormFile()
-		directory := "file_directory"
-
-		uploadFileArgs := mock.Arguments{
-			imageFileHeader,
-			directory,
-		}
-
-		mockError := apperrors.NewInternal()
-		mockFileRepository.
-			On("UploadAvatar", uploadFileArgs...).
-			Return("", mockError)
-
-		url, err := us.ChangeAvatar(imageFileHeader, directory)
-		assert.Equal(t, "", url)
-		assert.Error(t, err)
+		entries := []model.TimelineEntry{{PostID: "post", CreatedAt: time.Now()}}
 
-		mockFileRepository.AssertCalled(t, "UploadAvatar", uploadFileArgs...)
-		mockUserRepository.AssertNotCalled(t, "Update")
-	})
-
-	t.Run("UserRepository UpdateImage Error", func(t *testing.T) {
-		uid, _ := GenerateId()
-		imageURL := "https://imageurl.com/jdfkj34kljl"
-
-		// has imageURL
-		mockUser := &model.User{
-			Email:    "new@bob.com",
-			Username: "A New Bob!",
-			Image:    imageURL,
-		}
-		mockUser.ID = uid
-
-		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
-		defer multipartImageFixture.Close()
-		imageFileHeader := multipartImageFixture.GetFormFile()
-		directory := "file_dir"
-
-		uploadFileArgs := mock.Arguments{
-			imageFileHeader,
-			directory,
-		}
-
-		mockFileRepository.
-			On("UploadAvatar", uploadFileArgs...).
-			Return(imageURL, nil)
-
-		updateArgs := mock.Arguments{
-			mockUser,
-		}
+		mockUserRepository.On("LoadViewerState", uid, []*model.User{mockUser}).Return(nil)
+		mockUserRepository.On("AddFollow", mockUser.ID, uid).Return(nil)
+		mockTypeaheadRepository.On("AddFollowers", mockUser, 1).Return(nil)
+		mockTimelineRepository.On("FindEntries", []string{mockUser.ID}, model.Page{}, model.TimelineSize, ([]string)(nil)).Return(entries, nil)
+		mockTimelineRepository.On("Add", []string{uid}, entries[0]).Return(nil)
 
-		mockError := apperrors.NewInternal()
-		mockUserRepository.
-			On("Update", updateArgs...).
-			Return(mockError)
+		err := us.ChangeFollow(mockUser, uid)
 
-		url, err := us.ChangeAvatar(imageFileHeader, directory)
 		assert.NoError(t, err)
-		assert.Equal(t, imageURL, url)
-
-		err = us.Update(mockUser)
-
-		assert.Error(t, err)
-		mockFileRepository.AssertCalled(t, "UploadAvatar", uploadFileArgs...)
-		mockUserRepository.AssertCalled(t, "Update", updateArgs...)
+		mockUserRepository.AssertExpectations(t)
+		mockTimelineRepository.AssertExpectations(t)
+		mockTypeaheadRepository.AssertExpectations(t)
+		mockUserRepository.AssertNotCalled(t, "RemoveFollow", mockUser, uid)
 	})
-}
 
-func TestUserService_ChangeFollow(t *testing.T) {
-	t.Run("Success change to following", func(t *testing.T) {
+	t.Run("Following a celebrity skips the backfill", func(t *testing.T) {
 		uid, _ := GenerateId()
 		mockUser := fixture.GetMockUser()
+		mockUser.FollowerCount = model.CelebrityFollowers
 
 		mockUserRepository := new(mocks.UserRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
 		us := NewUserService(&USConfig{
-			UserRepository: mockUserRepository,
+			UserRepository:      mockUserRepository,
+			TimelineRepository:  mockTimelineRepository,
+			TypeaheadRepository: mockTypeaheadRepository,
 		})
+		mockUserRepository.On("LoadViewerState", uid, []*model.User{mockUser}).Return(nil)
 		mockUserRepository.On("AddFollow", mockUser.ID, uid).Return(nil)
+		mockTypeaheadRepository.On("AddFollowers", mockUser, 1).Return(nil)
 
 		err := us.ChangeFollow(mockUser, uid)
 
 		assert.NoError(t, err)
-		mockUserRepository.AssertExpectations(t)
-		mockUserRepository.AssertNotCalled(t, "RemoveFollow", mockUser, uid)
+		mockTimelineRepository.AssertNotCalled(t, "FindEntries", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	})
 
 	t.Run("Success change to unfollowed", func(t *testing.T) {
@@ -490,16 +375,32 @@ func TestUserService_ChangeFollow(t *testing.T) {
 		mockUser := fixture.GetMockUser()
 
 		mockUserRepository := new(mocks.UserRepository)
+		mockTimelineRepository := new(mocks.TimelineRepository)
+		mockTypeaheadRepository := new(mocks.TypeaheadRepository)
 		us := NewUserService(&USConfig{
-			UserRepository: mockUserRepository,
+			UserRepository:      mockUserRepository,
+			TimelineRepository:  mockTimelineRepository,
+			TypeaheadRepository: mockTypeaheadRepository,
 		})
+
+		followee, _ := GenerateId()
+
+		mockUserRepository.On("LoadViewerState", current.ID, []*model.User{mockUser}).
+			Run(func(args mock.Arguments) {
+				mockUser.Following = true
+			}).
+			Return(nil)
 		mockUserRepository.On("RemoveFollow", mockUser.ID, current.ID).Return(nil)
+		mockTypeaheadRepository.On("AddFollowers", mockUser, -1).Return(nil)
+		mockTimelineRepository.On("FindFolloweeIDs", current.ID).Return([]string{followee}, []string{}, nil)
+		mockTimelineRepository.On("RemoveAuthor", current.ID, mockUser.ID, []string{followee, current.ID}).Return(nil)
 
-		mockUser.Followers = append(mockUser.Followers, current)
 		err := us.ChangeFollow(mockUser, current.ID)
 
 		assert.NoError(t, err)
 		mockUserRepository.AssertExpectations(t)
+		mockTimelineRepository.AssertExpectations(t)
+		mockTypeaheadRepository.AssertExpectations(t)
 		mockUserRepository.AssertNotCalled(t, "AddFollow", mockUser, current.ID)
 	})
 
@@ -512,6 +413,7 @@ func TestUserService_ChangeFollow(t *testing.T) {
 			UserRepository: mockUserRepository,
 		})
 
+		mockUserRepository.On("LoadViewerState", current.ID, []*model.User{mockUser}).Return(nil)
 		mockUserRepository.On("AddFollow", mockUser.ID, current.ID).Return(fmt.Errorf("some error down the call chain"))
 
 		err := us.ChangeFollow(mockUser, current.ID)
@@ -529,9 +431,13 @@ func TestUserService_ChangeFollow(t *testing.T) {
 			UserRepository: mockUserRepository,
 		})
 
+		mockUserRepository.On("LoadViewerState", current.ID, []*model.User{mockUser}).
+			Run(func(args mock.Arguments) {
+				mockUser.Following = true
+			}).
+			Return(nil)
 		mockUserRepository.On("RemoveFollow", mockUser.ID, current.ID).Return(fmt.Errorf("some error down the call chain"))
 
-		mockUser.Followers = append(mockUser.Followers, current)
 		err := us.ChangeFollow(mockUser, current.ID)
 
 		assert.Error(t, err)
@@ -556,9 +462,12 @@ func TestUserService_Search(t *testing.T) {
 
 		term := "tes"
 
-		mockUserRepository.On("SearchProfiles", term).Return(&users, nil)
+		viewer := fixture.RandID()
+		cursor := &model.ProfileCursor{Rank: 2.5, Followers: 3, ID: fixture.RandID()}
+
+		mockUserRepository.On("SearchProfiles", term, viewer, cursor).Return(&users, nil)
 
-		rsp, err := us.Search(term)
+		rsp, err := us.Search(" "+term+" ", viewer, cursor)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 5, len(*rsp))
@@ -572,214 +481,360 @@ func TestUserService_Search(t *testing.T) {
 		})
 
 		term := "tes"
-		mockUserRepository.On("SearchProfiles", term).Return(nil, fmt.Errorf("some error down the call chain"))
+		mockUserRepository.On("SearchProfiles", term, "", (*model.ProfileCursor)(nil)).Return(nil, fmt.Errorf("some error down the call chain"))
 
-		rsp, err := us.Search(term)
+		rsp, err := us.Search(term, "", nil)
 
 		assert.Nil(t, rsp)
 		assert.Error(t, err)
 		mockUserRepository.AssertExpectations(t)
 	})
-}
 
-func TestUserService_ChangeBanner(t *testing.T) {
-	mockUserRepository := new(mocks.UserRepository)
-	mockFileRepository := new(mocks.FileRepository)
+	t.Run("Empty term", func(t *testing.T) {
+		mockUserRepository := new(mocks.UserRepository)
+		us := NewUserService(&USConfig{
+			UserRepository: mockUserRepository,
+		})
 
-	us := NewUserService(&USConfig{
-		UserRepository: mockUserRepository,
-		FileRepository: mockFileRepository,
+		rsp, err := us.Search(" ", "", nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, *rsp)
+		mockUserRepository.AssertNotCalled(t, "SearchProfiles", mock.Anything, mock.Anything, mock.Anything)
 	})
+}
+
+func TestUserService_ChangeAvatar(t *testing.T) {
+	placeholder := model.Placeholder{
+		BlurHash:      "LEHV6nWB2yk8pyo0adR*.7kCMdnj",
+		DominantColor: "#1da1f2",
+	}
+
+	t.Run("Successful new image", func(t *testing.T) {
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+
+		us := NewUserService(&USConfig{
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
 
-	t.Run("Successful new banner", func(t *testing.T) {
-		// does not have have banner
 		mockUser := fixture.GetMockUser()
 
 		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
 		defer multipartImageFixture.Close()
 		imageFileHeader := multipartImageFixture.GetFormFile()
-		directory := "test_dir"
 
-		uploadFileArgs := mock.Arguments{
-			imageFileHeader,
-			directory,
+		variant := model.FileVariant{
+			Size:   model.OriginalSize,
+			Format: model.JPEG,
+			Key:    "files/profile_images/hash.jpeg",
+			Url:    "https://imageurl.com/profile_images/hash.jpeg",
 		}
 
-		imageURL := "https://imageurl.com/jdfkj34kljl"
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(nil, nil)
 
 		mockFileRepository.
-			On("UploadBanner", uploadFileArgs...).
-			Return(imageURL, nil)
-
-		updateArgs := mock.Arguments{
-			mockUser,
-		}
-
-		mockUpdatedUser := &model.User{
-			ID:          mockUser.ID,
-			Email:       mockUser.Email,
-			Bio:         mockUser.Bio,
-			Username:    mockUser.Username,
-			DisplayName: mockUser.DisplayName,
-			Image:       mockUser.Image,
-			Banner:      &imageURL,
-			Password:    mockUser.Password,
-			CreatedAt:   mockUser.CreatedAt,
-			UpdatedAt:   mockUser.UpdatedAt,
-		}
+			On("UploadAvatar", imageFileHeader, "profile_images", mock.AnythingOfType("string")).
+			Return(variant, placeholder, nil)
 
-		mockUserRepository.
-			On("Update", updateArgs...).
+		mockMediaRepository.
+			On("Create", mock.AnythingOfType("*model.Media")).
 			Return(nil)
 
-		url, err := us.ChangeBanner(imageFileHeader, directory)
+		err := us.ChangeAvatar(mockUser, imageFileHeader)
 		assert.NoError(t, err)
-		mockUser.Banner = &url
 
-		err = us.Update(mockUser)
+		assert.Equal(t, variant.Url, mockUser.Image)
+		assert.Equal(t, placeholder, mockUser.ImagePlaceholder)
+		assert.NotNil(t, mockUser.ImageMediaID)
 
-		assert.NoError(t, err)
-		assert.Equal(t, mockUpdatedUser, mockUser)
-		mockFileRepository.AssertCalled(t, "UploadBanner", uploadFileArgs...)
-		mockUserRepository.AssertCalled(t, "Update", updateArgs...)
+		media := mockMediaRepository.Calls[1].Arguments.Get(0).(*model.Media)
+		assert.Equal(t, *mockUser.ImageMediaID, media.ID)
+		assert.Equal(t, "profile_images/"+media.Hash, media.ID)
+		assert.Equal(t, []string{variant.Key}, []string(media.Keys))
+		mockFileRepository.AssertCalled(t, "UploadAvatar", imageFileHeader, "profile_images", media.Hash)
 	})
 
-	t.Run("Successful update banner", func(t *testing.T) {
-		imageURL := "https://imageurl.com/jdfkj34kljl"
+	t.Run("Reuses stored image", func(t *testing.T) {
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+
+		us := NewUserService(&USConfig{
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
 
 		mockUser := fixture.GetMockUser()
-		mockUser.Banner = &imageURL
 
 		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
 		defer multipartImageFixture.Close()
 		imageFileHeader := multipartImageFixture.GetFormFile()
-		directory := "test_dir"
 
-		uploadFileArgs := mock.Arguments{
-			imageFileHeader,
-			directory,
+		media := &model.Media{
+			ID:          "profile_images/hash",
+			Hash:        "hash",
+			Refs:        2,
+			Url:         "https://imageurl.com/profile_images/hash.jpeg",
+			Keys:        []string{"files/profile_images/hash.jpeg"},
+			Placeholder: placeholder,
 		}
 
-		deleteImageArgs := mock.Arguments{
-			imageURL,
-		}
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(media, nil)
 
-		mockFileRepository.
-			On("UploadBanner", uploadFileArgs...).
-			Return(imageURL, nil)
+		err := us.ChangeAvatar(mockUser, imageFileHeader)
+		assert.NoError(t, err)
 
+		assert.Equal(t, media.Url, mockUser.Image)
+		assert.Equal(t, placeholder, mockUser.ImagePlaceholder)
+		assert.Equal(t, media.ID, *mockUser.ImageMediaID)
+		mockFileRepository.AssertNotCalled(t, "UploadAvatar", mock.Anything, mock.Anything, mock.Anything)
+		mockMediaRepository.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("FileRepository Error", func(t *testing.T) {
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+
+		us := NewUserService(&USConfig{
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
+
+		mockUser := fixture.GetMockUser()
+		image := mockUser.Image
+
+		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
+		defer multipartImageFixture.Close()
+		imageFileHeader := multipartImageFixture.GetFormFile()
+
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(nil, nil)
+
+		mockError := apperrors.NewBadRequest("could not decode image")
 		mockFileRepository.
-			On("DeleteImage", deleteImageArgs...).
-			Return(nil)
+			On("UploadAvatar", imageFileHeader, "profile_images", mock.AnythingOfType("string")).
+			Return(model.FileVariant{}, model.Placeholder{}, mockError)
 
-		mockUpdatedUser := &model.User{
-			ID:          mockUser.ID,
-			Email:       mockUser.Email,
-			Bio:         mockUser.Bio,
-			Username:    mockUser.Username,
-			DisplayName: mockUser.DisplayName,
-			Image:       mockUser.Image,
-			Banner:      &imageURL,
-			Password:    mockUser.Password,
-			CreatedAt:   mockUser.CreatedAt,
-			UpdatedAt:   mockUser.UpdatedAt,
-		}
+		err := us.ChangeAvatar(mockUser, imageFileHeader)
 
-		updateArgs := mock.Arguments{
-			mockUser,
-		}
+		assert.Equal(t, mockError, err)
+		assert.Equal(t, image, mockUser.Image)
+		assert.Nil(t, mockUser.ImageMediaID)
+		mockMediaRepository.AssertNotCalled(t, "Create", mock.Anything)
+	})
 
-		mockUserRepository.
-			On("Update", updateArgs...).
-			Return(nil)
+	t.Run("MediaRepository Error", func(t *testing.T) {
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
 
-		url, err := us.ChangeBanner(imageFileHeader, directory)
-		assert.NoError(t, err)
-		err = us.DeleteImage(*mockUser.Banner)
-		assert.NoError(t, err)
+		us := NewUserService(&USConfig{
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
 
-		mockUser.Banner = &url
-		err = us.Update(mockUser)
-		assert.NoError(t, err)
+		mockUser := fixture.GetMockUser()
+
+		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
+		defer multipartImageFixture.Close()
+		imageFileHeader := multipartImageFixture.GetFormFile()
+
+		mockError := apperrors.NewInternal()
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(nil, mockError)
 
-		assert.Equal(t, mockUpdatedUser, mockUser)
-		mockFileRepository.AssertCalled(t, "UploadBanner", uploadFileArgs...)
-		mockFileRepository.AssertCalled(t, "DeleteImage", imageURL)
-		mockUserRepository.AssertCalled(t, "Update", updateArgs...)
+		err := us.ChangeAvatar(mockUser, imageFileHeader)
+
+		assert.Equal(t, mockError, err)
+		assert.Nil(t, mockUser.ImageMediaID)
+		mockFileRepository.AssertNotCalled(t, "UploadAvatar", mock.Anything, mock.Anything, mock.Anything)
 	})
+}
 
-	t.Run("FileRepository Error", func(t *testing.T) {
-		// need to create a new UserService and repository
-		// because testify has no way to overwrite a mock's
-		// "On" call.
-		mockUserRepository := new(mocks.UserRepository)
+func TestUserService_ChangeBanner(t *testing.T) {
+	t.Run("Successful new banner", func(t *testing.T) {
 		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
 
 		us := NewUserService(&USConfig{
-			UserRepository: mockUserRepository,
-			FileRepository: mockFileRepository,
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
 		})
 
+		mockUser := fixture.GetMockUser()
+
 		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
 		defer multipartImageFixture.Close()
 		imageFileHeader := multipartImageFixture.GetFormFile()
-		directory := "file_directory"
 
-		uploadFileArgs := mock.Arguments{
-			imageFileHeader,
-			directory,
+		variant := model.FileVariant{
+			Size:   model.OriginalSize,
+			Format: model.JPEG,
+			Key:    "files/header_photo/hash.jpeg",
+			Url:    "https://imageurl.com/header_photo/hash.jpeg",
 		}
 
-		mockError := apperrors.NewInternal()
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(nil, nil)
+
 		mockFileRepository.
-			On("UploadBanner", uploadFileArgs...).
-			Return("", mockError)
+			On("UploadBanner", imageFileHeader, "header_photo", mock.AnythingOfType("string")).
+			Return(variant, model.Placeholder{}, nil)
 
-		url, err := us.ChangeBanner(imageFileHeader, directory)
-		assert.Equal(t, "", url)
-		assert.Error(t, err)
+		mockMediaRepository.
+			On("Create", mock.AnythingOfType("*model.Media")).
+			Return(nil)
+
+		err := us.ChangeBanner(mockUser, imageFileHeader)
+		assert.NoError(t, err)
 
-		mockFileRepository.AssertCalled(t, "UploadBanner", uploadFileArgs...)
-		mockUserRepository.AssertNotCalled(t, "Update")
+		assert.Equal(t, variant.Url, *mockUser.Banner)
+		assert.NotNil(t, mockUser.BannerMediaID)
+		mockMediaRepository.AssertCalled(t, "Create", mock.AnythingOfType("*model.Media"))
 	})
 
-	t.Run("UserRepository Update Error", func(t *testing.T) {
-		imageURL := "https://imageurl.com/jdfkj34kljl"
+	t.Run("Reuses stored banner", func(t *testing.T) {
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+
+		us := NewUserService(&USConfig{
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
 
 		mockUser := fixture.GetMockUser()
-		mockUser.Banner = &imageURL
 
 		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
 		defer multipartImageFixture.Close()
 		imageFileHeader := multipartImageFixture.GetFormFile()
-		directory := "file_dir"
 
-		uploadFileArgs := mock.Arguments{
-			imageFileHeader,
-			directory,
+		media := &model.Media{
+			ID:   "header_photo/hash",
+			Hash: "hash",
+			Refs: 2,
+			Url:  "https://imageurl.com/header_photo/hash.jpeg",
+			Keys: []string{"files/header_photo/hash.jpeg"},
 		}
 
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(media, nil)
+
+		err := us.ChangeBanner(mockUser, imageFileHeader)
+		assert.NoError(t, err)
+
+		assert.Equal(t, media.Url, *mockUser.Banner)
+		assert.Equal(t, media.ID, *mockUser.BannerMediaID)
+		mockFileRepository.AssertNotCalled(t, "UploadBanner", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("FileRepository Error", func(t *testing.T) {
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+
+		us := NewUserService(&USConfig{
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
+
+		mockUser := fixture.GetMockUser()
+
+		multipartImageFixture := fixture.NewMultipartImage("image.png", "image/png")
+		defer multipartImageFixture.Close()
+		imageFileHeader := multipartImageFixture.GetFormFile()
+
+		mockMediaRepository.
+			On("Acquire", mock.AnythingOfType("string")).
+			Return(nil, nil)
+
+		mockError := apperrors.NewInternal()
 		mockFileRepository.
-			On("UploadBanner", uploadFileArgs...).
-			Return(imageURL, nil)
+			On("UploadBanner", imageFileHeader, "header_photo", mock.AnythingOfType("string")).
+			Return(model.FileVariant{}, model.Placeholder{}, mockError)
 
-		updateArgs := mock.Arguments{
-			mockUser,
+		err := us.ChangeBanner(mockUser, imageFileHeader)
+
+		assert.Equal(t, mockError, err)
+		assert.Nil(t, mockUser.Banner)
+		mockMediaRepository.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestUserService_ReleaseMedia(t *testing.T) {
+	t.Run("Still referenced", func(t *testing.T) {
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+
+		us := NewUserService(&USConfig{
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
+
+		mockMediaRepository.
+			On("Release", "profile_images/hash", mock.Anything).
+			Return(nil)
+
+		err := us.ReleaseMedia("profile_images/hash")
+
+		assert.NoError(t, err)
+		mockFileRepository.AssertNotCalled(t, "DeleteImage", mock.Anything)
+	})
+
+	t.Run("Last reference deletes the objects", func(t *testing.T) {
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
+
+		us := NewUserService(&USConfig{
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
+
+		media := &model.Media{
+			ID:   "profile_images/hash",
+			Keys: []string{"files/profile_images/hash.jpeg"},
 		}
 
-		mockError := apperrors.NewInternal()
-		mockUserRepository.
-			On("Update", updateArgs...).
-			Return(mockError)
+		mockMediaRepository.
+			On("Release", media.ID, mock.Anything).
+			Run(func(args mock.Arguments) {
+				_ = args.Get(1).(func([]string) error)(media.Keys)
+			}).
+			Return(nil)
+
+		mockFileRepository.
+			On("DeleteImage", "files/profile_images/hash.jpeg").
+			Return(nil)
+
+		err := us.ReleaseMedia(media.ID)
 
-		url, err := us.ChangeBanner(imageFileHeader, directory)
 		assert.NoError(t, err)
-		assert.Equal(t, imageURL, url)
+		mockFileRepository.AssertCalled(t, "DeleteImage", "files/profile_images/hash.jpeg")
+	})
 
-		err = us.Update(mockUser)
+	t.Run("MediaRepository Error", func(t *testing.T) {
+		mockFileRepository := new(mocks.FileRepository)
+		mockMediaRepository := new(mocks.MediaRepository)
 
-		assert.Error(t, err)
-		mockFileRepository.AssertCalled(t, "UploadBanner", uploadFileArgs...)
-		mockUserRepository.AssertCalled(t, "Update", updateArgs...)
+		us := NewUserService(&USConfig{
+			FileRepository:  mockFileRepository,
+			MediaRepository: mockMediaRepository,
+		})
+
+		mockError := apperrors.NewInternal()
+		mockMediaRepository.
+			On("Release", "profile_images/hash", mock.Anything).
+			Return(mockError)
+
+		err := us.ReleaseMedia("profile_images/hash")
+
+		assert.Equal(t, mockError, err)
+		mockFileRepository.AssertNotCalled(t, "DeleteImage", mock.Anything)
 	})
 }